@@ -3,8 +3,6 @@ package main
 import (
 	"bufio"
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -37,14 +35,15 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	s := mwapi.NewSession(c)
 
-	login, err := c.Login(ctx, cfg.Username, cfg.Password)
+	login, err := s.Login(ctx, cfg.Username, cfg.Password)
 	if err != nil {
 		log.Fatal(err)
 	}
 	log.Printf("login ok: %s (id=%d)", login.LgName, login.LgUserID)
 
-	userInfo, err := queryUserInfo(ctx, c)
+	userInfo, err := s.UserInfo(ctx)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -54,18 +53,12 @@ func main() {
 	ts := time.Now().UTC().Format(time.RFC3339)
 	text := buildDemoText(ts, cfg.Endpoint)
 
-	editResp, err := c.PostWithToken(ctx, mwapi.TokenCSRF, map[string]any{
-		"action":  "edit",
-		"title":   title,
-		"text":    text,
-		"summary": fmt.Sprintf("demo update timestamp: %s", ts),
-		"minor":   true,
-	}, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	edit, err := parseEditResult(editResp.Raw)
+	edit, err := s.Edit(ctx, mwapi.EditParams{
+		Title:   title,
+		Text:    text,
+		Summary: fmt.Sprintf("demo update timestamp: %s", ts),
+		Minor:   true,
+	})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -94,59 +87,6 @@ func readConfigFromEnv() (envConfig, error) {
 	return cfg, nil
 }
 
-type userInfo struct {
-	Name      string `json:"name"`
-	ID        int    `json:"id"`
-	EditCount int    `json:"editcount"`
-}
-
-func queryUserInfo(ctx context.Context, c *mwapi.Client) (*userInfo, error) {
-	resp, err := c.Get(ctx, map[string]any{
-		"action": "query",
-		"meta":   "userinfo",
-		"uiprop": []string{"editcount"},
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	var out struct {
-		Query struct {
-			UserInfo userInfo `json:"userinfo"`
-		} `json:"query"`
-	}
-	if err := resp.Into(&out); err != nil {
-		return nil, err
-	}
-	if out.Query.UserInfo.Name == "" {
-		return nil, errors.New("missing userinfo.name in response")
-	}
-	return &out.Query.UserInfo, nil
-}
-
-type editResult struct {
-	Result       string `json:"result"`
-	Title        string `json:"title"`
-	NewRevID     int64  `json:"newrevid"`
-	NewTimestamp string `json:"newtimestamp"`
-}
-
-func parseEditResult(raw json.RawMessage) (*editResult, error) {
-	var out struct {
-		Edit editResult `json:"edit"`
-	}
-	if err := json.Unmarshal(raw, &out); err != nil {
-		return nil, err
-	}
-	if strings.ToLower(out.Edit.Result) != "success" {
-		if out.Edit.Result == "" {
-			return nil, errors.New("missing edit.result in response")
-		}
-		return nil, fmt.Errorf("edit failed: %s", out.Edit.Result)
-	}
-	return &out.Edit, nil
-}
-
 func buildDemoText(ts string, endpoint string) string {
 	return strings.TrimSpace(fmt.Sprintf(`
 == wiki-saikou-go demo ==
@@ -1,6 +1,6 @@
 module github.com/moegirlwiki/wiki-saikou-go
 
-go 1.22
+go 1.23
 
 require (
 	github.com/google/go-querystring v1.1.0
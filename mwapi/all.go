@@ -0,0 +1,137 @@
+package mwapi
+
+import (
+	"context"
+	"iter"
+)
+
+type AllPagesOptions struct {
+	// Namespace to enumerate; list=allpages takes a single namespace (default 0).
+	Namespace int
+	Prefix    string
+	From      string
+	To        string
+	Filter    RedirectFilter
+	// ProtectionTypes (edit, move, ...) limits to protected pages; ProtectionLevel
+	// narrows further to e.g. sysop.
+	ProtectionTypes []string
+	ProtectionLevel string
+}
+
+// AllPages iterates every page in a namespace (list=allpages).
+func (c *Client) AllPages(ctx context.Context, opts AllPagesOptions, qopts ...QueryOption) iter.Seq2[PageRef, error] {
+	p := map[string]any{
+		"action":      "query",
+		"list":        "allpages",
+		"apnamespace": opts.Namespace,
+		"aplimit":     "max",
+	}
+	if opts.Prefix != "" {
+		p["apprefix"] = opts.Prefix
+	}
+	if opts.From != "" {
+		p["apfrom"] = opts.From
+	}
+	if opts.To != "" {
+		p["apto"] = opts.To
+	}
+	if opts.Filter != "" {
+		p["apfilterredir"] = string(opts.Filter)
+	}
+	if len(opts.ProtectionTypes) > 0 {
+		p["apprtype"] = opts.ProtectionTypes
+		if opts.ProtectionLevel != "" {
+			p["apprlevel"] = opts.ProtectionLevel
+		}
+	}
+	return queryItems(ctx, c, p, listItems[PageRef]("allpages"), iterOptions(qopts, "aplimit"))
+}
+
+type ImageEntry struct {
+	Name           string `json:"name"`
+	NS             int    `json:"ns"`
+	Title          string `json:"title"`
+	Timestamp      string `json:"timestamp,omitempty"`
+	User           string `json:"user,omitempty"`
+	Size           int64  `json:"size,omitempty"`
+	Width          int    `json:"width,omitempty"`
+	Height         int    `json:"height,omitempty"`
+	URL            string `json:"url,omitempty"`
+	DescriptionURL string `json:"descriptionurl,omitempty"`
+	MIME           string `json:"mime,omitempty"`
+	SHA1           string `json:"sha1,omitempty"`
+}
+
+type AllImagesOptions struct {
+	Prefix string
+	From   string
+	To     string
+	MIME   []string
+	// SHA1 finds files with this hex digest (duplicates of a known file).
+	SHA1 string
+}
+
+// AllImages iterates every file on the wiki (list=allimages).
+func (c *Client) AllImages(ctx context.Context, opts AllImagesOptions, qopts ...QueryOption) iter.Seq2[ImageEntry, error] {
+	p := map[string]any{
+		"action":  "query",
+		"list":    "allimages",
+		"aiprop":  []string{"timestamp", "user", "size", "url", "mime", "sha1"},
+		"aimime":  opts.MIME,
+		"ailimit": "max",
+	}
+	if opts.Prefix != "" {
+		p["aiprefix"] = opts.Prefix
+	}
+	if opts.From != "" {
+		p["aifrom"] = opts.From
+	}
+	if opts.To != "" {
+		p["aito"] = opts.To
+	}
+	if opts.SHA1 != "" {
+		p["aisha1"] = opts.SHA1
+	}
+	return queryItems(ctx, c, p, listItems[ImageEntry]("allimages"), iterOptions(qopts, "ailimit"))
+}
+
+type UserEntry struct {
+	UserID       int64    `json:"userid"`
+	Name         string   `json:"name"`
+	EditCount    int64    `json:"editcount"`
+	Registration string   `json:"registration,omitempty"`
+	Groups       []string `json:"groups,omitempty"`
+	Rights       []string `json:"rights,omitempty"`
+}
+
+type AllUsersOptions struct {
+	Prefix        string
+	From          string
+	Groups        []string
+	ExcludeGroups []string
+	Rights        []string
+	WithEditsOnly bool
+	ActiveOnly    bool
+}
+
+// AllUsers iterates registered users (list=allusers).
+func (c *Client) AllUsers(ctx context.Context, opts AllUsersOptions, qopts ...QueryOption) iter.Seq2[UserEntry, error] {
+	p := map[string]any{
+		"action":          "query",
+		"list":            "allusers",
+		"auprop":          []string{"editcount", "registration", "groups", "rights"},
+		"augroup":         opts.Groups,
+		"auexcludegroup":  opts.ExcludeGroups,
+		"aurights":        opts.Rights,
+		"auwitheditsonly": opts.WithEditsOnly,
+		"auactiveusers":   opts.ActiveOnly,
+		"aulimit":         "max",
+	}
+	if opts.Prefix != "" {
+		p["auprefix"] = opts.Prefix
+	}
+	if opts.From != "" {
+		p["aufrom"] = opts.From
+	}
+	return queryItems(ctx, c, p, listItems[UserEntry]("allusers"), iterOptions(qopts, "aulimit"))
+}
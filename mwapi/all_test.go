@@ -0,0 +1,153 @@
+package mwapi
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+func TestAllPages_ContinuationAndFilters(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("query", func(r *mwtest.Request) any {
+		switch r.Param("apcontinue") {
+		case "":
+			return map[string]any{
+				"continue": map[string]any{"apcontinue": "Foo/C", "continue": "-||"},
+				"query": map[string]any{"allpages": []any{
+					map[string]any{"pageid": 10, "ns": 10, "title": "Template:Foo/A"},
+					map[string]any{"pageid": 11, "ns": 10, "title": "Template:Foo/B"},
+				}},
+			}
+		case "Foo/C":
+			return map[string]any{
+				"batchcomplete": true,
+				"query": map[string]any{"allpages": []any{
+					map[string]any{"pageid": 12, "ns": 10, "title": "Template:Foo/C"},
+				}},
+			}
+		}
+		return mwtest.ErrorResponse("badcontinue", "Invalid continue param.")
+	})
+
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	var titles []string
+	for pg, err := range c.AllPages(ctx, AllPagesOptions{Namespace: 10, Prefix: "Foo/", Filter: RedirectsExcept}) {
+		if err != nil {
+			t.Fatalf("AllPages: %v", err)
+		}
+		titles = append(titles, pg.Title)
+	}
+	if len(titles) != 3 || titles[2] != "Template:Foo/C" {
+		t.Fatalf("titles = %v", titles)
+	}
+
+	reqs := wiki.RequestsFor("query")
+	if len(reqs) != 2 {
+		t.Fatalf("requests = %d, want 2", len(reqs))
+	}
+	for _, r := range reqs {
+		if r.Param("list") != "allpages" || r.Param("apnamespace") != "10" || r.Param("apprefix") != "Foo/" ||
+			r.Param("apfilterredir") != "nonredirects" || r.Param("aplimit") != "max" {
+			t.Fatalf("params = %v", r.Form)
+		}
+	}
+	if reqs[0].Param("apcontinue") != "" || reqs[1].Param("continue") != "-||" {
+		t.Fatalf("continuation params = %v / %v", reqs[0].Form, reqs[1].Form)
+	}
+}
+
+func TestAllImagesAndAllUsers_Decode(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("query", func(r *mwtest.Request) any {
+		switch r.Param("list") {
+		case "allimages":
+			return map[string]any{"query": map[string]any{"allimages": []any{map[string]any{
+				"name": "Logo.png", "ns": 6, "title": "File:Logo.png", "size": 2048, "mime": "image/png", "sha1": "abc123",
+			}}}}
+		case "allusers":
+			return map[string]any{"query": map[string]any{"allusers": []any{map[string]any{
+				"userid": 7, "name": "Admin", "editcount": 42, "groups": []any{"*", "user", "sysop"},
+			}}}}
+		}
+		return mwtest.ErrorResponse("badvalue", "unexpected list")
+	})
+
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	for img, err := range c.AllImages(ctx, AllImagesOptions{MIME: []string{"image/png"}, SHA1: "abc123"}) {
+		if err != nil || img.Name != "Logo.png" || img.Size != 2048 || img.SHA1 != "abc123" {
+			t.Fatalf("AllImages = %+v, %v", img, err)
+		}
+	}
+	wiki.AssertSent("query", "aimime", "image/png")
+	wiki.AssertSent("query", "aisha1", "abc123")
+
+	for u, err := range c.AllUsers(ctx, AllUsersOptions{Groups: []string{"sysop"}, WithEditsOnly: true}) {
+		if err != nil || u.UserID != 7 || u.EditCount != 42 || len(u.Groups) != 3 {
+			t.Fatalf("AllUsers = %+v, %v", u, err)
+		}
+	}
+	wiki.AssertSent("query", "augroup", "sysop")
+	wiki.AssertSent("query", "auwitheditsonly", "1")
+	wiki.AssertNotSent("query", "auactiveusers")
+}
+
+func TestAllPages_WithLimit(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("query", func(r *mwtest.Request) any {
+		// Two pages per batch at most; the client may ask for fewer.
+		n := min(2, mustAtoi(t, r.Param("aplimit")))
+		start := 0
+		if c := r.Param("apcontinue"); c != "" {
+			start = mustAtoi(t, c)
+		}
+		var pages []any
+		for i := start; i < start+n; i++ {
+			pages = append(pages, map[string]any{"pageid": i, "ns": 0, "title": fmt.Sprintf("P%d", i)})
+		}
+		return map[string]any{
+			"continue": map[string]any{"apcontinue": strconv.Itoa(start + n), "continue": "-||"},
+			"query":    map[string]any{"allpages": pages},
+		}
+	})
+
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	var titles []string
+	for pg, err := range c.AllPages(ctx, AllPagesOptions{}, WithLimit(5)) {
+		if err != nil {
+			t.Fatalf("AllPages: %v", err)
+		}
+		titles = append(titles, pg.Title)
+	}
+	if len(titles) != 5 || titles[4] != "P4" {
+		t.Fatalf("titles = %v, want P0..P4", titles)
+	}
+
+	reqs := wiki.RequestsFor("query")
+	var limits []string
+	for _, r := range reqs {
+		limits = append(limits, r.Param("aplimit"))
+	}
+	if strings.Join(limits, ",") != "5,3,1" {
+		t.Fatalf("aplimit per request = %v, want 5,3,1", limits)
+	}
+}
@@ -0,0 +1,51 @@
+package mwapi
+
+import (
+	"context"
+	"fmt"
+)
+
+// AssertDiagnostic is the server's view of the session at the moment an
+// assertuser check failed, next to what the client expected.
+type AssertDiagnostic struct {
+	Expected   string // user the client asserted
+	ServerUser string // user the server sees ("" if the probe failed)
+	ServerAnon bool
+	Cookies    int   // cookies the client holds for the endpoint
+	ProbeErr   error // why the userinfo probe failed, if it did
+}
+
+func (d *AssertDiagnostic) String() string {
+	switch {
+	case d.ProbeErr != nil:
+		return fmt.Sprintf("expected %q, userinfo probe failed: %v; %d cookies", d.Expected, d.ProbeErr, d.Cookies)
+	case d.ServerAnon:
+		return fmt.Sprintf("expected %q, server sees an anonymous session; %d cookies", d.Expected, d.Cookies)
+	default:
+		return fmt.Sprintf("expected %q, server sees %q; %d cookies", d.Expected, d.ServerUser, d.Cookies)
+	}
+}
+
+// WithAssertDiagnostics makes the client probe meta=userinfo whenever an
+// assertuser check fails and attach the result to the error, to tell expired
+// or missing cookies apart from a session of the wrong user.
+func WithAssertDiagnostics(v bool) Option {
+	return func(c *Client) {
+		c.assertDiagnostics = v
+	}
+}
+
+func (c *Client) diagnoseAssert(ctx context.Context, e *MediaWikiApiError) {
+	if !c.assertDiagnostics || e.AssertDiagnostic != nil {
+		return
+	}
+	c.mu.Lock()
+	d := &AssertDiagnostic{Expected: c.loggedInUser}
+	c.mu.Unlock()
+	if c.hc.Jar != nil {
+		d.Cookies = len(c.hc.Jar.Cookies(c.endpoint))
+	}
+	e.AssertDiagnostic = d
+
+	d.ServerUser, d.ServerAnon, d.ProbeErr = c.whoAmI(ctx)
+}
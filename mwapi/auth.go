@@ -3,10 +3,18 @@ package mwapi
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 )
 
+// ErrOAuthLogin is returned by the login methods of a client authenticating
+// with WithOAuth2Token: the token already identifies the user, and a cookie
+// login on top would mix two identities.
+var ErrOAuthLogin = errors.New("login not available with an OAuth token")
+
 type LoginResult struct {
 	Result   string `json:"result"`
 	LgUserID int    `json:"lguserid"`
@@ -15,6 +23,79 @@ type LoginResult struct {
 }
 
 func (c *Client) Login(ctx context.Context, user, pass string) (*LoginResult, error) {
+	res, err := c.login(ctx, user, pass)
+	if err != nil {
+		return res, err
+	}
+	c.setRelogin(func(ctx context.Context) error {
+		_, err := c.Login(ctx, user, pass)
+		return err
+	})
+	return res, nil
+}
+
+// LoginWithBotPassword logs in with a bot password created at Special:BotPasswords.
+// The session belongs to user; botName is the suffix after "@" in the login name.
+func (c *Client) LoginWithBotPassword(ctx context.Context, user, botName, botPass string) (*LoginResult, error) {
+	res, err := c.login(ctx, user+"@"+botName, botPass)
+	if err != nil {
+		return res, err
+	}
+	c.setRelogin(func(ctx context.Context) error {
+		_, err := c.LoginWithBotPassword(ctx, user, botName, botPass)
+		return err
+	})
+	return res, nil
+}
+
+// SwitchUser logs the client in as another user, replacing the current
+// session. Tokens cached for the previous user are dropped and, being keyed by
+// user, can never be sent on behalf of the new one.
+func (c *Client) SwitchUser(ctx context.Context, user, pass string) (*LoginResult, error) {
+	c.InvalidateAllTokens()
+	return c.Login(ctx, user, pass)
+}
+
+// CredentialProvider returns the login name and password to use, e.g. read
+// from a secrets manager. It is consulted on every login, so rotated secrets
+// are picked up by the next relogin.
+type CredentialProvider func(ctx context.Context) (user, pass string, err error)
+
+// WithCredentialProvider sets the provider used by LoginWithProvider.
+func WithCredentialProvider(p CredentialProvider) Option {
+	return func(c *Client) {
+		c.credentials = p
+	}
+}
+
+// LoginWithProvider logs in with credentials from the WithCredentialProvider
+// provider. Relogin asks the provider again instead of reusing the password.
+func (c *Client) LoginWithProvider(ctx context.Context) (*LoginResult, error) {
+	if c.credentials == nil {
+		return nil, errors.New("login: no credential provider configured")
+	}
+	user, pass, err := c.credentials(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("credential provider: %w", err)
+	}
+	res, err := c.login(ctx, user, pass)
+	if err != nil {
+		return res, err
+	}
+	c.setRelogin(func(ctx context.Context) error {
+		_, err := c.LoginWithProvider(ctx)
+		return err
+	})
+	return res, nil
+}
+
+func (c *Client) login(ctx context.Context, user, pass string) (_ *LoginResult, err error) {
+	ctx, done := c.startCall(ctx)
+	defer func() { err = done(err) }()
+	if c.oauthToken != "" {
+		return nil, ErrOAuthLogin
+	}
+	ctx = c.ensureRequestID(ctx)
 	retry := c.tokenRetry
 	var lastErr error
 
@@ -50,13 +131,15 @@ func (c *Client) Login(ctx context.Context, user, pass string) (*LoginResult, er
 		switch strings.ToLower(out.Login.Result) {
 		case "success":
 			c.mu.Lock()
-			c.loginUser = user
-			c.loginPass = pass
 			c.loggedInUser = out.Login.LgName
+			c.rights = nil
 			c.mu.Unlock()
 
 			// Session changed; invalidate all tokens.
 			c.InvalidateAllTokens()
+			if err := c.runAfterLogin(ctx, &out.Login); err != nil {
+				return &out.Login, err
+			}
 			return &out.Login, nil
 		case "needtoken", "wrongtoken":
 			lastErr = fmt.Errorf("login token error: %s", out.Login.Result)
@@ -75,32 +158,128 @@ func (c *Client) Login(ctx context.Context, user, pass string) (*LoginResult, er
 	return nil, fmt.Errorf("login retry exhausted: %w", lastErr)
 }
 
+// WithAfterLogin runs fn after every successful login, including the ones
+// Relogin performs, e.g. to set preferences or warm tokens for the new
+// session. An error from fn fails the login. Requests made by fn never
+// trigger a relogin themselves.
+func WithAfterLogin(fn func(ctx context.Context, c *Client, res *LoginResult) error) Option {
+	return func(c *Client) {
+		c.afterLogin = fn
+	}
+}
+
+type afterLoginKey struct{}
+
+func (c *Client) runAfterLogin(ctx context.Context, res *LoginResult) error {
+	if c.afterLogin == nil {
+		return nil
+	}
+	ctx = context.WithValue(ctx, afterLoginKey{}, true)
+	if err := c.afterLogin(ctx, c, res); err != nil {
+		return fmt.Errorf("after-login hook: %w", err)
+	}
+	return nil
+}
+
+// Relogin re-establishes the session with the method used by the last successful login.
+// With an OAuth bearer token there is no session to restore, so it is a no-op.
 func (c *Client) Relogin(ctx context.Context) error {
+	if ctx.Err() != nil {
+		return canceled(ctx, nil)
+	}
 	c.mu.Lock()
-	user := c.loginUser
-	pass := c.loginPass
+	fn := c.relogin
+	oauth := c.oauthToken != ""
 	c.mu.Unlock()
 
-	if user == "" || pass == "" {
-		return fmt.Errorf("relogin requested but no stored credentials")
+	if oauth {
+		return nil
+	}
+	if fn == nil {
+		return fmt.Errorf("relogin requested but no stored login method")
 	}
-	_, err := c.Login(ctx, user, pass)
-	return err
+	if err := c.reloginGuard.allow(time.Now()); err != nil {
+		return err
+	}
+	c.relogins.Add(1)
+	return fn(ctx)
 }
 
-func (c *Client) Logout(ctx context.Context) error {
-	_, err := c.PostWithToken(ctx, TokenCSRF, map[string]any{
-		"action": "logout",
-	}, nil)
-	if err != nil {
-		return err
+// WithMaxReloginsPerMinute makes Relogin fail fast with ErrReloginStorm after
+// n relogins within a minute. Repeated relogins usually mean the session
+// cannot stick (cookie domain, clock skew), so more logins only load the wiki.
+// Zero, the default, sets no limit; negative values are ignored. To stop
+// relogins altogether, use WithReloginRetry(0).
+func WithMaxReloginsPerMinute(n int) Option {
+	return func(c *Client) {
+		if n >= 0 {
+			c.reloginGuard.max = n
+		}
 	}
+}
 
+// reloginWindow counts relogins in fixed one-minute windows.
+type reloginWindow struct {
+	mu    sync.Mutex
+	max   int // 0 means unlimited
+	start time.Time
+	count int
+}
+
+func (w *reloginWindow) allow(now time.Time) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.max == 0 {
+		return nil
+	}
+	if now.Sub(w.start) >= time.Minute {
+		w.start, w.count = now, 0
+	}
+	if w.count >= w.max {
+		return fmt.Errorf("%w: %d in the last minute", ErrReloginStorm, w.count)
+	}
+	w.count++
+	return nil
+}
+
+func (c *Client) setRelogin(fn func(ctx context.Context) error) {
 	c.mu.Lock()
-	c.loggedInUser = ""
-	c.loginUser = ""
-	c.loginPass = ""
+	c.relogin = fn
 	c.mu.Unlock()
-	c.InvalidateAllTokens()
-	return nil
+}
+
+type LogoutResult struct {
+	// Confirmed is true when the server acknowledged the logout.
+	// When false, the server-side session may still be alive.
+	Confirmed bool
+	Response  *Response
+}
+
+// Logout ends the session. Local state (logged-in user, relogin method, tokens)
+// is always cleared, even when the API call fails; the returned error then
+// reports why the server could not confirm the logout.
+func (c *Client) Logout(ctx context.Context) (*LogoutResult, error) {
+	defer func() {
+		c.mu.Lock()
+		c.loggedInUser = ""
+		c.oauthIdentified = false
+		c.rights = nil
+		c.relogin = nil
+		c.mu.Unlock()
+		c.InvalidateAllTokens()
+	}()
+
+	res := &LogoutResult{}
+	resp, err := c.PostWithToken(ctx, TokenCSRF, map[string]any{
+		"action": "logout",
+	}, nil)
+	res.Response = resp
+	if err != nil {
+		return res, err
+	}
+	if err := apiError(resp); err != nil {
+		return res, err
+	}
+	res.Confirmed = true
+	return res, nil
 }
@@ -0,0 +1,442 @@
+package mwapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+func TestRelogin_ReusesBotPasswordLogin(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.AddUser("UserA@mybot", "botsecret")
+	wiki.Handle("parse", func(r *mwtest.Request) any {
+		return map[string]any{"parse": map[string]any{}}
+	})
+
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	res, err := c.LoginWithBotPassword(ctx, "UserA", "mybot", "botsecret")
+	if err != nil {
+		t.Fatalf("LoginWithBotPassword: %v", err)
+	}
+	if res.LgName != "UserA" {
+		t.Fatalf("lgusername = %q, want UserA", res.LgName)
+	}
+
+	wiki.ExpireSessions()
+	if _, err := c.Post(ctx, map[string]any{"action": "parse", "text": "x"}); err != nil {
+		t.Fatalf("Post(parse): %v", err)
+	}
+
+	logins := wiki.RequestsFor("login")
+	if len(logins) != 2 {
+		t.Fatalf("login requests = %d, want 2", len(logins))
+	}
+	if got := logins[1].Param("lgname"); got != "UserA@mybot" {
+		t.Fatalf("relogin lgname = %q, want UserA@mybot", got)
+	}
+}
+
+func TestRelogin_NoopWithOAuth(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	c := New(wiki.URL(), WithOAuth2Token("ACCESS"))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	if err := c.Relogin(ctx); err != nil {
+		t.Fatalf("Relogin: %v", err)
+	}
+	if got := len(wiki.RequestsFor("login")); got != 0 {
+		t.Fatalf("login requests = %d, want 0", got)
+	}
+
+	if _, err := c.Get(ctx, map[string]any{"meta": "userinfo"}); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got := wiki.LastRequest("query").Header.Get("Authorization"); got != "Bearer ACCESS" {
+		t.Fatalf("Authorization = %q", got)
+	}
+}
+
+func TestLogin_RefusedWithOAuth(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.AddUser("UserA", "secret")
+	c := New(wiki.URL(), WithOAuth2Token("ACCESS"))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	if _, err := c.Login(ctx, "UserA", "secret"); !errors.Is(err, ErrOAuthLogin) {
+		t.Fatalf("Login err = %v, want ErrOAuthLogin", err)
+	}
+	if _, err := c.ClientLogin(ctx, ClientLoginParams{Username: "UserA", Password: "secret"}); !errors.Is(err, ErrOAuthLogin) {
+		t.Fatalf("ClientLogin err = %v, want ErrOAuthLogin", err)
+	}
+	if n := len(wiki.Requests()); n != 0 {
+		t.Fatalf("requests = %d, want 0", n)
+	}
+}
+
+func TestOAuth_IdentifiesUserForAsserts(t *testing.T) {
+	t.Parallel()
+
+	var probes atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.Header.Get("Authorization") != "Bearer ACCESS" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		if r.Form.Get("meta") == "userinfo" {
+			probes.Add(1)
+			if r.Form.Has("assertuser") {
+				t.Errorf("userinfo probe sent assertuser=%q", r.Form.Get("assertuser"))
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"query": map[string]any{"userinfo": map[string]any{"id": 3, "name": "OAuthBot"}},
+			})
+			return
+		}
+		if got := r.Form.Get("assertuser"); got != "OAuthBot" {
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"error": map[string]any{"code": "assertnameduserfailed", "info": "assertuser=" + got},
+			})
+			return
+		}
+		switch {
+		case r.Form.Get("meta") == "tokens":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"query": map[string]any{"tokens": map[string]any{"csrftoken": "CSRF+\\"}},
+			})
+		case r.Form.Get("action") == "edit":
+			_ = json.NewEncoder(w).Encode(map[string]any{"edit": map[string]any{"result": "Success"}})
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	c := New(srv.URL+"/api.php", WithOAuth2Token("ACCESS"), WithThrowOnApiError(true))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	tok, err := c.GetToken(ctx, TokenCSRF)
+	if err != nil {
+		t.Fatalf("GetToken: %v", err)
+	}
+	if tok != "CSRF+\\" {
+		t.Fatalf("token = %q", tok)
+	}
+	if _, err := c.PostWithToken(ctx, TokenCSRF, map[string]any{"action": "edit", "title": "Sandbox", "text": "x"}, nil); err != nil {
+		t.Fatalf("PostWithToken: %v", err)
+	}
+	if n := probes.Load(); n != 1 {
+		t.Fatalf("userinfo probes = %d, want 1", n)
+	}
+	if name, err := c.WhoAmI(ctx); err != nil || name != "OAuthBot" {
+		t.Fatalf("WhoAmI = %q, %v", name, err)
+	}
+}
+
+func TestRelogin_WithoutLoginFails(t *testing.T) {
+	t.Parallel()
+
+	c := New("https://example.org/w/api.php")
+	if err := c.Relogin(context.Background()); err == nil {
+		t.Fatalf("Relogin without prior login should fail")
+	}
+}
+
+func TestLogout_ClearsLocalStateOnFailure(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	if _, err := c.Login(ctx, "UserA", "pass"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	res, err := c.Logout(ctx)
+	if err != nil || !res.Confirmed {
+		t.Fatalf("Logout = %+v, %v", res, err)
+	}
+
+	if _, err := c.Login(ctx, "UserA", "pass"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	wiki.Handle("logout", func(r *mwtest.Request) any {
+		return mwtest.Reply{Status: 500, Body: mwtest.ErrorResponse("internal_api_error_DBQueryError", "database error")}
+	})
+	res, err = c.Logout(ctx)
+	if err == nil || res == nil || res.Confirmed {
+		t.Fatalf("Logout = %+v, %v; want unconfirmed with error", res, err)
+	}
+
+	c.mu.Lock()
+	user, relogin := c.loggedInUser, c.relogin
+	c.mu.Unlock()
+	c.tokens.mu.Lock()
+	tokens := len(c.tokens.tokens)
+	c.tokens.mu.Unlock()
+	if user != "" || relogin != nil || tokens != 0 {
+		t.Fatalf("local state not cleared: user=%q relogin=%v tokens=%d", user, relogin != nil, tokens)
+	}
+}
+
+func TestLoginAuto_PasswordOnlyUsesPlainLogin(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.AddUser("UserA", "pass")
+	wiki.Handle("query", func(r *mwtest.Request) any {
+		return map[string]any{"query": map[string]any{"authmanagerinfo": map[string]any{
+			"canauthenticatenow": true,
+			"requests": []any{
+				map[string]any{
+					"id": "MediaWiki\\Auth\\PasswordAuthenticationRequest", "required": "primary-required",
+					"fields": map[string]any{
+						"username": map[string]any{"type": "string", "label": "Username"},
+						"password": map[string]any{"type": "password", "label": "Password"},
+					},
+				},
+				map[string]any{
+					"id": "MediaWiki\\Auth\\RememberMeAuthenticationRequest", "required": "optional",
+					"fields": map[string]any{"rememberMe": map[string]any{"type": "checkbox", "optional": true}},
+				},
+			},
+		}}}
+	})
+
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	for i := 0; i < 2; i++ {
+		c.mu.Lock()
+		c.loggedInUser = ""
+		c.mu.Unlock()
+		res, err := c.LoginAuto(ctx, "UserA", "pass")
+		if err != nil {
+			t.Fatalf("LoginAuto: %v", err)
+		}
+		if res.LgName != "UserA" {
+			t.Fatalf("lgusername = %q", res.LgName)
+		}
+	}
+	if got := len(wiki.RequestsFor("login")); got != 2 {
+		t.Fatalf("action=login requests = %d, want 2", got)
+	}
+	if got := len(wiki.RequestsFor("clientlogin")); got != 0 {
+		t.Fatalf("clientlogin requests = %d, want 0", got)
+	}
+	var infos int
+	for _, r := range wiki.RequestsFor("query") {
+		if r.Param("meta") == "authmanagerinfo" {
+			infos++
+			if r.Param("amirequestsfor") != "login" {
+				t.Fatalf("amirequestsfor = %q", r.Param("amirequestsfor"))
+			}
+		}
+	}
+	if infos != 1 {
+		t.Fatalf("authmanagerinfo requests = %d, want 1 (cached)", infos)
+	}
+}
+
+func TestLoginAuto_ExtraFieldsAreInteractive(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("query", func(r *mwtest.Request) any {
+		return map[string]any{"query": map[string]any{"authmanagerinfo": map[string]any{
+			"requests": []any{
+				map[string]any{
+					"id": "MediaWiki\\Auth\\PasswordAuthenticationRequest", "required": "primary-required",
+					"fields": map[string]any{"username": map[string]any{}, "password": map[string]any{}},
+				},
+				map[string]any{
+					"id": "CaptchaAuthenticationRequest", "required": "required",
+					"fields": map[string]any{"captchaWord": map[string]any{"type": "string"}},
+				},
+			},
+		}}}
+	})
+
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	_, err := c.LoginAuto(ctx, "UserA", "pass")
+	if !errors.Is(err, ErrInteractiveLogin) || !strings.Contains(err.Error(), "captchaWord") {
+		t.Fatalf("err = %v, want ErrInteractiveLogin naming captchaWord", err)
+	}
+	if got := len(wiki.RequestsFor("login")); got != 0 {
+		t.Fatalf("action=login requests = %d, want 0", got)
+	}
+}
+
+func TestAfterLogin_RunsOnLoginAndRelogin(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.AddUser("UserA", "pass")
+	wiki.Handle("parse", func(r *mwtest.Request) any {
+		return map[string]any{"parse": map[string]any{}}
+	})
+
+	var calls []string
+	c := New(wiki.URL(), WithAfterLogin(func(ctx context.Context, c *Client, res *LoginResult) error {
+		calls = append(calls, res.LgName)
+		// Hook requests see the fresh session.
+		_, err := c.Get(ctx, map[string]any{"meta": "userinfo"})
+		return err
+	}))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	if _, err := c.Login(ctx, "UserA", "pass"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	wiki.ExpireSessions()
+	if _, err := c.Post(ctx, map[string]any{"action": "parse", "text": "x"}); err != nil {
+		t.Fatalf("Post after expiry: %v", err)
+	}
+	if len(calls) != 2 || calls[0] != "UserA" || calls[1] != "UserA" {
+		t.Fatalf("hook calls = %v, want login and relogin", calls)
+	}
+
+	failing := New(wiki.URL(), WithAfterLogin(func(ctx context.Context, c *Client, res *LoginResult) error {
+		return errors.New("cannot set preference")
+	}))
+	if _, err := failing.Login(ctx, "UserA", "pass"); err == nil || !strings.Contains(err.Error(), "cannot set preference") {
+		t.Fatalf("Login err = %v, want hook error", err)
+	}
+}
+
+func TestAssertDiagnostics_ReportsServerView(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.AddUser("UserA", "pass")
+	wiki.Handle("parse", func(r *mwtest.Request) any {
+		return map[string]any{"parse": map[string]any{}}
+	})
+
+	c := New(wiki.URL(), WithReloginRetry(0), WithAssertDiagnostics(true))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	if _, err := c.Login(ctx, "UserA", "pass"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	wiki.ExpireSessions()
+
+	_, err := c.Post(ctx, map[string]any{"action": "parse", "text": "x"})
+	e, ok := IsMediaWikiApiError(err)
+	if !ok || e.AssertDiagnostic == nil {
+		t.Fatalf("err = %v, want assert failure with diagnostic", err)
+	}
+	d := e.AssertDiagnostic
+	if d.Expected != "UserA" || !d.ServerAnon || d.ProbeErr != nil {
+		t.Fatalf("diagnostic = %+v, want expected UserA and anonymous server session", d)
+	}
+	if d.Cookies == 0 {
+		t.Fatalf("diagnostic cookies = 0, want session cookies counted")
+	}
+	if !strings.Contains(err.Error(), "anonymous") {
+		t.Fatalf("err = %q, want diagnostic in message", err)
+	}
+}
+
+func TestRelogin_StormGuard(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("parse", func(r *mwtest.Request) any {
+		return map[string]any{"parse": map[string]any{}}
+	})
+
+	c := New(wiki.URL(), WithMaxReloginsPerMinute(2))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	if _, err := c.Login(ctx, "UserA", "pass"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		wiki.ExpireSessions()
+		if _, err := c.Post(ctx, map[string]any{"action": "parse", "text": "x"}); err != nil {
+			t.Fatalf("Post #%d: %v", i, err)
+		}
+	}
+	wiki.ExpireSessions()
+	_, err := c.Post(ctx, map[string]any{"action": "parse", "text": "x"})
+	if !errors.Is(err, ErrReloginStorm) {
+		t.Fatalf("err = %v, want ErrReloginStorm", err)
+	}
+	if got := c.Stats().Relogins; got != 2 {
+		t.Fatalf("Stats().Relogins = %d, want 2", got)
+	}
+	if got := len(wiki.RequestsFor("login")); got != 3 {
+		t.Fatalf("login requests = %d, want 3", got)
+	}
+}
+
+func TestLoginWithProvider_ReloginFetchesRotatedPassword(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.AddUser("UserA@bot", "first-secret")
+	wiki.Handle("parse", func(r *mwtest.Request) any {
+		return map[string]any{"parse": map[string]any{}}
+	})
+
+	calls := 0
+	secrets := []string{"first-secret", "rotated-secret"}
+	c := New(wiki.URL(), WithCredentialProvider(func(ctx context.Context) (string, string, error) {
+		pass := secrets[min(calls, len(secrets)-1)]
+		calls++
+		return "UserA@bot", pass, nil
+	}))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	if _, err := c.LoginWithProvider(ctx); err != nil {
+		t.Fatalf("LoginWithProvider: %v", err)
+	}
+
+	// The secret is rotated on the wiki; the old session dies with it.
+	wiki.AddUser("UserA@bot", "rotated-secret")
+	wiki.ExpireSessions()
+	if _, err := c.Post(ctx, map[string]any{"action": "parse", "text": "x"}); err != nil {
+		t.Fatalf("Post after rotation: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("provider calls = %d, want 2", calls)
+	}
+	logins := wiki.RequestsFor("login")
+	if got := logins[len(logins)-1].Param("lgpassword"); got != "rotated-secret" {
+		t.Fatalf("relogin password = %q, want the rotated one", got)
+	}
+
+	// A failing login never echoes the password.
+	wiki.AddUser("UserA@bot", "something-else")
+	_, err := c.LoginWithProvider(ctx)
+	if err == nil || strings.Contains(err.Error(), "rotated-secret") {
+		t.Fatalf("err = %v, want a failure without the password", err)
+	}
+}
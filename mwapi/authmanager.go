@@ -0,0 +1,126 @@
+package mwapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrInteractiveLogin is returned by LoginAuto when the wiki needs more than a
+// username and password (CAPTCHA, two-factor code, ...); use ClientLogin.
+var ErrInteractiveLogin = errors.New("login needs fields beyond username and password")
+
+type AuthField struct {
+	Type     string `json:"type"`
+	Label    string `json:"label"`
+	Help     string `json:"help"`
+	Optional bool   `json:"optional"`
+}
+
+type AuthRequest struct {
+	ID string `json:"id"`
+	// Required is "required", "primary-required" or "optional".
+	Required string               `json:"required"`
+	Provider string               `json:"provider"`
+	Account  string               `json:"account"`
+	Fields   map[string]AuthField `json:"fields"`
+}
+
+type AuthInfo struct {
+	CanAuthenticateNow bool          `json:"canauthenticatenow"`
+	CanCreateAccounts  bool          `json:"cancreateaccounts"`
+	PreservedUsername  string        `json:"preservedusername"`
+	Requests           []AuthRequest `json:"requests"`
+}
+
+// AuthManagerInfo returns what the wiki's AuthManager expects for requestsFor
+// ("login", "create", "link", ...), via meta=authmanagerinfo. The answer
+// depends only on the wiki's configuration and is cached per client.
+func (c *Client) AuthManagerInfo(ctx context.Context, requestsFor string) (*AuthInfo, error) {
+	c.mu.Lock()
+	cached := c.authInfo[requestsFor]
+	c.mu.Unlock()
+	if cached != nil {
+		return cached, nil
+	}
+
+	resp, err := c.Get(ctx, map[string]any{
+		"action":           "query",
+		"meta":             "authmanagerinfo",
+		"amirequestsfor":   requestsFor,
+		"amimessageformat": "plaintext",
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := apiError(resp); err != nil {
+		return nil, err
+	}
+
+	var out struct {
+		Query struct {
+			AuthManagerInfo *AuthInfo `json:"authmanagerinfo"`
+		} `json:"query"`
+	}
+	if err := resp.Into(&out); err != nil {
+		return nil, err
+	}
+	info := out.Query.AuthManagerInfo
+	if info == nil {
+		return nil, errors.New("missing query.authmanagerinfo in response")
+	}
+
+	c.mu.Lock()
+	if c.authInfo == nil {
+		c.authInfo = map[string]*AuthInfo{}
+	}
+	c.authInfo[requestsFor] = info
+	c.mu.Unlock()
+	return info, nil
+}
+
+// passwordOnly reports whether a plain username/password login satisfies
+// info, and otherwise which extra fields are required.
+func (info *AuthInfo) passwordOnly() (ok bool, extra []string) {
+	hasPassword := false
+	seen := map[string]bool{}
+	for _, r := range info.Requests {
+		if strings.HasSuffix(r.ID, "PasswordAuthenticationRequest") {
+			hasPassword = true
+		}
+		if r.Required != "required" {
+			continue
+		}
+		for name, f := range r.Fields {
+			if f.Optional || name == "username" || name == "password" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+	return hasPassword && len(extra) == 0, extra
+}
+
+// LoginAuto logs in with the flow the wiki asks for. Bot passwords
+// ("User@BotName") and password-only wikis use action=login; wikis requiring
+// extra fields fail with ErrInteractiveLogin listing them.
+func (c *Client) LoginAuto(ctx context.Context, user, pass string) (*LoginResult, error) {
+	if strings.Contains(user, "@") {
+		return c.Login(ctx, user, pass)
+	}
+	info, err := c.AuthManagerInfo(ctx, "login")
+	if err != nil {
+		return nil, fmt.Errorf("login: %w", err)
+	}
+	if ok, extra := info.passwordOnly(); !ok {
+		if len(extra) == 0 {
+			return nil, fmt.Errorf("%w: no password authentication offered", ErrInteractiveLogin)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrInteractiveLogin, strings.Join(extra, ", "))
+	}
+	return c.Login(ctx, user, pass)
+}
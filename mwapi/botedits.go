@@ -0,0 +1,74 @@
+package mwapi
+
+import (
+	"context"
+	"net/url"
+	"slices"
+)
+
+// botFlagParams maps write actions that can be hidden from recent changes to
+// the parameter that does it.
+var botFlagParams = map[string]string{
+	"edit":     "bot",
+	"rollback": "markbot",
+}
+
+// WithBotEdits marks edits (and rollbacks) as bot edits unless the request
+// sets the flag itself. It is a no-op while the logged-in account lacks the
+// bot right, which is checked once per login via meta=userinfo.
+func WithBotEdits(v bool) Option {
+	return func(c *Client) {
+		c.botEdits = v
+	}
+}
+
+func (c *Client) applyBotFlag(ctx context.Context, action string, v url.Values) {
+	param, ok := botFlagParams[action]
+	if !c.botEdits || !ok {
+		return
+	}
+	if _, set := v[param]; set {
+		return
+	}
+	if c.hasRight(ctx, "bot") {
+		v.Set(param, "1")
+	}
+}
+
+// hasRight reports whether the current account has right. The rights are
+// fetched once per login via meta=userinfo.
+func (c *Client) hasRight(ctx context.Context, right string) bool {
+	c.mu.Lock()
+	known, user := c.rights, c.loggedInUser
+	c.mu.Unlock()
+	if known != nil {
+		return slices.Contains(known, right)
+	}
+
+	resp, err := c.Get(ctx, map[string]any{
+		"action": "query",
+		"meta":   "userinfo",
+		"uiprop": "rights",
+	})
+	if err != nil || apiError(resp) != nil {
+		// Do not cache: a transient failure should not disable the flag for the session.
+		return false
+	}
+	var out struct {
+		Query struct {
+			UserInfo UserInfo `json:"userinfo"`
+		} `json:"query"`
+	}
+	if err := resp.Into(&out); err != nil {
+		return false
+	}
+	rights := append([]string{}, out.Query.UserInfo.Rights...)
+
+	c.mu.Lock()
+	// A login in the meantime makes the answer stale.
+	if c.loggedInUser == user {
+		c.rights = rights
+	}
+	c.mu.Unlock()
+	return slices.Contains(rights, right)
+}
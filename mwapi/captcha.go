@@ -0,0 +1,73 @@
+package mwapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrCaptchaRequired = errors.New("captcha required")
+
+// Captcha describes a ConfirmEdit challenge. Image CAPTCHAs have URL (relative
+// to the wiki), question CAPTCHAs have Question.
+type Captcha struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Mime     string `json:"mime,omitempty"`
+	Question string `json:"question,omitempty"`
+	URL      string `json:"url,omitempty"`
+}
+
+func (c *Captcha) UnmarshalJSON(b []byte) error {
+	var v struct {
+		ID       json.Number `json:"id"`
+		Type     string      `json:"type"`
+		Mime     string      `json:"mime"`
+		Question string      `json:"question"`
+		URL      string      `json:"url"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*c = Captcha{ID: v.ID.String(), Type: v.Type, Mime: v.Mime, Question: v.Question, URL: v.URL}
+	return nil
+}
+
+// CaptchaError is returned by Session.Edit when the wiki wants a CAPTCHA
+// solved; pass its ID and the answer to Session.ResolveCaptcha.
+type CaptchaError struct {
+	Captcha
+	// Result is the failed edit result, when the CAPTCHA came in edit.captcha.
+	Result *EditResult
+}
+
+func (e *CaptchaError) Error() string {
+	detail := e.Type
+	if e.Question != "" {
+		detail += ": " + e.Question
+	}
+	return fmt.Sprintf("%s (id %s, %s)", ErrCaptchaRequired, e.ID, detail)
+}
+
+func (e *CaptchaError) Is(target error) bool { return target == ErrCaptchaRequired }
+
+// captchaFromError extracts a CAPTCHA from a code=captcha API error, whose
+// details live in the error's data.
+func captchaFromError(err error) *CaptchaError {
+	e, ok := IsMediaWikiApiError(err)
+	if !ok || !strings.EqualFold(e.Code, "captcha") {
+		return nil
+	}
+	ce := &CaptchaError{}
+	for _, me := range e.Errors {
+		var data struct {
+			Captcha *Captcha `json:"captcha"`
+		}
+		if len(me.Data) > 0 && json.Unmarshal(me.Data, &data) == nil && data.Captcha != nil {
+			ce.Captcha = *data.Captcha
+			break
+		}
+	}
+	return ce
+}
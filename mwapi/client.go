@@ -1,25 +1,28 @@
 package mwapi
 
 import (
-	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"mime/multipart"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
-
-	"golang.org/x/sync/singleflight"
 )
 
 type Option func(*Client)
 
+const defaultMaxResponseBytes = 32 << 20 // 32MiB
+
 func WithUserAgent(ua string) Option {
 	return func(c *Client) {
 		if ua != "" {
@@ -28,6 +31,9 @@ func WithUserAgent(ua string) Option {
 	}
 }
 
+// WithHTTPClient makes the client send requests through a copy of hc, so
+// options such as WithTimeout, WithTransport and the cookie jar never modify
+// a client that may be shared with other code.
 func WithHTTPClient(hc *http.Client) Option {
 	return func(c *Client) {
 		if hc != nil {
@@ -36,24 +42,22 @@ func WithHTTPClient(hc *http.Client) Option {
 	}
 }
 
+// WithTransport sets the RoundTripper, regardless of where WithHTTPClient
+// appears among the options.
 func WithTransport(rt http.RoundTripper) Option {
 	return func(c *Client) {
-		if c.hc == nil {
-			return
-		}
 		if rt != nil {
-			c.hc.Transport = rt
+			c.transport = rt
 		}
 	}
 }
 
+// WithTimeout sets the HTTP client timeout, regardless of where
+// WithHTTPClient appears among the options.
 func WithTimeout(d time.Duration) Option {
 	return func(c *Client) {
-		if c.hc == nil {
-			return
-		}
 		if d > 0 {
-			c.hc.Timeout = d
+			c.timeout = d
 		}
 	}
 }
@@ -70,6 +74,15 @@ func WithKeepLogin(v bool) Option {
 	}
 }
 
+// WithAssertBot sends assert=bot on every request except logins, so writes
+// fail with ErrAssertBotFailed instead of going out unflagged once the
+// account loses its bot right. Such failures are never retried by relogin.
+func WithAssertBot(v bool) Option {
+	return func(c *Client) {
+		c.assertBot = v
+	}
+}
+
 func WithReloginRetry(n int) Option {
 	return func(c *Client) {
 		if n >= 0 {
@@ -86,23 +99,175 @@ func WithTokenRetry(n int) Option {
 	}
 }
 
+// WithOAuth2Token authenticates every request with an OAuth 2.0 bearer token
+// instead of a cookie session. Login and ClientLogin then fail with
+// ErrOAuthLogin and Relogin is a no-op. With keep-login, the token's user is
+// looked up once (see WhoAmI) and asserted like a cookie login's.
+func WithOAuth2Token(token string) Option {
+	return func(c *Client) {
+		c.oauthToken = token
+	}
+}
+
+// WithCookieJarFactory sets how fresh cookie jars are created, both for the
+// client itself (unless its *http.Client already has one) and for every Clone.
+func WithCookieJarFactory(fn func() (http.CookieJar, error)) Option {
+	return func(c *Client) {
+		c.jarFactory = fn
+	}
+}
+
+// WithStrictBooleans omits well-known boolean params (minor, bot, redirects, ...)
+// whose value is a falsey string like "false" or "0". Without it, such values
+// are sent verbatim and MediaWiki reads them as true.
+func WithStrictBooleans(v bool) Option {
+	return func(c *Client) {
+		c.strictBooleans = v
+	}
+}
+
+// WithEndpointResolver picks the api.php URL per request, e.g. to send
+// action=login to a central auth wiki in a farm. Returning nil keeps the
+// client's endpoint. params must not be modified.
+func WithEndpointResolver(fn func(ctx context.Context, action string, params url.Values) (*url.URL, error)) Option {
+	return func(c *Client) {
+		c.endpointResolver = fn
+	}
+}
+
+func (c *Client) resolveEndpoint(ctx context.Context, params url.Values) (*url.URL, error) {
+	if c.endpointResolver == nil {
+		return c.endpoint, nil
+	}
+	u, err := c.endpointResolver(ctx, params.Get("action"), params)
+	if err != nil {
+		return nil, fmt.Errorf("resolve endpoint: %w", err)
+	}
+	if u == nil {
+		return c.endpoint, nil
+	}
+	return u, nil
+}
+
+// WithAutoPostThreshold makes Get send a request as POST once its encoded
+// query exceeds n bytes (default 7000), since servers reject URLs over about
+// 8000 bytes. n <= 0 always keeps GET.
+func WithAutoPostThreshold(n int) Option {
+	return func(c *Client) {
+		c.autoPostThreshold = max(n, 0)
+	}
+}
+
+// WithMaxResponseBytes caps the (decompressed) size of a response body,
+// 32MiB by default. A larger body fails with ErrResponseTooLarge rather than
+// being parsed truncated.
+func WithMaxResponseBytes(n int64) Option {
+	return func(c *Client) {
+		if n > 0 {
+			c.maxResponseBytes = n
+		}
+	}
+}
+
+// WithCompression controls whether responses are requested gzip-compressed
+// (the default). Compressed bodies are decoded before WithMaxResponseBytes
+// applies, so the limit is on the decompressed size.
+func WithCompression(v bool) Option {
+	return func(c *Client) {
+		c.noCompression = !v
+	}
+}
+
 type Client struct {
+	clientConfig
+
+	hc  *http.Client
+	jar http.CookieJar
+
+	rights   []string // nil until checked for the current login
+	siteInfo *SiteInfo
+
+	life          lifecycle
+	writePacer    writePacer
+	tagCache      tagCache
+	paramInfo     paramInfoCache
+	endpointCheck endpointCheck
+	stats         *statsCounters
+
+	mu          sync.Mutex
+	tokens      *TokenCache
+	loginTokens *TokenCache
+
+	loggedInUser string
+	relogin      func(ctx context.Context) error
+	relogins     atomic.Int64
+	reloginGuard reloginWindow
+	authInfo     map[string]*AuthInfo
+	// oauthIdentified is set once the OAuth user has been looked up.
+	oauthIdentified bool
+}
+
+// clientConfig holds what options set and clones share; Client keeps the
+// per-session state and anything guarded by a lock next to it.
+type clientConfig struct {
 	endpoint *url.URL
-	hc       *http.Client
 	ua       string
 
-	throwOnApiError bool
-	keepLogin       bool
-	reloginRetry    int
-	tokenRetry      int
+	timeout   time.Duration
+	transport http.RoundTripper
 
-	mu     sync.Mutex
-	tokens map[TokenType]string
-	_sf    *singleflight.Group
+	throwOnApiError  bool
+	keepLogin        bool
+	assertBot        bool
+	reloginRetry     int
+	tokenRetry       int
+	maxLag           int
+	maxLagRetry      int
+	maxLagWait       time.Duration
+	serverRetries    int
+	serverRetryDelay time.Duration
+	rawContinue      bool
 
-	loggedInUser string
-	loginUser    string
-	loginPass    string
+	maxResponseBytes  int64
+	autoPostThreshold int
+	noCompression     bool
+	jarFactory        func() (http.CookieJar, error)
+
+	writeActions          map[string]struct{}
+	requireLoginForWrites bool
+	readOnly              bool
+	forcePostActions      map[string]struct{}
+
+	requestIDHeader string
+	strictBooleans  bool
+	strictWrites    bool
+
+	verifyEndpoint bool
+
+	overallDeadline time.Duration
+
+	paramRewriters []func(action string, v url.Values)
+	actionDefaults map[string]map[string]any
+
+	assertDiagnostics bool
+	botEdits          bool
+
+	siteInfoProps []string
+
+	endpointResolver func(ctx context.Context, action string, params url.Values) (*url.URL, error)
+
+	scheduler Scheduler
+	observer  func(RequestInfo, ResponseInfo, error)
+
+	statsHook func(ctx context.Context, s ClientStats) error
+
+	tlsConfig          *tls.Config
+	insecureSkipVerify bool
+
+	tokenStore  TokenStore
+	afterLogin  func(ctx context.Context, c *Client, res *LoginResult) error
+	credentials CredentialProvider
+	oauthToken  string
 }
 
 func New(endpoint string, opts ...Option) *Client {
@@ -125,22 +290,30 @@ func NewClient(endpoint string, opts ...Option) (*Client, error) {
 		return nil, fmt.Errorf("invalid endpoint path (expect .../api.php): %q", u.Path)
 	}
 
-	jar, _ := cookiejar.New(nil)
 	hc := &http.Client{
-		Jar:     jar,
 		Timeout: 30 * time.Second,
 	}
 
 	c := &Client{
-		endpoint:        u,
-		hc:              hc,
-		ua:              "mwapi-go/0.1",
-		throwOnApiError: false,
-		keepLogin:       true,
-		reloginRetry:    3,
-		tokenRetry:      3,
-		tokens:          map[TokenType]string{},
-	}
+		clientConfig: clientConfig{
+			endpoint:          u,
+			ua:                "mwapi-go/0.1",
+			throwOnApiError:   false,
+			keepLogin:         true,
+			reloginRetry:      3,
+			tokenRetry:        3,
+			maxLagRetry:       3,
+			maxLagWait:        30 * time.Second,
+			serverRetryDelay:  time.Second,
+			maxResponseBytes:  defaultMaxResponseBytes,
+			autoPostThreshold: 7000,
+			writeActions:      newWriteActionSet(),
+		},
+		hc: hc,
+	}
+
+	c.loginTokens = NewTokenCache()
+	c.tokens = c.loginTokens
 
 	for _, opt := range opts {
 		if opt != nil {
@@ -150,47 +323,192 @@ func NewClient(endpoint string, opts ...Option) (*Client, error) {
 
 	if c.hc == nil {
 		c.hc = hc
+	} else if c.hc != hc {
+		// Never mutate the caller's client: it may be shared.
+		cp := *c.hc
+		c.hc = &cp
+	}
+	if c.transport != nil {
+		c.hc.Transport = c.transport
+	}
+	if c.timeout > 0 {
+		c.hc.Timeout = c.timeout
+	}
+	if err := c.applyTLSOptions(); err != nil {
+		return nil, err
+	}
+	if c.jar != nil {
+		c.hc.Jar = c.jar
 	}
 	if c.hc.Jar == nil {
-		jar2, _ := cookiejar.New(nil)
-		c.hc.Jar = jar2
+		jar, err := c.newJar()
+		if err != nil {
+			return nil, err
+		}
+		c.hc.Jar = jar
 	}
 
 	return c, nil
 }
 
-func (c *Client) Get(ctx context.Context, p any) (*Response, error) {
-	return c.do(ctx, http.MethodGet, p, doOptions{})
+func (c *Client) newJar() (http.CookieJar, error) {
+	if c.jarFactory != nil {
+		return c.jarFactory()
+	}
+	return cookiejar.New(nil)
+}
+
+func (c *Client) Get(ctx context.Context, p any, opts ...RequestOption) (*Response, error) {
+	return c.do(ctx, http.MethodGet, p, newDoOptions(opts))
 }
 
-func (c *Client) Post(ctx context.Context, p any) (*Response, error) {
-	return c.do(ctx, http.MethodPost, p, doOptions{})
+func (c *Client) Post(ctx context.Context, p any, opts ...RequestOption) (*Response, error) {
+	return c.do(ctx, http.MethodPost, p, newDoOptions(opts))
+}
+
+// RequestOption adjusts a single Get/Post/PostWithToken call.
+type RequestOption func(*doOptions)
+
+// ThrowOnApiError overrides the client's WithThrowOnApiError setting for one call.
+func ThrowOnApiError(v bool) RequestOption {
+	return func(o *doOptions) {
+		o.throwOnApiError = &v
+	}
+}
+
+// SkipAssert sends one call without the keep-login assertuser, e.g. a query
+// that may run anonymously.
+func SkipAssert() RequestOption {
+	return func(o *doOptions) {
+		o.skipAssert = true
+	}
+}
+
+// RequestTimeout bounds one call, retries included, e.g. an expensive parse.
+// It only shortens ctx; the HTTP client timeout still applies per attempt.
+func RequestTimeout(d time.Duration) RequestOption {
+	return func(o *doOptions) {
+		o.timeout = d
+	}
+}
+
+// RequestHeader adds a header to the requests of one call, overriding the
+// client's own headers of that name.
+func RequestHeader(key, value string) RequestOption {
+	return func(o *doOptions) {
+		if o.header == nil {
+			o.header = http.Header{}
+		}
+		o.header.Add(key, value)
+	}
 }
 
 type doOptions struct {
 	skipAssert  bool
 	skipRelogin bool
+
+	throwOnApiError *bool
+	timeout         time.Duration
+	header          http.Header
+}
+
+type requestHeaderKey struct{}
+
+func newDoOptions(opts []RequestOption) doOptions {
+	var o doOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+func (c *Client) shouldThrow(opt doOptions) bool {
+	if opt.throwOnApiError != nil {
+		return *opt.throwOnApiError
+	}
+	return c.throwOnApiError
 }
 
-func (c *Client) do(ctx context.Context, method string, p any, opt doOptions) (*Response, error) {
+func (c *Client) do(ctx context.Context, method string, p any, opt doOptions) (_ *Response, err error) {
+	if opt.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opt.timeout)
+		defer cancel()
+	}
+	if opt.header != nil {
+		ctx = context.WithValue(ctx, requestHeaderKey{}, opt.header)
+	}
+	ctx, done := c.startCall(ctx)
+	defer func() { err = done(err) }()
+	ctx = c.ensureRequestID(ctx)
+	ctx = c.withAttempts(ctx)
+	if err := c.checkEndpoint(ctx); err != nil {
+		return nil, err
+	}
+
 	np, err := normalizeParams(p)
 	if err != nil {
 		return nil, err
 	}
+	defer func() { err = asTooManyValues(err, np.Values) }()
+	if err := c.applyActionDefaults(&np); err != nil {
+		return nil, err
+	}
+	if c.maxLag > 0 && !np.Values.Has("maxlag") {
+		np.Values.Set("maxlag", strconv.Itoa(c.maxLag))
+	}
+	if c.strictBooleans {
+		dropFalseyBooleans(np.Values)
+	}
 
 	action := strings.ToLower(np.Values.Get("action"))
 	meta := strings.ToLower(np.Values.Get("meta"))
 	typ := strings.ToLower(np.Values.Get("type"))
 
+	if method == http.MethodGet && (c.mustPost(action) || len(np.Streams) > 0) {
+		method = http.MethodPost
+	}
+	// Long URLs (hundreds of titles) are rejected; the same params work as a POST body.
+	if method == http.MethodGet && c.autoPostThreshold > 0 && len(np.Values.Encode()) > c.autoPostThreshold {
+		method = http.MethodPost
+	}
+
+	if err := c.checkWrite(method, action); err != nil {
+		return nil, err
+	}
+	if method == http.MethodPost {
+		c.applyBotFlag(ctx, action, np.Values)
+	}
+	for _, rw := range c.paramRewriters {
+		rw(action, np.Values)
+	}
+	if method == http.MethodPost && c.isWriteAction(action) {
+		if err := c.checkTags(ctx, np.Values.Get("tags")); err != nil {
+			return nil, err
+		}
+		if err := c.writePacer.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
 	// Keep-login: inject assertuser=username, but never for login or login-token.
 	shouldSkipAssert := opt.skipAssert
-	if action == "login" {
+	if action == "login" || action == "clientlogin" {
 		shouldSkipAssert = true
 	}
 	if action == "query" && meta == "tokens" && strings.Contains(typ, "login") {
 		shouldSkipAssert = true
 	}
+	// assert=anon and assertuser=<name> contradict each other; the caller's explicit anon wins.
+	if strings.EqualFold(np.Values.Get("assert"), "anon") {
+		shouldSkipAssert = true
+	}
 	if c.keepLogin && !shouldSkipAssert {
+		if err := c.identifyOAuthUser(ctx); err != nil {
+			return nil, err
+		}
 		c.mu.Lock()
 		user := c.loggedInUser
 		c.mu.Unlock()
@@ -198,32 +516,57 @@ func (c *Client) do(ctx context.Context, method string, p any, opt doOptions) (*
 			np.Values.Set("assertuser", user)
 		}
 	}
+	if c.assertBot && !shouldSkipAssert && np.Values.Get("assert") == "" {
+		np.Values.Set("assert", "bot")
+	}
 
 	var lastErr error
 	maxRelogin := 0
-	if !opt.skipRelogin {
+	// Requests from the after-login hook must not relogin: that would run the hook again.
+	if !opt.skipRelogin && ctx.Value(afterLoginKey{}) == nil {
 		maxRelogin = c.reloginRetry
 	}
 
 	for attempt := 0; attempt <= maxRelogin; attempt++ {
-		resp, err := c.doOnce(ctx, method, np)
+		resp, err := c.doOnceMaxLag(ctx, method, np, c.shouldThrow(opt))
 		if err == nil {
 			if code := responseErrorCode(resp); isAssertUserFailedCode(code) && attempt < maxRelogin {
-				lastErr = &MediaWikiApiError{
+				e := &MediaWikiApiError{
 					Code:       code,
 					Message:    "assertuser failed",
+					Action:     resp.action,
+					RequestURL: resp.requestURL,
 					HTTPStatus: resp.StatusCode,
 					Response:   resp,
 				}
+				c.diagnoseAssert(ctx, e)
+				lastErr = e
+				if ctx.Err() != nil {
+					return resp, canceled(ctx, e)
+				}
 				if err2 := c.Relogin(ctx); err2 != nil {
 					return resp, errors.Join(lastErr, err2)
 				}
 				continue
 			}
 			if code := responseErrorCode(resp); isAssertUserFailedCode(code) && attempt == maxRelogin {
-				return resp, &MediaWikiApiError{
+				e := &MediaWikiApiError{
 					Code:       code,
 					Message:    "assertuser failed",
+					Action:     resp.action,
+					RequestURL: resp.requestURL,
+					HTTPStatus: resp.StatusCode,
+					Response:   resp,
+				}
+				c.diagnoseAssert(ctx, e)
+				return resp, e
+			}
+			if code := responseErrorCode(resp); isAssertBotFailedCode(code) {
+				return resp, &MediaWikiApiError{
+					Code:       code,
+					Message:    "assert=bot failed",
+					Action:     resp.action,
+					RequestURL: resp.requestURL,
 					HTTPStatus: resp.StatusCode,
 					Response:   resp,
 				}
@@ -236,9 +579,13 @@ func (c *Client) do(ctx context.Context, method string, p any, opt doOptions) (*
 		if !ok || e.Code == "" || !isAssertUserFailedCode(e.Code) {
 			return resp, err
 		}
+		c.diagnoseAssert(ctx, e)
 		if attempt == maxRelogin {
 			return resp, err
 		}
+		if ctx.Err() != nil {
+			return resp, canceled(ctx, err)
+		}
 		if err2 := c.Relogin(ctx); err2 != nil {
 			return resp, errors.Join(err, err2)
 		}
@@ -248,20 +595,70 @@ func (c *Client) do(ctx context.Context, method string, p any, opt doOptions) (*
 	return nil, lastErr
 }
 
-func (c *Client) doOnce(ctx context.Context, method string, np normalizedParams) (*Response, error) {
+func (c *Client) doOnce(ctx context.Context, method string, np normalizedParams, throw bool) (*Response, error) {
+	if ctx.Err() != nil {
+		return nil, canceled(ctx, nil)
+	}
+	action := strings.ToLower(np.Values.Get("action"))
+	if c.scheduler != nil {
+		isWrite := method == http.MethodPost && c.isWriteAction(action)
+		if err := c.scheduler.Before(ctx, action, isWrite); err != nil {
+			return nil, err
+		}
+	}
+	start := time.Now()
+	resp, err := c.send(ctx, method, np, throw)
+	if c.scheduler != nil {
+		c.scheduler.After(resp, err)
+	}
+	if c.observer != nil {
+		c.observe(ctx, method, action, start, resp, err)
+	}
+	if err != nil && ctx.Err() != nil {
+		err = canceled(ctx, err)
+	}
+	return resp, err
+}
+
+// send performs a single HTTP round-trip and decodes the envelope.
+func (c *Client) send(ctx context.Context, method string, np normalizedParams, throw bool) (*Response, error) {
+	if err := c.life.enter(); err != nil {
+		return nil, err
+	}
+	defer c.life.exit()
+
+	if err := c.beforeRequest(ctx); err != nil {
+		return nil, err
+	}
 	req, err := c.buildRequest(ctx, method, np)
 	if err != nil {
 		return nil, err
 	}
+	c.countRequest(int64(len(req.URL.RawQuery)) + max(req.ContentLength, 0))
+	reqURL := redactedURL(req.URL)
 
 	res, err := c.hc.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, &TransportError{RequestURL: reqURL, Err: err}
 	}
 	defer res.Body.Close()
 
-	const maxBody = 32 << 20 // 32MiB
-	body, err := io.ReadAll(io.LimitReader(res.Body, maxBody))
+	// Servers may ignore Accept-Encoding and send plain JSON; proxies and
+	// custom transports may compress even when asked not to.
+	var rd io.Reader = res.Body
+	if !res.Uncompressed && strings.EqualFold(strings.TrimSpace(res.Header.Get("Content-Encoding")), "gzip") {
+		gz, err := gzip.NewReader(res.Body)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		rd = gz
+	}
+
+	// Read one byte past the limit to tell "exactly at limit" from "truncated".
+	limit := c.maxResponseBytes
+	body, err := io.ReadAll(io.LimitReader(rd, limit+1))
+	c.countResponse(int64(len(body)))
 	if err != nil {
 		return nil, err
 	}
@@ -269,13 +666,33 @@ func (c *Client) doOnce(ctx context.Context, method string, np normalizedParams)
 	resp := &Response{
 		StatusCode: res.StatusCode,
 		Header:     res.Header.Clone(),
-		Raw:        json.RawMessage(body),
+		action:     np.Values.Get("action"),
+		requestURL: reqURL,
+
+		DatabaseLag: parseDatabaseLag(res.Header.Get("X-Database-Lag")),
+	}
+	if int64(len(body)) > limit {
+		resp.Raw = json.RawMessage(body[:limit])
+		return resp, &ResponseTooLargeError{Limit: limit, Response: resp}
+	}
+	resp.Raw = json.RawMessage(body)
+
+	// The caller asked for another format (xml, jsonfm, ...): hand back the
+	// raw body, but never parse it as an envelope.
+	if f := np.Values.Get("format"); f != "json" {
+		return resp, fmt.Errorf("%w: format=%s", ErrNonJSONFormat, f)
+	}
+
+	// A fronting proxy's HTML error page is not an API response; don't let it
+	// pass as an empty envelope. Some servers mislabel JSON, so check the body too.
+	if ct := res.Header.Get("Content-Type"); !isJSONContentType(ct) && !json.Valid(body) {
+		return resp, newHTTPError(resp, ct)
 	}
 
 	// Best-effort parse the minimal envelope fields.
 	_ = json.Unmarshal(body, &resp.Envelope)
 
-	if c.throwOnApiError {
+	if throw {
 		if apiErr := responseApiError(resp); apiErr != nil {
 			return resp, apiErr
 		}
@@ -284,8 +701,21 @@ func (c *Client) doOnce(ctx context.Context, method string, np normalizedParams)
 	return resp, nil
 }
 
+// parseDatabaseLag parses X-Database-Lag, which is in (possibly fractional) seconds.
+func parseDatabaseLag(v string) time.Duration {
+	secs, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil || secs <= 0 {
+		return 0
+	}
+	return time.Duration(secs * float64(time.Second))
+}
+
 func (c *Client) buildRequest(ctx context.Context, method string, np normalizedParams) (*http.Request, error) {
-	base := *c.endpoint
+	endpoint, err := c.resolveEndpoint(ctx, np.Values)
+	if err != nil {
+		return nil, err
+	}
+	base := *endpoint
 	baseQuery := base.Query()
 
 	if method == http.MethodGet {
@@ -295,7 +725,7 @@ func (c *Client) buildRequest(ctx context.Context, method string, np normalizedP
 		if err != nil {
 			return nil, err
 		}
-		req.Header.Set("User-Agent", c.ua)
+		c.setHeaders(req)
 		return req, nil
 	}
 
@@ -307,49 +737,67 @@ func (c *Client) buildRequest(ctx context.Context, method string, np normalizedP
 	}
 	base.RawQuery = baseQuery.Encode()
 
-	var body io.Reader
-	contentType := "application/x-www-form-urlencoded"
-
-	if len(np.Files) == 0 {
-		body = strings.NewReader(np.Values.Encode())
-	} else {
-		var buf bytes.Buffer
-		w := multipart.NewWriter(&buf)
-		for k, vs := range np.Values {
-			if len(vs) == 0 {
-				continue
-			}
-			_ = w.WriteField(k, vs[0])
+	if len(np.Files) > 0 {
+		mb, err := newMultipartBody(np)
+		if err != nil {
+			return nil, err
 		}
-		for _, f := range np.Files {
-			filename := f.File.Filename
-			if filename == "" {
-				filename = f.Field
-			}
-			fw, err := w.CreateFormFile(f.Field, filename)
-			if err != nil {
-				_ = w.Close()
-				return nil, err
-			}
-			if _, err := io.Copy(fw, f.File.Reader); err != nil {
-				_ = w.Close()
-				return nil, err
-			}
+		size := mb.size()
+		body := mb.pipe()
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, base.String(), body)
+		if err != nil {
+			_ = body.Close()
+			return nil, err
 		}
-		_ = w.Close()
-		body = &buf
-		contentType = w.FormDataContentType()
+		// Zero with a body means unknown length: net/http sends it chunked.
+		req.ContentLength = max(size, 0)
+		req.Header.Set("Content-Type", mb.contentType())
+		c.setHeaders(req)
+		return req, nil
+	}
+
+	var body io.Reader
+	if len(np.Streams) > 0 {
+		body, err = streamingFormBody(np.Values, np.Streams)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		body = strings.NewReader(np.Values.Encode())
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, base.String(), body)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Content-Type", contentType)
-	req.Header.Set("User-Agent", c.ua)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c.setHeaders(req)
 	return req, nil
 }
 
+func (c *Client) setHeaders(req *http.Request) {
+	req.Header.Set("User-Agent", c.ua)
+	// Set explicitly, so net/http leaves the body alone and doOnce decodes it.
+	if c.noCompression {
+		req.Header.Set("Accept-Encoding", "identity")
+	} else {
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
+	if c.requestIDHeader != "" {
+		if id, ok := RequestIDFromContext(req.Context()); ok {
+			req.Header.Set(c.requestIDHeader, id)
+		}
+	}
+	if c.oauthToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.oauthToken)
+	}
+	if h, ok := req.Context().Value(requestHeaderKey{}).(http.Header); ok {
+		for k, vs := range h {
+			req.Header[k] = slices.Clone(vs)
+		}
+	}
+}
+
 func mergeQuery(base url.Values, overlay url.Values, omitKeys map[string]struct{}) url.Values {
 	out := url.Values{}
 	for k, vs := range base {
@@ -384,7 +832,7 @@ func responseApiError(r *Response) *MediaWikiApiError {
 	var errs []MWError
 	if r.Error != nil {
 		code = r.Error.Code
-		msg = firstNonEmpty(r.Error.Info, r.Error.Text)
+		msg = firstNonEmpty(r.Error.Info, r.Error.Text, r.Error.Key)
 		errs = append(errs, *r.Error)
 	}
 	if len(r.Errors) > 0 {
@@ -392,7 +840,7 @@ func responseApiError(r *Response) *MediaWikiApiError {
 			code = r.Errors[0].Code
 		}
 		if msg == "" {
-			msg = firstNonEmpty(r.Errors[0].Info, r.Errors[0].Text)
+			msg = firstNonEmpty(r.Errors[0].Info, r.Errors[0].Text, r.Errors[0].Key)
 		}
 		errs = append(errs, r.Errors...)
 	}
@@ -402,6 +850,8 @@ func responseApiError(r *Response) *MediaWikiApiError {
 	return &MediaWikiApiError{
 		Code:       code,
 		Message:    msg,
+		Action:     r.action,
+		RequestURL: r.requestURL,
 		HTTPStatus: r.StatusCode,
 		Errors:     errs,
 		Response:   r,
@@ -1,14 +1,20 @@
 package mwapi
 
 import (
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
 )
 
 func TestPostWithToken_RetryOnBadToken(t *testing.T) {
@@ -256,3 +262,631 @@ func TestCookieJar_PersistsAfterLogin(t *testing.T) {
 		t.Fatalf("expected session cookie to be sent after login")
 	}
 }
+
+func TestMaxResponseBytes_PreservesPartialBody(t *testing.T) {
+	t.Parallel()
+
+	body := `{"query":{"pages":[` + strings.Repeat(`{"title":"x"},`, 100) + `{}]}}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	c := New(srv.URL+"/api.php", WithMaxResponseBytes(64))
+	resp, err := c.Get(ctx, map[string]any{"action": "query"})
+	if !errors.Is(err, ErrResponseTooLarge) {
+		t.Fatalf("err = %v, want ErrResponseTooLarge", err)
+	}
+	if !strings.Contains(err.Error(), "response exceeded 64 bytes") {
+		t.Fatalf("err message = %q", err.Error())
+	}
+	var tooLarge *ResponseTooLargeError
+	if !errors.As(err, &tooLarge) || string(tooLarge.Response.Raw) != body[:64] {
+		t.Fatalf("partial body not preserved on error: %#v", tooLarge)
+	}
+	if resp == nil || string(resp.Raw) != body[:64] {
+		t.Fatalf("partial body not preserved on response")
+	}
+
+	// A body of exactly the limit is not an error.
+	c = New(srv.URL+"/api.php", WithMaxResponseBytes(int64(len(body))))
+	if _, err := c.Get(ctx, map[string]any{"action": "query"}); err != nil {
+		t.Fatalf("Get at exact limit: %v", err)
+	}
+}
+
+func TestKeepLogin_AssertAnonSuppressesAssertUser(t *testing.T) {
+	t.Parallel()
+
+	var sawAnonQuery atomic.Bool
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+
+		action := r.Form.Get("action")
+		if action == "query" && r.Form.Get("meta") == "tokens" && r.Form.Get("type") == "login" {
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"query": map[string]any{"tokens": map[string]any{"logintoken": "LOGIN_TOKEN"}},
+			})
+			return
+		}
+		if action == "login" {
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"login": map[string]any{"result": "Success", "lguserid": 1, "lgusername": "UserA"},
+			})
+			return
+		}
+		if action == "query" && r.Form.Get("assert") == "anon" {
+			sawAnonQuery.Store(true)
+			if got := r.Form.Get("assertuser"); got != "" {
+				t.Errorf("assertuser=%q sent alongside assert=anon", got)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"query": map[string]any{}})
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"error": map[string]any{"code": "badtest", "info": "unhandled request"},
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	c := New(srv.URL+"/api.php", WithKeepLogin(true))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	if _, err := c.Login(ctx, "UserA", "pass"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if _, err := c.Get(ctx, map[string]any{
+		"action": "query",
+		"assert": "anon",
+	}); err != nil {
+		t.Fatalf("Get(assert=anon): %v", err)
+	}
+	if !sawAnonQuery.Load() {
+		t.Fatalf("assert=anon query was not sent")
+	}
+}
+
+func TestGzipHTMLErrorPage_IsHTTPError(t *testing.T) {
+	t.Parallel()
+
+	page := `<html><head><title>502 Bad Gateway</title><style>body{color:red}</style></head>` +
+		`<body><h1>502 Bad Gateway</h1><p>upstream timed out</p></body></html>`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Encoding", "GZIP")
+		w.WriteHeader(http.StatusBadGateway)
+		gz := gzip.NewWriter(w)
+		_, _ = gz.Write([]byte(page))
+		_ = gz.Close()
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	for name, rt := range map[string]http.RoundTripper{
+		"transport decodes": http.DefaultTransport,
+		"client decodes":    &http.Transport{DisableCompression: true},
+	} {
+		c := New(srv.URL+"/api.php", WithTransport(rt))
+		_, err := c.Get(ctx, map[string]any{"action": "query"})
+
+		var httpErr *HTTPError
+		if !errors.As(err, &httpErr) {
+			t.Fatalf("%s: err = %v, want *HTTPError", name, err)
+		}
+		if httpErr.StatusCode != http.StatusBadGateway {
+			t.Fatalf("%s: status = %d", name, httpErr.StatusCode)
+		}
+		if !strings.Contains(httpErr.Snippet, "upstream timed out") || strings.Contains(httpErr.Snippet, "<") {
+			t.Fatalf("%s: snippet = %q", name, httpErr.Snippet)
+		}
+	}
+}
+
+func TestCompression(t *testing.T) {
+	t.Parallel()
+
+	var ignore atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := `{"query":{"pad":"` + strings.Repeat("a", 4096) + `"}}`
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Accept-Encoding", r.Header.Get("Accept-Encoding"))
+		if ignore.Load() || r.Header.Get("Accept-Encoding") != "gzip" {
+			_, _ = w.Write([]byte(body))
+			return
+		}
+		w.Header().Set("Content-Encoding", "Gzip")
+		gz := gzip.NewWriter(w)
+		_, _ = gz.Write([]byte(body))
+		_ = gz.Close()
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	c := New(srv.URL + "/api.php")
+	resp, err := c.Get(ctx, map[string]any{"action": "query"})
+	if err != nil {
+		t.Fatalf("Get(gzip): %v", err)
+	}
+	if got := resp.Header.Get("X-Accept-Encoding"); got != "gzip" {
+		t.Fatalf("Accept-Encoding = %q, want gzip", got)
+	}
+	if !strings.Contains(string(resp.Raw), strings.Repeat("a", 4096)) {
+		t.Fatalf("body not decompressed: %.40q", resp.Raw)
+	}
+
+	// The limit applies to the decompressed body, not the bytes on the wire.
+	small := New(srv.URL+"/api.php", WithMaxResponseBytes(1024))
+	var tooLarge *ResponseTooLargeError
+	if _, err := small.Get(ctx, map[string]any{"action": "query"}); !errors.As(err, &tooLarge) {
+		t.Fatalf("err = %v, want *ResponseTooLargeError", err)
+	}
+
+	off := New(srv.URL+"/api.php", WithCompression(false))
+	resp, err = off.Get(ctx, map[string]any{"action": "query"})
+	if err != nil {
+		t.Fatalf("Get(identity): %v", err)
+	}
+	if got := resp.Header.Get("X-Accept-Encoding"); got != "identity" {
+		t.Fatalf("Accept-Encoding = %q, want identity", got)
+	}
+
+	ignore.Store(true)
+	if _, err := c.Get(ctx, map[string]any{"action": "query"}); err != nil {
+		t.Fatalf("Get(server ignores gzip): %v", err)
+	}
+}
+
+func TestResponse_DatabaseLag(t *testing.T) {
+	t.Parallel()
+
+	var lagged atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if lagged.Load() {
+			w.Header().Set("X-Database-Lag", "3")
+			w.Header().Set("Retry-After", "5")
+		}
+		_, _ = w.Write([]byte(`{"batchcomplete":true}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c := New(srv.URL + "/api.php")
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	resp, err := c.Get(ctx, map[string]any{"action": "query"})
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if resp.DatabaseLag != 0 {
+		t.Fatalf("DatabaseLag = %v, want 0 without header", resp.DatabaseLag)
+	}
+
+	lagged.Store(true)
+	resp, err = c.Get(ctx, map[string]any{"action": "query"})
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if resp.DatabaseLag != 3*time.Second {
+		t.Fatalf("DatabaseLag = %v, want 3s", resp.DatabaseLag)
+	}
+}
+
+func TestThrowOnApiError_PerCallOverride(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("parse", func(r *mwtest.Request) any {
+		return mwtest.ErrorResponse("missingtitle", "The page you specified doesn't exist.")
+	})
+	wiki.Handle("edit", func(r *mwtest.Request) any {
+		return mwtest.ErrorResponse("protectedpage", "This page has been protected.")
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+	probe := map[string]any{"action": "parse", "page": "Nope"}
+
+	lenient := New(wiki.URL())
+	if _, err := lenient.Get(ctx, probe); err != nil {
+		t.Fatalf("default Get: %v", err)
+	}
+	if _, err := lenient.Get(ctx, probe, ThrowOnApiError(true)); err == nil {
+		t.Fatalf("Get with ThrowOnApiError(true) returned no error")
+	}
+	if _, err := lenient.PostWithToken(ctx, TokenCSRF, map[string]any{"action": "edit", "title": "X"}, nil,
+		ThrowOnApiError(true)); err == nil {
+		t.Fatalf("PostWithToken with ThrowOnApiError(true) returned no error")
+	}
+
+	strict := New(wiki.URL(), WithThrowOnApiError(true))
+	if _, err := strict.Get(ctx, probe); err == nil {
+		t.Fatalf("strict Get returned no error")
+	}
+	resp, err := strict.Get(ctx, probe, ThrowOnApiError(false))
+	if err != nil {
+		t.Fatalf("Get with ThrowOnApiError(false): %v", err)
+	}
+	if code := responseErrorCode(resp); code != "missingtitle" {
+		t.Fatalf("envelope code = %q, want missingtitle", code)
+	}
+}
+
+func TestMediaWikiApiError_Action(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("edit", func(r *mwtest.Request) any {
+		return mwtest.ErrorResponse("permissiondenied", "You do not have permission to edit this page.")
+	})
+	wiki.Handle("move", func(r *mwtest.Request) any {
+		return mwtest.ErrorResponse("permissiondenied", "You do not have permission to move this page.")
+	})
+
+	c := New(wiki.URL(), WithThrowOnApiError(true))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	for _, action := range []string{"edit", "move"} {
+		_, err := c.PostWithToken(ctx, TokenCSRF, map[string]any{"action": action, "title": "Main Page"}, nil)
+		e, ok := IsMediaWikiApiError(err)
+		if !ok || e.Code != "permissiondenied" {
+			t.Fatalf("%s: err = %v, want permissiondenied", action, err)
+		}
+		if e.Action != action {
+			t.Fatalf("%s: Action = %q", action, e.Action)
+		}
+		if !strings.Contains(err.Error(), "action="+action) {
+			t.Fatalf("%s: message %q does not name the action", action, err.Error())
+		}
+	}
+}
+
+func TestTooManyValues_DescriptiveError(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("query", func(r *mwtest.Request) any {
+		if n := len(strings.Split(r.Param("titles"), "|")); n > 50 {
+			return map[string]any{"errors": []any{map[string]any{
+				"code":   "toomanyvalues",
+				"text":   `Too many values supplied for parameter "titles". The limit is 50.`,
+				"data":   map[string]any{"limit": 50, "lowlimit": 50, "highlimit": 500},
+				"module": "main",
+			}}}
+		}
+		return map[string]any{"query": map[string]any{"pages": []any{}}}
+	})
+
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	titles := make([]string, 60)
+	for i := range titles {
+		titles[i] = fmt.Sprintf("Page %d", i)
+	}
+	_, err := c.Get(ctx, map[string]any{"action": "query", "titles": titles}, ThrowOnApiError(true))
+
+	var tmv *TooManyValuesError
+	if !errors.As(err, &tmv) {
+		t.Fatalf("err = %v, want *TooManyValuesError", err)
+	}
+	if tmv.Param != "titles" || tmv.Limit != 50 || tmv.Sent != 60 {
+		t.Fatalf("error = %+v", tmv)
+	}
+	if !errors.Is(err, ErrTooManyValues) || !strings.Contains(err.Error(), "titles: sent 60, limit is 50") {
+		t.Fatalf("err = %q", err)
+	}
+
+	for _, err := range c.EachPage(ctx, map[string]any{"titles": titles}) {
+		if !errors.As(err, &tmv) || tmv.Param != "titles" || tmv.Limit != 50 {
+			t.Fatalf("EachPage err = %v", err)
+		}
+	}
+}
+
+func TestEndpointResolver_RoutesLoginToCentralWiki(t *testing.T) {
+	t.Parallel()
+
+	central := mwtest.NewFakeWiki(t)
+	central.AddUser("UserA", "pass")
+	local := mwtest.NewFakeWiki(t)
+	local.Handle("query", func(r *mwtest.Request) any {
+		return map[string]any{"query": map[string]any{"allpages": []any{}}}
+	})
+
+	centralURL, err := url.Parse(central.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := New(local.URL(), WithKeepLogin(false), WithEndpointResolver(
+		func(ctx context.Context, action string, params url.Values) (*url.URL, error) {
+			// The login token must come from the same wiki as the login itself.
+			if action == "login" || (params.Get("meta") == "tokens" && params.Get("type") == "login") {
+				return centralURL, nil
+			}
+			return nil, nil
+		}))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	if _, err := c.Login(ctx, "UserA", "pass"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if _, err := c.Get(ctx, map[string]any{"action": "query", "list": "allpages"}); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	if n := len(central.RequestsFor("login")); n != 1 {
+		t.Fatalf("central login requests = %d, want 1", n)
+	}
+	if n := len(local.RequestsFor("login")); n != 0 {
+		t.Fatalf("local login requests = %d, want 0", n)
+	}
+	if local.LastRequest("query").Param("list") != "allpages" {
+		t.Fatalf("query was not sent to the local wiki")
+	}
+	for _, r := range central.RequestsFor("query") {
+		if r.Param("list") == "allpages" {
+			t.Fatalf("content query was sent to the central wiki")
+		}
+	}
+}
+
+func TestMWError_RawErrorFormat(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("parse", func(r *mwtest.Request) any {
+		if r.Param("errorformat") != "raw" {
+			t.Errorf("errorformat = %q, want raw", r.Param("errorformat"))
+		}
+		return map[string]any{"errors": []any{map[string]any{
+			"code":   "missingtitle",
+			"key":    "apierror-missingtitle-byname",
+			"params": []any{"Nope", 3},
+			"module": "parse",
+		}}}
+	})
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	resp, err := c.Get(ctx, map[string]any{"action": "parse", "page": "Nope", "errorformat": "raw"})
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	e, ok := IsMediaWikiApiError(apiError(resp))
+	if !ok || len(e.Errors) != 1 {
+		t.Fatalf("envelope error = %v, want one API error", e)
+	}
+	got := e.Errors[0]
+	if got.Key != "apierror-missingtitle-byname" || len(got.Params) != 2 || got.Params[0] != "Nope" || got.Params[1] != float64(3) {
+		t.Fatalf("error = %+v, want key and params decoded", got)
+	}
+	if e.Message != "apierror-missingtitle-byname" {
+		t.Fatalf("Message = %q, want the key when there is no text", e.Message)
+	}
+}
+
+func TestErrors_CarryRedactedRequestURL(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("action") == "parse" {
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte("<h1>Bad Gateway</h1>"))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"code": "badvalue", "info": "nope"}})
+	}))
+	c := New(srv.URL+"/api.php", WithThrowOnApiError(true))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	params := map[string]any{"action": "parse", "page": "Main Page", "token": "SECRET+\\", "lgpassword": "hunter2"}
+	_, err := c.Get(ctx, params)
+	var he *HTTPError
+	if !errors.As(err, &he) {
+		t.Fatalf("err = %v, want *HTTPError", err)
+	}
+	checkURL := func(kind, u, action string) {
+		t.Helper()
+		if !strings.Contains(u, "action="+action) || !strings.Contains(u, "page=Main+Page") {
+			t.Fatalf("%s RequestURL = %q, want the query string", kind, u)
+		}
+		if strings.Contains(u, "SECRET") || strings.Contains(u, "hunter2") || strings.Contains(u, "token=") {
+			t.Fatalf("%s RequestURL = %q, want secrets removed", kind, u)
+		}
+	}
+	checkURL("HTTPError", he.RequestURL, "parse")
+
+	params["action"] = "query"
+	_, err = c.Get(ctx, params)
+	e, ok := IsMediaWikiApiError(err)
+	if !ok {
+		t.Fatalf("err = %v, want API error", err)
+	}
+	checkURL("MediaWikiApiError", e.RequestURL, "query")
+
+	srv.Close()
+	_, err = c.Get(ctx, params)
+	var te *TransportError
+	if !errors.As(err, &te) {
+		t.Fatalf("err = %v, want *TransportError", err)
+	}
+	checkURL("TransportError", te.RequestURL, "query")
+}
+
+func TestWithTimeout_AppliesInAnyOrder(t *testing.T) {
+	t.Parallel()
+
+	rt := http.DefaultTransport
+	for name, opts := range map[string][]Option{
+		"before": {WithTimeout(7 * time.Second), WithTransport(rt), WithHTTPClient(&http.Client{})},
+		"after":  {WithHTTPClient(&http.Client{}), WithTimeout(7 * time.Second), WithTransport(rt)},
+	} {
+		c := New("https://example.org/api.php", opts...)
+		if c.hc.Timeout != 7*time.Second {
+			t.Errorf("%s: timeout = %v, want 7s", name, c.hc.Timeout)
+		}
+		if c.hc.Transport != rt {
+			t.Errorf("%s: transport not applied", name)
+		}
+	}
+}
+
+func TestWithHTTPClient_DoesNotMutateSharedClient(t *testing.T) {
+	t.Parallel()
+
+	shared := &http.Client{Timeout: time.Minute}
+	c := New("https://example.org/api.php",
+		WithHTTPClient(shared),
+		WithTimeout(5*time.Second),
+		WithTransport(http.DefaultTransport),
+		WithInsecureSkipVerify(true),
+	)
+
+	if shared.Timeout != time.Minute || shared.Transport != nil || shared.Jar != nil {
+		t.Fatalf("shared client modified: %+v", shared)
+	}
+	if c.hc == shared {
+		t.Fatal("client uses the shared *http.Client directly")
+	}
+	if c.hc.Timeout != 5*time.Second || c.hc.Jar == nil {
+		t.Fatalf("copy not configured: timeout=%v jar=%v", c.hc.Timeout, c.hc.Jar)
+	}
+}
+
+func TestGet_SwitchesToPostForLongQueries(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("query", func(r *mwtest.Request) any {
+		return map[string]any{"batchcomplete": true}
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	titles := make([]string, 500)
+	for i := range titles {
+		titles[i] = fmt.Sprintf("Page number %d", i)
+	}
+	long := map[string]any{"prop": "info", "titles": titles}
+
+	c := New(wiki.URL())
+	if _, err := c.Get(ctx, map[string]any{"prop": "info", "titles": "A"}); err != nil {
+		t.Fatalf("Get(short): %v", err)
+	}
+	if m := wiki.LastRequest("query").Method; m != http.MethodGet {
+		t.Fatalf("short query sent as %s", m)
+	}
+	if _, err := c.Get(ctx, long); err != nil {
+		t.Fatalf("Get(long): %v", err)
+	}
+	last := wiki.LastRequest("query")
+	if last.Method != http.MethodPost || last.Param("titles") != strings.Join(titles, "|") || last.Param("action") != "query" {
+		t.Fatalf("long query sent as %s with titles=%.40q", last.Method, last.Param("titles"))
+	}
+
+	c = New(wiki.URL(), WithAutoPostThreshold(0))
+	if _, err := c.Get(ctx, long); err != nil {
+		t.Fatalf("Get(long, disabled): %v", err)
+	}
+	if m := wiki.LastRequest("query").Method; m != http.MethodGet {
+		t.Fatalf("with threshold 0 the query was sent as %s", m)
+	}
+}
+
+func TestRequestOptions_PerCall(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.AddUser("UserA", "secret")
+	wiki.Handle("parse", func(r *mwtest.Request) any {
+		if r.Param("page") == "Slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		return map[string]any{"parse": map[string]any{}}
+	})
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+	if _, err := c.Login(ctx, "UserA", "secret"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+
+	if _, err := c.Get(ctx, map[string]any{"action": "parse", "page": "A"}, SkipAssert(), RequestHeader("X-Trace", "one")); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	last := wiki.LastRequest("parse")
+	if last.Param("assertuser") != "" || last.Header.Get("X-Trace") != "one" {
+		t.Fatalf("assertuser=%q X-Trace=%q", last.Param("assertuser"), last.Header.Get("X-Trace"))
+	}
+
+	if _, err := c.Get(ctx, map[string]any{"action": "parse", "page": "A"}); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	last = wiki.LastRequest("parse")
+	if last.Param("assertuser") != "UserA" || last.Header.Get("X-Trace") != "" {
+		t.Fatalf("options leaked into the next call: assertuser=%q X-Trace=%q", last.Param("assertuser"), last.Header.Get("X-Trace"))
+	}
+
+	_, err := c.Get(ctx, map[string]any{"action": "parse", "page": "Slow"}, RequestTimeout(20*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if _, err := c.Get(ctx, map[string]any{"action": "parse", "page": "Slow"}); err != nil {
+		t.Fatalf("Get without timeout: %v", err)
+	}
+}
+
+func TestWithAssertBot(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.AddUser("UserA", "secret")
+	wiki.Handle("parse", func(r *mwtest.Request) any {
+		if r.Param("assert") != "bot" {
+			return mwtest.ErrorResponse("badtest", "assert=bot not sent")
+		}
+		return mwtest.ErrorResponse("assertbotfailed", "You do not have the bot right.")
+	})
+
+	c := New(wiki.URL(), WithAssertBot(true))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	if _, err := c.Login(ctx, "UserA", "secret"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if got := wiki.RequestsFor("login")[0].Param("assert"); got != "" {
+		t.Fatalf("login sent assert=%q", got)
+	}
+
+	_, err := c.Get(ctx, map[string]any{"action": "parse", "page": "A"})
+	if !errors.Is(err, ErrAssertBotFailed) {
+		t.Fatalf("err = %v, want ErrAssertBotFailed", err)
+	}
+	if n := len(wiki.RequestsFor("login")); n != 1 {
+		t.Fatalf("login requests = %d, want 1 (no relogin)", n)
+	}
+
+	// An explicit assert from the caller wins.
+	_, _ = c.Get(ctx, map[string]any{"action": "query", "assert": "user"})
+	if got := wiki.RequestsFor("query"); got[len(got)-1].Param("assert") != "user" {
+		t.Fatalf("caller's assert overridden")
+	}
+}
@@ -0,0 +1,148 @@
+package mwapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Statuses of an action=clientlogin step.
+const (
+	ClientLoginPass     = "PASS"
+	ClientLoginFail     = "FAIL"
+	ClientLoginUI       = "UI"
+	ClientLoginRedirect = "REDIRECT"
+	ClientLoginRestart  = "RESTART"
+)
+
+type ClientLoginParams struct {
+	Username string
+	Password string
+	// ReturnURL is where REDIRECT flows (e.g. third-party login) come back
+	// to; defaults to the endpoint URL.
+	ReturnURL string
+	// Fields holds any further fields the wiki asks for up front, e.g.
+	// "rememberMe".
+	Fields map[string]string
+}
+
+type ClientLoginResult struct {
+	Status         string        `json:"status"`
+	Username       string        `json:"username,omitempty"`
+	Message        string        `json:"message,omitempty"`
+	MessageCode    string        `json:"messagecode,omitempty"`
+	RedirectTarget string        `json:"redirecttarget,omitempty"`
+	Requests       []AuthRequest `json:"requests,omitempty"`
+}
+
+// RequestedFields lists the names of the fields a UI step asks for (e.g.
+// OATHToken), to be sent with ClientLoginContinue.
+func (r *ClientLoginResult) RequestedFields() []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, req := range r.Requests {
+		for name := range req.Fields {
+			if !seen[name] {
+				seen[name] = true
+				out = append(out, name)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// ClientLogin starts an action=clientlogin flow, which, unlike Login, also
+// works for accounts with two-factor authentication. A UI status asks for
+// more fields (see RequestedFields), to be answered with ClientLoginContinue.
+// FAIL and RESTART are returned together with an error. Relogin cannot
+// repeat an interactive login, so it is not available after one.
+func (c *Client) ClientLogin(ctx context.Context, params ClientLoginParams) (*ClientLoginResult, error) {
+	if params.Username == "" {
+		return nil, errors.New("clientlogin: missing username")
+	}
+	returnURL := params.ReturnURL
+	if returnURL == "" {
+		returnURL = c.endpoint.String()
+	}
+	p := map[string]any{
+		"action":         "clientlogin",
+		"username":       params.Username,
+		"password":       params.Password,
+		"loginreturnurl": returnURL,
+	}
+	for k, v := range params.Fields {
+		p[k] = v
+	}
+	// A new flow needs a login token from the current session.
+	c.InvalidateToken(TokenLogin)
+	return c.clientLogin(ctx, p)
+}
+
+// ClientLoginContinue answers a UI step of ClientLogin with fields, e.g.
+// {"OATHToken": "123456"}.
+func (c *Client) ClientLoginContinue(ctx context.Context, fields map[string]string) (*ClientLoginResult, error) {
+	p := map[string]any{
+		"action":        "clientlogin",
+		"logincontinue": true,
+	}
+	for k, v := range fields {
+		p[k] = v
+	}
+	return c.clientLogin(ctx, p)
+}
+
+func (c *Client) clientLogin(ctx context.Context, p map[string]any) (_ *ClientLoginResult, err error) {
+	ctx, done := c.startCall(ctx)
+	defer func() { err = done(err) }()
+	if c.oauthToken != "" {
+		return nil, ErrOAuthLogin
+	}
+
+	tok, err := c.GetToken(ctx, TokenLogin)
+	if err != nil {
+		return nil, err
+	}
+	p["logintoken"] = tok
+	p["loginmessageformat"] = "plaintext"
+
+	resp, err := c.Post(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	if err := apiError(resp); err != nil {
+		return nil, err
+	}
+	var out struct {
+		ClientLogin *ClientLoginResult `json:"clientlogin"`
+	}
+	if err := json.Unmarshal(resp.Raw, &out); err != nil {
+		return nil, err
+	}
+	res := out.ClientLogin
+	if res == nil || res.Status == "" {
+		return nil, errors.New("missing clientlogin.status in response")
+	}
+
+	switch strings.ToUpper(res.Status) {
+	case ClientLoginPass:
+		c.mu.Lock()
+		c.loggedInUser = res.Username
+		c.rights = nil
+		c.relogin = nil
+		c.mu.Unlock()
+
+		c.InvalidateAllTokens()
+		if err := c.runAfterLogin(ctx, &LoginResult{Result: "Success", LgName: res.Username}); err != nil {
+			return res, err
+		}
+		return res, nil
+	case ClientLoginFail, ClientLoginRestart:
+		return res, fmt.Errorf("clientlogin failed: %s: %s", res.Status, firstNonEmpty(res.Message, res.MessageCode))
+	default:
+		return res, nil
+	}
+}
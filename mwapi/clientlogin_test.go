@@ -0,0 +1,71 @@
+package mwapi
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+func TestClientLogin_TwoFactor(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("clientlogin", func(r *mwtest.Request) any {
+		if r.Param("logintoken") == "" || r.Param("assertuser") != "" {
+			return mwtest.ErrorResponse("badtest", "missing logintoken or unexpected assertuser")
+		}
+		switch {
+		case r.Param("logincontinue") == "" && r.Param("password") != "secret":
+			return map[string]any{"clientlogin": map[string]any{
+				"status": "FAIL", "message": "Incorrect username or password entered.", "messagecode": "wrongpassword",
+			}}
+		case r.Param("logincontinue") == "":
+			return map[string]any{"clientlogin": map[string]any{
+				"status":  "UI",
+				"message": "Enter a verification code from your authenticator app.",
+				"requests": []any{map[string]any{
+					"id":     "MediaWiki\\Extension\\OATHAuth\\Auth\\TOTPAuthenticationRequest",
+					"fields": map[string]any{"OATHToken": map[string]any{"type": "string", "label": "Token"}},
+				}},
+			}}
+		case r.Param("OATHToken") == "123456":
+			return map[string]any{"clientlogin": map[string]any{"status": "PASS", "username": "UserA"}}
+		}
+		return map[string]any{"clientlogin": map[string]any{"status": "FAIL", "messagecode": "oathauth-login-failed"}}
+	})
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	res, err := c.ClientLogin(ctx, ClientLoginParams{Username: "UserA", Password: "wrong"})
+	if err == nil || res == nil || res.Status != ClientLoginFail || !strings.Contains(err.Error(), "Incorrect") {
+		t.Fatalf("wrong password: res = %+v, err = %v", res, err)
+	}
+
+	res, err = c.ClientLogin(ctx, ClientLoginParams{Username: "UserA", Password: "secret"})
+	if err != nil {
+		t.Fatalf("ClientLogin: %v", err)
+	}
+	if res.Status != ClientLoginUI || strings.Join(res.RequestedFields(), ",") != "OATHToken" {
+		t.Fatalf("result = %+v, want UI asking for OATHToken", res)
+	}
+	wiki.AssertSent("clientlogin", "loginreturnurl", wiki.URL())
+
+	res, err = c.ClientLoginContinue(ctx, map[string]string{"OATHToken": "123456"})
+	if err != nil {
+		t.Fatalf("ClientLoginContinue: %v", err)
+	}
+	if res.Status != ClientLoginPass || res.Username != "UserA" {
+		t.Fatalf("result = %+v, want PASS", res)
+	}
+	wiki.AssertSent("clientlogin", "logincontinue", "1")
+	c.mu.Lock()
+	user := c.loggedInUser
+	c.mu.Unlock()
+	if user != "UserA" {
+		t.Fatalf("loggedInUser = %q", user)
+	}
+}
@@ -0,0 +1,56 @@
+package mwapi
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrClientClosed is returned for requests started after Close.
+var ErrClientClosed = errors.New("client is closed")
+
+type lifecycle struct {
+	mu       sync.Mutex
+	closed   bool
+	inflight sync.WaitGroup
+}
+
+// enter registers an HTTP request; the caller must call exit when done.
+func (l *lifecycle) enter() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return ErrClientClosed
+	}
+	l.inflight.Add(1)
+	return nil
+}
+
+func (l *lifecycle) exit() {
+	l.inflight.Done()
+}
+
+// Close stops the client: new requests fail with ErrClientClosed, requests
+// already on the wire are waited for (until ctx ends), and idle connections
+// are closed. A multi-request call such as PostWithToken that is between
+// requests fails rather than completes. Close can be called more than once.
+func (c *Client) Close(ctx context.Context) error {
+	c.life.mu.Lock()
+	c.life.closed = true
+	c.life.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		c.life.inflight.Wait()
+		close(drained)
+	}()
+
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	c.hc.CloseIdleConnections()
+	return err
+}
@@ -0,0 +1,57 @@
+package mwapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClose_DrainsInFlightRequests(t *testing.T) {
+	t.Parallel()
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		_ = json.NewEncoder(w).Encode(map[string]any{"batchcomplete": true})
+	}))
+	t.Cleanup(srv.Close)
+
+	c := New(srv.URL + "/api.php")
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	slow := make(chan error, 1)
+	go func() {
+		_, err := c.Get(ctx, map[string]any{"meta": "siteinfo"})
+		slow <- err
+	}()
+	<-started
+
+	closed := make(chan error, 1)
+	go func() { closed <- c.Close(ctx) }()
+
+	// Close must not return while the request is still on the wire.
+	select {
+	case err := <-closed:
+		t.Fatalf("Close returned %v before the in-flight request finished", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if _, err := c.Get(ctx, map[string]any{"meta": "siteinfo"}); !errors.Is(err, ErrClientClosed) {
+		t.Fatalf("Get after Close: err = %v, want ErrClientClosed", err)
+	}
+
+	close(release)
+	if err := <-slow; err != nil {
+		t.Fatalf("in-flight Get: %v", err)
+	}
+	if err := <-closed; err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package mwapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// PageInfo is the metadata returned alongside page content. Timestamp is that
+// of the latest revision, CurTimestamp the server time of the fetch.
+type PageInfo struct {
+	PageID       int64
+	NS           int
+	Title        string
+	LastRevID    int64
+	Timestamp    string
+	CurTimestamp string
+}
+
+// EditParams returns EditParams for the fetched page with BaseTimestamp and
+// StartTimestamp set, so the edit fails with an edit conflict if the page
+// changed, or was deleted, after the fetch.
+func (pi *PageInfo) EditParams() EditParams {
+	p := EditParams{Title: pi.Title}
+	if t, err := ParseMWTimestamp(pi.Timestamp); err == nil {
+		p.BaseTimestamp = t
+	}
+	if t, err := ParseMWTimestamp(pi.CurTimestamp); err == nil {
+		p.StartTimestamp = t
+	}
+	return p
+}
+
+// PageContent is the main-slot wikitext of a page with its metadata.
+type PageContent struct {
+	PageInfo
+	Content string
+}
+
+// GetPageContent returns the main-slot content of the latest revision of
+// title. A missing or invalid title yields ErrPageMissing.
+func (c *Client) GetPageContent(ctx context.Context, title string) (string, *PageInfo, error) {
+	if title == "" {
+		return "", nil, errors.New("get page content: missing title")
+	}
+	pages, err := c.GetPagesContent(ctx, []string{title})
+	if err != nil {
+		return "", nil, err
+	}
+	pc, ok := pages[title]
+	if !ok {
+		return "", nil, fmt.Errorf("%w: %s", ErrPageMissing, title)
+	}
+	return pc.Content, &pc.PageInfo, nil
+}
+
+// GetPagesContent is GetPageContent for many titles, keyed by the title as
+// passed in. Missing or invalid pages have no entry.
+func (c *Client) GetPagesContent(ctx context.Context, titles []string) (map[string]PageContent, error) {
+	out := make(map[string]PageContent, len(titles))
+	for start := 0; start < len(titles); start += maxTitlesPerQuery {
+		end := min(start+maxTitlesPerQuery, len(titles))
+		chunk := titles[start:end]
+
+		err := c.queryContinue(ctx, map[string]any{
+			"action":        "query",
+			"titles":        chunk,
+			"prop":          []string{"info", "revisions"},
+			"rvprop":        []string{"ids", "timestamp", "content"},
+			"rvslots":       "main",
+			"curtimestamp":  true,
+			"formatversion": 2,
+		}, nil, func(resp *Response) error {
+			pages, err := resp.Pages()
+			if err != nil {
+				return err
+			}
+			inputs := inputTitles(resp, chunk)
+			for _, pg := range pages {
+				if pg.Missing || pg.Invalid {
+					continue
+				}
+				var v struct {
+					Revisions []Revision `json:"revisions"`
+				}
+				if err := json.Unmarshal(pg.Raw, &v); err != nil {
+					return err
+				}
+				// Content too large for this batch comes in a later continuation.
+				if len(v.Revisions) == 0 {
+					continue
+				}
+				rev := v.Revisions[0]
+				pc := PageContent{
+					PageInfo: PageInfo{
+						PageID:       pg.PageID,
+						NS:           pg.NS,
+						Title:        pg.Title,
+						LastRevID:    pg.LastRevID,
+						Timestamp:    rev.Timestamp,
+						CurTimestamp: resp.CurTimestamp,
+					},
+					Content: rev.MainContent(),
+				}
+				if pc.LastRevID == 0 {
+					pc.LastRevID = rev.RevID
+				}
+				for _, in := range inputs[pg.Title] {
+					out[in] = pc
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,68 @@
+package mwapi
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+func TestGetPageContent(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("query", func(r *mwtest.Request) any {
+		var pages []any
+		var normalized []any
+		for _, title := range strings.Split(r.Param("titles"), "|") {
+			switch title {
+			case "main Page":
+				normalized = append(normalized, map[string]any{"from": "main Page", "to": "Main Page"})
+				pages = append(pages, map[string]any{"pageid": 1, "ns": 0, "title": "Main Page", "lastrevid": 42,
+					"revisions": []any{map[string]any{"revid": 42, "timestamp": "2024-01-31T12:00:00Z",
+						"slots": map[string]any{"main": map[string]any{"content": "Welcome"}}}}})
+			case "Nope":
+				pages = append(pages, map[string]any{"ns": 0, "title": "Nope", "missing": true})
+			}
+		}
+		return map[string]any{"curtimestamp": "2024-02-01T00:00:00Z",
+			"query": map[string]any{"normalized": normalized, "pages": pages}}
+	})
+
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	content, info, err := c.GetPageContent(ctx, "main Page")
+	if err != nil {
+		t.Fatalf("GetPageContent: %v", err)
+	}
+	want := PageInfo{PageID: 1, NS: 0, Title: "Main Page", LastRevID: 42,
+		Timestamp: "2024-01-31T12:00:00Z", CurTimestamp: "2024-02-01T00:00:00Z"}
+	if content != "Welcome" || *info != want {
+		t.Fatalf("got %q, %+v; want Welcome, %+v", content, *info, want)
+	}
+	wiki.AssertSent("query", "rvslots", "main")
+	wiki.AssertSent("query", "formatversion", "2")
+
+	ep := info.EditParams()
+	if ep.Title != "Main Page" || !ep.BaseTimestamp.Equal(time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)) ||
+		!ep.StartTimestamp.Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("EditParams = %+v", ep)
+	}
+
+	if _, _, err := c.GetPageContent(ctx, "Nope"); !errors.Is(err, ErrPageMissing) {
+		t.Fatalf("missing page err = %v, want ErrPageMissing", err)
+	}
+
+	pages, err := c.GetPagesContent(ctx, []string{"main Page", "Nope"})
+	if err != nil {
+		t.Fatalf("GetPagesContent: %v", err)
+	}
+	if len(pages) != 1 || pages["main Page"].Content != "Welcome" {
+		t.Fatalf("pages = %+v", pages)
+	}
+}
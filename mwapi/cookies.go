@@ -0,0 +1,47 @@
+package mwapi
+
+import (
+	"errors"
+	"net/http"
+)
+
+// WithCookieJar makes the client keep its session cookies in jar, e.g. one
+// persisted to disk, instead of a fresh in-memory jar. It takes precedence
+// over the jar of a WithHTTPClient client. Clones still get jars of their own.
+func WithCookieJar(jar http.CookieJar) Option {
+	return func(c *Client) {
+		c.jar = jar
+	}
+}
+
+// ExportCookies returns the cookies the client would send to the endpoint,
+// to save a session and restore it later with ImportCookies. Only names and
+// values are kept by the standard cookie jar.
+func (c *Client) ExportCookies() ([]*http.Cookie, error) {
+	if c.hc.Jar == nil {
+		return nil, errors.New("export cookies: client has no cookie jar")
+	}
+	return c.hc.Jar.Cookies(c.endpoint), nil
+}
+
+// ImportCookies stores cookies for the endpoint, e.g. ones saved by
+// ExportCookies. Call SetLoggedInUser too, so assertuser keeps checking the
+// restored session.
+func (c *Client) ImportCookies(cookies []*http.Cookie) error {
+	if c.hc.Jar == nil {
+		return errors.New("import cookies: client has no cookie jar")
+	}
+	c.hc.Jar.SetCookies(c.endpoint, cookies)
+	return nil
+}
+
+// SetLoggedInUser tells the client which user its session (restored cookies,
+// a persistent jar) belongs to, without logging in. An empty name marks the
+// session anonymous. There is no stored login method, so Relogin fails until
+// the next Login.
+func (c *Client) SetLoggedInUser(name string) {
+	c.mu.Lock()
+	c.loggedInUser = name
+	c.rights = nil
+	c.mu.Unlock()
+}
@@ -0,0 +1,48 @@
+package mwapi
+
+import (
+	"context"
+	"net/http/cookiejar"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+func TestCookies_ExportImportResumesSession(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.AddUser("UserA", "secret")
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	jar, _ := cookiejar.New(nil)
+	first := New(wiki.URL(), WithCookieJar(jar))
+	if _, err := first.Login(ctx, "UserA", "secret"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	cookies, err := first.ExportCookies()
+	if err != nil || len(cookies) == 0 {
+		t.Fatalf("ExportCookies = %v, %v", cookies, err)
+	}
+	if first.hc.Jar != jar {
+		t.Fatal("WithCookieJar jar not used")
+	}
+
+	second := New(wiki.URL())
+	if err := second.ImportCookies(cookies); err != nil {
+		t.Fatalf("ImportCookies: %v", err)
+	}
+	second.SetLoggedInUser("UserA")
+	if _, err := second.Get(ctx, map[string]any{"meta": "userinfo"}); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	last := wiki.LastRequest("query")
+	if last.User != "UserA" || last.Param("assertuser") != "UserA" {
+		t.Fatalf("request ran as %q with assertuser=%q", last.User, last.Param("assertuser"))
+	}
+	if got := len(wiki.RequestsFor("login")); got != 1 {
+		t.Fatalf("login requests = %d, want 1", got)
+	}
+}
@@ -0,0 +1,65 @@
+package mwapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrDeadlineExceeded reports that WithOverallDeadline fired. It also matches
+// context.DeadlineExceeded for code that only checks for the generic error.
+var ErrDeadlineExceeded error = deadlineExceededError{}
+
+type deadlineExceededError struct{}
+
+func (deadlineExceededError) Error() string { return "overall deadline exceeded" }
+
+func (deadlineExceededError) Is(target error) bool { return target == context.DeadlineExceeded }
+
+// ErrContextCanceled is returned, wrapping the context's error, when the
+// context of a call is done before or during a request. No further retry or
+// relogin is attempted then.
+var ErrContextCanceled = errors.New("request context done")
+
+// canceled wraps err (or, if nil, the context's cause) in ErrContextCanceled.
+func canceled(ctx context.Context, err error) error {
+	if err == nil {
+		err = context.Cause(ctx)
+	}
+	if errors.Is(err, ErrContextCanceled) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrContextCanceled, err)
+}
+
+type overallDeadlineKey struct{}
+
+// WithOverallDeadline bounds the total time of one logical call (Get, Post,
+// PostWithToken, GetToken, Login...) including every token retry, relogin and
+// backoff it triggers. When it fires, the call fails with ErrDeadlineExceeded
+// wrapping the last underlying error. A shorter ctx deadline still applies.
+func WithOverallDeadline(d time.Duration) Option {
+	return func(c *Client) {
+		if d > 0 {
+			c.overallDeadline = d
+		}
+	}
+}
+
+// startCall applies the overall deadline to ctx unless an enclosing call
+// already did. done must be called with the call's result error.
+func (c *Client) startCall(ctx context.Context) (context.Context, func(error) error) {
+	if c.overallDeadline <= 0 || ctx.Value(overallDeadlineKey{}) != nil {
+		return ctx, func(err error) error { return err }
+	}
+	ctx, cancel := context.WithTimeoutCause(ctx, c.overallDeadline, ErrDeadlineExceeded)
+	ctx = context.WithValue(ctx, overallDeadlineKey{}, true)
+	return ctx, func(err error) error {
+		defer cancel()
+		if err == nil || context.Cause(ctx) != ErrDeadlineExceeded || errors.Is(err, ErrDeadlineExceeded) {
+			return err
+		}
+		return fmt.Errorf("%w after %s: %w", ErrDeadlineExceeded, c.overallDeadline, err)
+	}
+}
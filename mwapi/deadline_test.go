@@ -0,0 +1,107 @@
+package mwapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+func TestOverallDeadline_BoundsStackedRetries(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("edit", func(r *mwtest.Request) any {
+		time.Sleep(150 * time.Millisecond)
+		return mwtest.BadToken()
+	})
+
+	// Ten token retries of 150ms each would take 1.5s on their own.
+	c := New(wiki.URL(), WithTokenRetry(10), WithOverallDeadline(400*time.Millisecond))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	start := time.Now()
+	_, err := c.PostWithToken(ctx, TokenCSRF, map[string]any{"action": "edit", "title": "Sandbox"}, nil)
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, ErrDeadlineExceeded) {
+		t.Fatalf("err = %v, want ErrDeadlineExceeded", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, should wrap the underlying context error", err)
+	}
+	if elapsed > time.Second {
+		t.Fatalf("call took %v, want it bounded by the 400ms deadline", elapsed)
+	}
+
+	// The caller's own cancellation is reported as such, not as the overall deadline.
+	short, cancelShort := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	t.Cleanup(cancelShort)
+	_, err = c.PostWithToken(short, TokenCSRF, map[string]any{"action": "edit", "title": "Sandbox"}, nil)
+	if errors.Is(err, ErrDeadlineExceeded) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want plain context deadline", err)
+	}
+}
+
+func TestCanceledContext_NoRelogin(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.AddUser("UserA", "secret")
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+	if _, err := c.Login(ctx, "UserA", "secret"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+
+	callCtx, cancelCall := context.WithCancel(ctx)
+	wiki.Handle("parse", func(r *mwtest.Request) any {
+		cancelCall()
+		return mwtest.ErrorResponse("assertuserfailed", "You are no longer logged in.")
+	})
+	_, err := c.Get(callCtx, map[string]any{"action": "parse", "page": "A"})
+	if !errors.Is(err, ErrContextCanceled) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want ErrContextCanceled wrapping context.Canceled", err)
+	}
+	if n := len(wiki.RequestsFor("login")); n != 1 {
+		t.Fatalf("login requests = %d, want 1 (no relogin after cancel)", n)
+	}
+
+	before := len(wiki.Requests())
+	_, err = c.PostWithToken(callCtx, TokenCSRF, map[string]any{"action": "purge", "titles": "A"}, nil)
+	if !errors.Is(err, ErrContextCanceled) {
+		t.Fatalf("err = %v, want ErrContextCanceled", err)
+	}
+	if n := len(wiki.Requests()) - before; n != 0 {
+		t.Fatalf("requests after cancel = %d, want 0", n)
+	}
+}
+
+func TestCanceledContext_WhilePaced(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("watch", func(r *mwtest.Request) any {
+		return map[string]any{"watch": []any{}}
+	})
+	c := New(wiki.URL(), WithMinWriteInterval(time.Minute))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+	if _, err := c.Login(ctx, "UserA", "secret"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if _, err := c.PostWithToken(ctx, TokenCSRF, map[string]any{"action": "watch", "titles": "A"}, nil); err != nil {
+		t.Fatalf("first write: %v", err)
+	}
+
+	short, cancelShort := context.WithTimeout(ctx, 50*time.Millisecond)
+	t.Cleanup(cancelShort)
+	_, err := c.PostWithToken(short, TokenCSRF, map[string]any{"action": "watch", "titles": "A"}, nil)
+	if !errors.Is(err, ErrContextCanceled) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want ErrContextCanceled wrapping context.DeadlineExceeded", err)
+	}
+}
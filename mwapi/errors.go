@@ -1,27 +1,291 @@
 package mwapi
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"html"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
 type MediaWikiApiError struct {
-	Code       string
-	Message    string
+	Code    string
+	Message string
+	// Action is the action param of the failing request (edit, move, ...).
+	Action string
+	// RequestURL is the request URL with secrets removed (see TransportError).
+	RequestURL string
 	HTTPStatus int
 	Errors     []MWError
 	Response   *Response
+
+	// AssertDiagnostic is set on assertuserfailed errors when
+	// WithAssertDiagnostics is enabled.
+	AssertDiagnostic *AssertDiagnostic
 }
 
 func (e *MediaWikiApiError) Error() string {
-	if e.Code == "" {
-		return e.Message
+	msg := e.Code
+	switch {
+	case e.Code == "":
+		msg = e.Message
+	case e.Message != "":
+		msg = fmt.Sprintf("%s: %s", e.Code, e.Message)
+	}
+	if e.AssertDiagnostic != nil {
+		msg += " (" + e.AssertDiagnostic.String() + ")"
+	}
+	if e.Action != "" {
+		return fmt.Sprintf("action=%s: %s", e.Action, msg)
+	}
+	return msg
+}
+
+// Is lets errors.Is match an API error against the sentinel for its code,
+// e.g. errors.Is(err, ErrInvalidLevel).
+func (e *MediaWikiApiError) Is(target error) bool {
+	sentinel, ok := codeErrors[strings.ToLower(e.Code)]
+	return ok && sentinel == target
+}
+
+// Sentinels for error codes that callers commonly branch on.
+var (
+	ErrInvalidLevel = errors.New("invalid protection level")
+	ErrCantEdit     = errors.New("page cannot be edited or protected")
+
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrRevDelNoChange   = errors.New("revision visibility already as requested")
+
+	ErrCantImport    = errors.New("import not allowed")
+	ErrImportUnknown = errors.New("import failed for an unknown reason")
+
+	// ErrBadTags means a tag is undefined, inactive or not applicable by hand.
+	ErrBadTags = errors.New("tags cannot be applied")
+
+	// ErrPageMissing is returned by read helpers for pages that do not exist
+	// (or have invalid titles), whether the API flags the page or fails outright.
+	ErrPageMissing = errors.New("page does not exist")
+
+	// ErrAssertBotFailed means assert=bot failed: the account has no bot right
+	// (any more). Logging in again does not restore it.
+	ErrAssertBotFailed = errors.New("account is not a bot")
+
+	// ErrOnlyAuthor means a rollback was refused because the user to roll
+	// back is the only author of the page.
+	ErrOnlyAuthor = errors.New("user is the only author of the page")
+)
+
+var codeErrors = map[string]error{
+	"invalidlevel": ErrInvalidLevel,
+	"cantedit":     ErrCantEdit,
+
+	"permissiondenied": ErrPermissionDenied,
+	// The API reports the message key; older releases used the short form.
+	"revdelete-no-change": ErrRevDelNoChange,
+	"revdel-no-change":    ErrRevDelNoChange,
+
+	"notloggedin": ErrNotLoggedIn,
+	"badtags":     ErrBadTags,
+
+	"missingtitle": ErrPageMissing,
+	"nosuchpageid": ErrPageMissing,
+	"invalidtitle": ErrPageMissing,
+
+	"cantimport":          ErrCantImport,
+	"cantimport-upload":   ErrCantImport,
+	"import-unknownerror": ErrImportUnknown,
+
+	"toomanyvalues": ErrTooManyValues,
+
+	"assertbotfailed": ErrAssertBotFailed,
+	"onlyauthor":      ErrOnlyAuthor,
+}
+
+var ErrTooManyValues = errors.New("too many values for a multi-value parameter")
+
+// TooManyValuesError describes a toomanyvalues failure: more values were
+// joined into Param than the wiki allows per request (50, or 500 with apihighlimits).
+type TooManyValuesError struct {
+	Param string
+	Limit int
+	Sent  int // number of values sent, when known
+	Err   *MediaWikiApiError
+}
+
+func (e *TooManyValuesError) Error() string {
+	param := e.Param
+	if param == "" {
+		param = "(unknown)"
+	}
+	msg := fmt.Sprintf("too many values for parameter %s", param)
+	if e.Sent > 0 {
+		msg += fmt.Sprintf(": sent %d", e.Sent)
+	}
+	if e.Limit > 0 {
+		msg += fmt.Sprintf(", limit is %d; split the request into batches", e.Limit)
+	}
+	return msg
+}
+
+func (e *TooManyValuesError) Is(target error) bool { return target == ErrTooManyValues }
+
+func (e *TooManyValuesError) Unwrap() error { return e.Err }
+
+var (
+	reTooManyValuesParam = regexp.MustCompile(`parameter "?([a-z0-9_]+)"?`)
+	reTooManyValuesLimit = regexp.MustCompile(`limit is (\d+)`)
+)
+
+// asTooManyValues rewrites a toomanyvalues API error into a TooManyValuesError;
+// other errors are returned unchanged. values are the params that were sent, if known.
+func asTooManyValues(err error, values url.Values) error {
+	e, ok := IsMediaWikiApiError(err)
+	if !ok || !strings.EqualFold(e.Code, "toomanyvalues") {
+		return err
+	}
+	tmv := &TooManyValuesError{Err: e}
+	for _, me := range e.Errors {
+		var data struct {
+			Limit int `json:"limit"`
+		}
+		if len(me.Data) > 0 && json.Unmarshal(me.Data, &data) == nil && data.Limit > 0 {
+			tmv.Limit = data.Limit
+		}
+	}
+	if m := reTooManyValuesParam.FindStringSubmatch(e.Message); m != nil {
+		tmv.Param = m[1]
 	}
-	if e.Message == "" {
-		return e.Code
+	if tmv.Limit == 0 {
+		if m := reTooManyValuesLimit.FindStringSubmatch(e.Message); m != nil {
+			tmv.Limit, _ = strconv.Atoi(m[1])
+		}
 	}
-	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+	if tmv.Param != "" && values != nil {
+		if v := values.Get(tmv.Param); v != "" {
+			tmv.Sent = len(strings.Split(v, "|"))
+		}
+	}
+	return tmv
+}
+
+// ErrNonJSONFormat is returned with the raw response when the request set a
+// format other than json; Response.Raw holds the body as received.
+var ErrNonJSONFormat = errors.New("response is not in json format")
+
+var ErrResponseTooLarge = errors.New("response too large")
+
+// ErrNotLoggedIn is returned before sending requests that need a session
+// (writes under WithRequireLoginForWrites, watchlist reads) when there is none.
+var ErrNotLoggedIn = errors.New("no logged-in session")
+
+// ErrReloginStorm is returned instead of logging in again once the limit set
+// by WithMaxReloginsPerMinute is reached.
+var ErrReloginStorm = errors.New("too many relogins")
+
+var ErrReadOnlyClient = errors.New("write action attempted on a read-only client")
+
+// ResponseTooLargeError is returned when a response body exceeds the configured limit.
+// Response.Raw holds the (invalid, truncated) bytes read so far for inspection.
+type ResponseTooLargeError struct {
+	Limit    int64
+	Response *Response
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("response exceeded %d bytes; increase WithMaxResponseBytes or narrow the query", e.Limit)
+}
+
+func (e *ResponseTooLargeError) Is(target error) bool {
+	return target == ErrResponseTooLarge
+}
+
+// TransportError is returned when a request got no HTTP response at all (DNS,
+// TLS, connection reset, timeout). Err is the error from net/http.
+type TransportError struct {
+	// RequestURL is the URL without token, lgtoken and lgpassword. It never
+	// includes a POST body.
+	RequestURL string
+	Err        error
+}
+
+func (e *TransportError) Error() string { return e.Err.Error() }
+
+func (e *TransportError) Unwrap() error { return e.Err }
+
+// redactedSecrets are the query parameters removed from RequestURL fields.
+var redactedSecrets = []string{"token", "lgtoken", "lgpassword", "logintoken", "password"}
+
+func redactedURL(u *url.URL) string {
+	r := *u
+	q := r.Query()
+	for _, k := range redactedSecrets {
+		q.Del(k)
+	}
+	r.RawQuery = q.Encode()
+	r.User = nil
+	return r.String()
+}
+
+// HTTPError is returned when the server answers with something other than an
+// API response, typically an HTML error page from a proxy or load balancer.
+type HTTPError struct {
+	StatusCode  int
+	ContentType string
+	RequestURL  string
+	// Snippet is the beginning of the body with markup stripped.
+	Snippet  string
+	Response *Response
+}
+
+func (e *HTTPError) Error() string {
+	msg := fmt.Sprintf("http %d", e.StatusCode)
+	if text := http.StatusText(e.StatusCode); text != "" {
+		msg += " " + text
+	}
+	msg += fmt.Sprintf(": non-API response (%s)", e.ContentType)
+	if e.Snippet != "" {
+		msg += ": " + e.Snippet
+	}
+	return msg
+}
+
+const httpErrorSnippetLen = 200
+
+var (
+	reHTMLTag    = regexp.MustCompile(`(?s)<(script|style)[^>]*>.*?</(script|style)>|<[^>]*>`)
+	reWhitespace = regexp.MustCompile(`\s+`)
+)
+
+func newHTTPError(resp *Response, contentType string) *HTTPError {
+	text := reHTMLTag.ReplaceAllString(string(resp.Raw), " ")
+	text = strings.TrimSpace(reWhitespace.ReplaceAllString(html.UnescapeString(text), " "))
+	if r := []rune(text); len(r) > httpErrorSnippetLen {
+		text = string(r[:httpErrorSnippetLen]) + "..."
+	}
+	return &HTTPError{
+		StatusCode:  resp.StatusCode,
+		ContentType: contentType,
+		RequestURL:  resp.requestURL,
+		Snippet:     text,
+		Response:    resp,
+	}
+}
+
+func isJSONContentType(ct string) bool {
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return false
+	}
+	switch mt {
+	case "application/json", "text/json", "text/javascript", "application/javascript":
+		return true
+	}
+	return strings.HasSuffix(mt, "+json")
 }
 
 func IsMediaWikiApiError(err error) (*MediaWikiApiError, bool) {
@@ -41,6 +305,10 @@ func isTokenErrorCode(code string) bool {
 	}
 }
 
+func isAssertBotFailedCode(code string) bool {
+	return strings.EqualFold(code, "assertbotfailed")
+}
+
 func isAssertUserFailedCode(code string) bool {
 	switch strings.ToLower(code) {
 	case "assertuserfailed", "assertnameduserfailed":
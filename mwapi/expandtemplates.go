@@ -0,0 +1,98 @@
+package mwapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+type ExpandOptions struct {
+	IncludeComments bool
+	RevID           int64
+}
+
+type ExpandCategory struct {
+	Category string `json:"category"`
+	SortKey  string `json:"sortkey,omitempty"`
+}
+
+type ExpandResult struct {
+	Wikitext      string           `json:"wikitext,omitempty"`
+	ParseTree     string           `json:"parsetree,omitempty"`
+	Categories    []ExpandCategory `json:"categories,omitempty"`
+	Modules       []string         `json:"modules,omitempty"`
+	ModuleScripts []string         `json:"modulescripts,omitempty"`
+	ModuleStyles  []string         `json:"modulestyles,omitempty"`
+}
+
+// ExpandTemplates expands all templates in text as if it appeared on title
+// (action=expandtemplates). props defaults to wikitext only.
+func (c *Client) ExpandTemplates(ctx context.Context, title, text string, props []string) (*ExpandResult, error) {
+	return c.ExpandTemplatesWithOptions(ctx, title, text, props, ExpandOptions{})
+}
+
+func (c *Client) ExpandTemplatesWithOptions(ctx context.Context, title, text string, props []string, opts ExpandOptions) (*ExpandResult, error) {
+	if text == "" {
+		return nil, errors.New("expandtemplates: missing text")
+	}
+	if len(props) == 0 {
+		props = []string{"wikitext"}
+	}
+
+	p := map[string]any{
+		"action":          "expandtemplates",
+		"text":            text,
+		"prop":            props,
+		"includecomments": opts.IncludeComments,
+	}
+	if title != "" {
+		p["title"] = title
+	}
+	if opts.RevID > 0 {
+		p["revid"] = opts.RevID
+	}
+
+	// POST: the text can easily exceed URL length limits.
+	resp, err := c.Post(ctx, p)
+	if err != nil {
+		return nil, fmt.Errorf("expandtemplates: %w", err)
+	}
+	if err := apiError(resp); err != nil {
+		return nil, fmt.Errorf("expandtemplates: %w", err)
+	}
+
+	var out struct {
+		Expand *struct {
+			ExpandResult
+			Star *string `json:"*"` // formatversion=1 wikitext
+		} `json:"expandtemplates"`
+		// Deprecated generatexml places the tree at the top level.
+		ParseTree json.RawMessage `json:"parsetree"`
+	}
+	if err := resp.Into(&out); err != nil {
+		return nil, err
+	}
+	if out.Expand == nil {
+		return nil, errors.New("missing expandtemplates in response")
+	}
+
+	res := out.Expand.ExpandResult
+	if res.Wikitext == "" && out.Expand.Star != nil {
+		res.Wikitext = *out.Expand.Star
+	}
+	if res.ParseTree == "" && len(out.ParseTree) > 0 {
+		var tree string
+		if err := json.Unmarshal(out.ParseTree, &tree); err == nil {
+			res.ParseTree = tree
+		} else {
+			var v struct {
+				Star string `json:"*"`
+			}
+			if err := json.Unmarshal(out.ParseTree, &v); err == nil {
+				res.ParseTree = v.Star
+			}
+		}
+	}
+	return &res, nil
+}
@@ -0,0 +1,52 @@
+package mwapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+func TestExpandTemplates(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("expandtemplates", func(r *mwtest.Request) any {
+		if r.Param("text") == "{{Broken" {
+			return mwtest.ErrorResponse("invalidtitle", "Bad title \"Broken\".")
+		}
+		return map[string]any{"expandtemplates": map[string]any{
+			"wikitext":   "Hello, World!",
+			"categories": []any{map[string]any{"category": "Greetings", "sortkey": ""}},
+			"parsetree":  "<root><template><title>Hello</title></template></root>",
+		}}
+	})
+
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	res, err := c.ExpandTemplates(ctx, "Sandbox", "{{Hello|World}}", nil)
+	if err != nil {
+		t.Fatalf("ExpandTemplates: %v", err)
+	}
+	if res.Wikitext != "Hello, World!" || len(res.Categories) != 1 || res.Categories[0].Category != "Greetings" {
+		t.Fatalf("result = %+v", res)
+	}
+	wiki.AssertSent("expandtemplates", "prop", "wikitext")
+	wiki.AssertSent("expandtemplates", "title", "Sandbox")
+	wiki.AssertNotSent("expandtemplates", "includecomments")
+
+	if _, err := c.ExpandTemplatesWithOptions(ctx, "", "{{Hello}}", []string{"wikitext", "parsetree"},
+		ExpandOptions{IncludeComments: true}); err != nil {
+		t.Fatalf("ExpandTemplatesWithOptions: %v", err)
+	}
+	wiki.AssertSent("expandtemplates", "prop", "wikitext|parsetree")
+	wiki.AssertSent("expandtemplates", "includecomments", "1")
+
+	_, err = c.ExpandTemplates(ctx, "", "{{Broken", nil)
+	if e, ok := IsMediaWikiApiError(err); !ok || e.Code != "invalidtitle" {
+		t.Fatalf("err = %v, want invalidtitle", err)
+	}
+}
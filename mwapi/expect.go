@@ -0,0 +1,66 @@
+package mwapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ResultError reports a module whose nested result field (edit.result,
+// login.result, ...) is not "Success" although the request itself succeeded.
+type ResultError struct {
+	Module string
+	Result string
+	Reason string
+}
+
+func (e *ResultError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("%s failed: %s (%s)", e.Module, e.Result, e.Reason)
+	}
+	return fmt.Sprintf("%s failed: %s", e.Module, e.Result)
+}
+
+// Expect returns the envelope error of r, if any, and otherwise whatever
+// predicate returns, e.g. resp.Expect(mwapi.EditSucceeded).
+func (r *Response) Expect(predicate func(*Response) error) error {
+	if err := apiError(r); err != nil {
+		return err
+	}
+	if r == nil {
+		return fmt.Errorf("nil response")
+	}
+	return predicate(r)
+}
+
+// EditSucceeded expects edit.result to be "Success".
+func EditSucceeded(r *Response) error { return moduleSucceeded(r, "edit") }
+
+// LoginSucceeded expects login.result to be "Success".
+func LoginSucceeded(r *Response) error { return moduleSucceeded(r, "login") }
+
+// UploadSucceeded expects upload.result to be "Success"; "Warning" is a failure.
+func UploadSucceeded(r *Response) error { return moduleSucceeded(r, "upload") }
+
+func moduleSucceeded(r *Response, module string) error {
+	var out map[string]json.RawMessage
+	if err := json.Unmarshal(r.Raw, &out); err != nil {
+		return err
+	}
+	var m struct {
+		Result string `json:"result"`
+		Reason string `json:"reason"`
+	}
+	if raw, ok := out[module]; ok {
+		if err := json.Unmarshal(raw, &m); err != nil {
+			return err
+		}
+	}
+	if m.Result == "" {
+		return fmt.Errorf("missing %s.result in response", module)
+	}
+	if !strings.EqualFold(m.Result, "success") {
+		return &ResultError{Module: module, Result: m.Result, Reason: m.Reason}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package mwapi
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestResponse_Expect(t *testing.T) {
+	t.Parallel()
+
+	ok := &Response{Raw: json.RawMessage(`{"edit":{"result":"Success","newrevid":2},"batchcomplete":true}`)}
+	if err := ok.Expect(EditSucceeded); err != nil {
+		t.Fatalf("Expect(EditSucceeded) = %v, want nil", err)
+	}
+
+	failed := &Response{Raw: json.RawMessage(`{"login":{"result":"Failed","reason":"Incorrect password"}}`)}
+	err := failed.Expect(LoginSucceeded)
+	var re *ResultError
+	if !errors.As(err, &re) || re.Module != "login" || re.Result != "Failed" || re.Reason != "Incorrect password" {
+		t.Fatalf("Expect(LoginSucceeded) = %v, want login ResultError", err)
+	}
+
+	warned := &Response{Raw: json.RawMessage(`{"upload":{"result":"Warning","warnings":{"exists":"A.png"}}}`)}
+	if err := warned.Expect(UploadSucceeded); !errors.As(err, &re) || re.Result != "Warning" {
+		t.Fatalf("Expect(UploadSucceeded) = %v, want Warning ResultError", err)
+	}
+
+	if err := ok.Expect(UploadSucceeded); err == nil {
+		t.Fatalf("Expect on a response without upload.result should fail")
+	}
+}
@@ -0,0 +1,70 @@
+package mwapi
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+const importDump = `<mediawiki xmlns="http://www.mediawiki.org/xml/export-0.11/">
+  <page><title>Imported</title><ns>0</ns>
+    <revision><timestamp>2024-01-31T12:00:00Z</timestamp><text>Hello</text></revision>
+  </page>
+</mediawiki>`
+
+func TestImport_XMLMultipart(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("import", func(r *mwtest.Request) any {
+		f, ok := r.Files["xml"]
+		if !ok {
+			return mwtest.ErrorResponse("import-unknownerror", "Unknown error on import: no file.")
+		}
+		if r.User == "" {
+			return mwtest.ErrorResponse("cantimport-upload", "You don't have permission to import uploaded pages.")
+		}
+		if !strings.Contains(string(f.Data), "<title>Imported</title>") {
+			return mwtest.ErrorResponse("import-unknownerror", "Unknown error on import: bad dump.")
+		}
+		return map[string]any{"import": []any{
+			map[string]any{"ns": 0, "title": "Imported", "revisions": 1},
+		}}
+	})
+
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	params := ImportParams{XML: strings.NewReader(importDump), InterwikiPrefix: "en", Summary: "Migration"}
+	if _, err := c.Import(ctx, params); !errors.Is(err, ErrCantImport) {
+		t.Fatalf("anonymous err = %v, want ErrCantImport", err)
+	}
+
+	if _, err := c.Login(ctx, "Importer", "pass"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	params.XML = strings.NewReader(importDump)
+	pages, err := c.Import(ctx, params)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if len(pages) != 1 || pages[0].Title != "Imported" || pages[0].Revisions != 1 {
+		t.Fatalf("pages = %+v", pages)
+	}
+	last := wiki.LastRequest("import")
+	if got := last.Files["xml"].Filename; got != "import.xml" {
+		t.Fatalf("xml filename = %q", got)
+	}
+	wiki.AssertSent("import", "interwikiprefix", "en")
+	wiki.AssertSent("import", "summary", "Migration")
+	wiki.AssertNotSent("import", "fullhistory")
+
+	if _, err := c.Import(ctx, ImportParams{InterwikiSource: "en"}); err == nil {
+		t.Fatalf("interwiki import without page was accepted")
+	}
+}
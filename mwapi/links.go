@@ -0,0 +1,186 @@
+package mwapi
+
+import (
+	"context"
+	"encoding/json"
+	"iter"
+)
+
+type PageRef struct {
+	PageID   int64  `json:"pageid,omitempty"`
+	NS       int    `json:"ns"`
+	Title    string `json:"title"`
+	Redirect bool   `json:"redirect,omitempty"`
+	// Via is the redirect the page links through, for Backlinks with
+	// FollowRedirects; "" for a direct link.
+	Via string `json:"-"`
+}
+
+type ExtLink struct {
+	URL string `json:"url"`
+}
+
+func (l *ExtLink) UnmarshalJSON(b []byte) error {
+	var v struct {
+		URL  string `json:"url"`
+		Star string `json:"*"` // formatversion=1
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	l.URL = firstNonEmpty(v.URL, v.Star)
+	return nil
+}
+
+type RedirectFilter string
+
+const (
+	RedirectsAll    RedirectFilter = "all"
+	RedirectsOnly   RedirectFilter = "redirects"
+	RedirectsExcept RedirectFilter = "nonredirects"
+)
+
+type LinksOptions struct {
+	Namespaces []int
+}
+
+type BacklinksOptions struct {
+	Namespaces []int
+	Filter     RedirectFilter
+	// FollowRedirects also lists pages linking through a redirect to the
+	// title, right after that redirect, with PageRef.Via set.
+	FollowRedirects bool
+}
+
+// Links iterates the outgoing wiki links of title (prop=links).
+func (c *Client) Links(ctx context.Context, title string, opts LinksOptions, qopts ...QueryOption) iter.Seq2[PageRef, error] {
+	return queryItems(ctx, c, map[string]any{
+		"action":      "query",
+		"prop":        "links",
+		"titles":      title,
+		"plnamespace": opts.Namespaces,
+		"pllimit":     "max",
+	}, pagePropItems[PageRef]("links"), iterOptions(qopts, "pllimit"))
+}
+
+// LinksHere iterates pages linking to title (prop=linkshere).
+func (c *Client) LinksHere(ctx context.Context, title string, opts BacklinksOptions, qopts ...QueryOption) iter.Seq2[PageRef, error] {
+	p := map[string]any{
+		"action":      "query",
+		"prop":        "linkshere",
+		"titles":      title,
+		"lhprop":      []string{"pageid", "title", "redirect"},
+		"lhnamespace": opts.Namespaces,
+		"lhlimit":     "max",
+	}
+	switch opts.Filter {
+	case RedirectsOnly:
+		p["lhshow"] = "redirect"
+	case RedirectsExcept:
+		p["lhshow"] = "!redirect"
+	}
+	return queryItems(ctx, c, p, pagePropItems[PageRef]("linkshere"), iterOptions(qopts, "lhlimit"))
+}
+
+// Backlinks iterates pages linking to title (list=backlinks).
+func (c *Client) Backlinks(ctx context.Context, title string, opts BacklinksOptions, qopts ...QueryOption) iter.Seq2[PageRef, error] {
+	p := map[string]any{
+		"action":      "query",
+		"list":        "backlinks",
+		"bltitle":     title,
+		"blnamespace": opts.Namespaces,
+		"blredirect":  opts.FollowRedirects,
+		"bllimit":     "max",
+	}
+	if opts.Filter != "" {
+		p["blfilterredir"] = string(opts.Filter)
+	}
+	return func(yield func(PageRef, error) bool) {
+		for ref, err := range queryItems(ctx, c, p, backlinkItems(), iterOptions(qopts, "bllimit")) {
+			if !yield(ref, err) {
+				return
+			}
+		}
+	}
+}
+
+// backlinkItems flattens list=backlinks entries with their redirlinks. A
+// redirect whose links continue on the next page is repeated there; it is
+// yielded only once.
+func backlinkItems() func(*Response) ([]PageRef, error) {
+	seen := map[string]bool{}
+	extract := listItems[struct {
+		PageRef
+		RedirLinks []PageRef `json:"redirlinks"`
+	}]("backlinks")
+	return func(resp *Response) ([]PageRef, error) {
+		entries, err := extract(resp)
+		if err != nil {
+			return nil, err
+		}
+		var out []PageRef
+		for _, e := range entries {
+			if len(e.RedirLinks) == 0 || !seen[e.Title] {
+				out = append(out, e.PageRef)
+			}
+			if len(e.RedirLinks) > 0 {
+				seen[e.Title] = true
+			}
+			for _, l := range e.RedirLinks {
+				l.Via = e.Title
+				out = append(out, l)
+			}
+		}
+		return out, nil
+	}
+}
+
+type TransclusionOptions struct {
+	Namespaces []int
+	Filter     RedirectFilter
+}
+
+// TranscludedIn iterates pages transcluding title, e.g. the users of a
+// template (prop=transcludedin).
+func (c *Client) TranscludedIn(ctx context.Context, title string, opts TransclusionOptions, qopts ...QueryOption) iter.Seq2[PageRef, error] {
+	p := map[string]any{
+		"action":      "query",
+		"prop":        "transcludedin",
+		"titles":      title,
+		"tiprop":      []string{"pageid", "title", "redirect"},
+		"tinamespace": opts.Namespaces,
+		"tilimit":     "max",
+	}
+	switch opts.Filter {
+	case RedirectsOnly:
+		p["tishow"] = "redirect"
+	case RedirectsExcept:
+		p["tishow"] = "!redirect"
+	}
+	return queryItems(ctx, c, p, pagePropItems[PageRef]("transcludedin"), iterOptions(qopts, "tilimit"))
+}
+
+// EmbeddedIn iterates pages transcluding title (list=embeddedin).
+func (c *Client) EmbeddedIn(ctx context.Context, title string, opts TransclusionOptions, qopts ...QueryOption) iter.Seq2[PageRef, error] {
+	p := map[string]any{
+		"action":      "query",
+		"list":        "embeddedin",
+		"eititle":     title,
+		"einamespace": opts.Namespaces,
+		"eilimit":     "max",
+	}
+	if opts.Filter != "" {
+		p["eifilterredir"] = string(opts.Filter)
+	}
+	return queryItems(ctx, c, p, listItems[PageRef]("embeddedin"), iterOptions(qopts, "eilimit"))
+}
+
+// ExtLinks iterates the external links of title (prop=extlinks).
+func (c *Client) ExtLinks(ctx context.Context, title string, opts ...QueryOption) iter.Seq2[ExtLink, error] {
+	return queryItems(ctx, c, map[string]any{
+		"action":  "query",
+		"prop":    "extlinks",
+		"titles":  title,
+		"ellimit": "max",
+	}, pagePropItems[ExtLink]("extlinks"), iterOptions(opts, "ellimit"))
+}
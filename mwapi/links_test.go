@@ -0,0 +1,263 @@
+package mwapi
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+func TestBacklinks_FollowsContinuation(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("query", func(r *mwtest.Request) any {
+		if r.Param("list") != "backlinks" || r.Param("bltitle") != "Template:Foo" {
+			return mwtest.ErrorResponse("badtest", "unexpected query")
+		}
+		pages := map[string]struct {
+			items []any
+			next  string
+		}{
+			"":    {[]any{map[string]any{"pageid": 1, "ns": 0, "title": "A"}, map[string]any{"pageid": 2, "ns": 0, "title": "B"}}, "0|2"},
+			"0|2": {[]any{map[string]any{"pageid": 3, "ns": 0, "title": "C", "redirect": true}}, "0|3"},
+			"0|3": {[]any{map[string]any{"pageid": 4, "ns": 0, "title": "D"}}, ""},
+		}
+		pg, ok := pages[r.Param("blcontinue")]
+		if !ok {
+			return mwtest.ErrorResponse("badcontinue", "bad continue")
+		}
+		out := map[string]any{"query": map[string]any{"backlinks": pg.items}}
+		if pg.next != "" {
+			out["continue"] = map[string]any{"blcontinue": pg.next, "continue": "-||"}
+		}
+		return out
+	})
+
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	var titles []string
+	for ref, err := range c.Backlinks(ctx, "Template:Foo", BacklinksOptions{
+		Namespaces: []int{0},
+		Filter:     RedirectsAll,
+	}) {
+		if err != nil {
+			t.Fatalf("Backlinks: %v", err)
+		}
+		titles = append(titles, ref.Title)
+		if ref.Title == "C" && !ref.Redirect {
+			t.Fatalf("C should be flagged as redirect")
+		}
+	}
+	if got := len(titles); got != 4 || titles[0] != "A" || titles[3] != "D" {
+		t.Fatalf("titles = %v", titles)
+	}
+
+	reqs := wiki.RequestsFor("query")
+	if len(reqs) != 3 {
+		t.Fatalf("query requests = %d, want 3", len(reqs))
+	}
+	for _, r := range reqs {
+		if r.Param("blnamespace") != "0" || r.Param("blfilterredir") != "all" || r.Param("bllimit") != "max" {
+			t.Fatalf("filters not preserved across pages: %v", r.Form)
+		}
+	}
+}
+
+func TestBacklinks_StopEarly(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("query", func(r *mwtest.Request) any {
+		return map[string]any{
+			"query":    map[string]any{"backlinks": []any{map[string]any{"ns": 0, "title": "A"}, map[string]any{"ns": 0, "title": "B"}}},
+			"continue": map[string]any{"blcontinue": "next", "continue": "-||"},
+		}
+	})
+
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	for _, err := range c.Backlinks(ctx, "X", BacklinksOptions{}) {
+		if err != nil {
+			t.Fatalf("Backlinks: %v", err)
+		}
+		break
+	}
+	if got := len(wiki.RequestsFor("query")); got != 1 {
+		t.Fatalf("query requests = %d, want 1", got)
+	}
+}
+
+func TestExtLinks_DecodesBothFormats(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("query", func(r *mwtest.Request) any {
+		return map[string]any{"query": map[string]any{"pages": []any{map[string]any{
+			"title":    "A",
+			"extlinks": []any{map[string]any{"url": "https://a.example"}, map[string]any{"*": "https://b.example"}},
+		}}}}
+	})
+
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	var urls []string
+	for l, err := range c.ExtLinks(ctx, "A") {
+		if err != nil {
+			t.Fatalf("ExtLinks: %v", err)
+		}
+		urls = append(urls, l.URL)
+	}
+	if len(urls) != 2 || urls[1] != "https://b.example" {
+		t.Fatalf("urls = %v", urls)
+	}
+}
+
+// transclusionPages serves total refs in batches of 500, continued by key.
+func transclusionPages(r *mwtest.Request, key string, total int) (items []any, next string) {
+	start, _ := strconv.Atoi(r.Param(key))
+	end := min(start+500, total)
+	for i := start; i < end; i++ {
+		items = append(items, map[string]any{"pageid": i + 1, "ns": 0, "title": fmt.Sprintf("Page %d", i)})
+	}
+	if end < total {
+		next = strconv.Itoa(end)
+	}
+	return items, next
+}
+
+func TestEmbeddedIn_LargeListContinues(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("query", func(r *mwtest.Request) any {
+		if r.Param("list") != "embeddedin" || r.Param("eititle") != "Template:Infobox" {
+			return mwtest.ErrorResponse("badtest", "unexpected query")
+		}
+		items, next := transclusionPages(r, "eicontinue", 1234)
+		out := map[string]any{"query": map[string]any{"embeddedin": items}}
+		if next != "" {
+			out["continue"] = map[string]any{"eicontinue": next, "continue": "-||"}
+		}
+		return out
+	})
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	n := 0
+	for ref, err := range c.EmbeddedIn(ctx, "Template:Infobox", TransclusionOptions{Namespaces: []int{0, 2}, Filter: RedirectsExcept}) {
+		if err != nil {
+			t.Fatalf("EmbeddedIn: %v", err)
+		}
+		if want := fmt.Sprintf("Page %d", n); ref.Title != want {
+			t.Fatalf("item %d = %q, want %q", n, ref.Title, want)
+		}
+		n++
+	}
+	if n != 1234 {
+		t.Fatalf("items = %d, want 1234", n)
+	}
+	reqs := wiki.RequestsFor("query")
+	if len(reqs) != 3 {
+		t.Fatalf("query requests = %d, want 3", len(reqs))
+	}
+	for _, r := range reqs {
+		if r.Param("einamespace") != "0|2" || r.Param("eifilterredir") != "nonredirects" {
+			t.Fatalf("filters not preserved across pages: %v", r.Form)
+		}
+	}
+}
+
+func TestTranscludedIn_LargeListContinues(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("query", func(r *mwtest.Request) any {
+		if r.Param("prop") != "transcludedin" || r.Param("titles") != "Template:Infobox" {
+			return mwtest.ErrorResponse("badtest", "unexpected query")
+		}
+		items, next := transclusionPages(r, "ticontinue", 700)
+		out := map[string]any{"query": map[string]any{"pages": []any{map[string]any{
+			"pageid": 99, "ns": 10, "title": "Template:Infobox", "transcludedin": items,
+		}}}}
+		if next != "" {
+			out["continue"] = map[string]any{"ticontinue": next, "continue": "||"}
+		}
+		return out
+	})
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	n := 0
+	for _, err := range c.TranscludedIn(ctx, "Template:Infobox", TransclusionOptions{Filter: RedirectsOnly}) {
+		if err != nil {
+			t.Fatalf("TranscludedIn: %v", err)
+		}
+		n++
+	}
+	if n != 700 {
+		t.Fatalf("items = %d, want 700", n)
+	}
+	if got := len(wiki.RequestsFor("query")); got != 2 {
+		t.Fatalf("query requests = %d, want 2", got)
+	}
+	wiki.AssertSent("query", "tishow", "redirect")
+	wiki.AssertSent("query", "tiprop", "pageid|title|redirect")
+}
+
+func TestBacklinks_FollowRedirects(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("query", func(r *mwtest.Request) any {
+		if r.Param("blredirect") != "1" {
+			return mwtest.ErrorResponse("badtest", "blredirect not sent")
+		}
+		// The redirect R's links are split across the continuation.
+		if r.Param("blcontinue") == "" {
+			return map[string]any{
+				"continue": map[string]any{"blcontinue": "0|R|11", "continue": "-||"},
+				"query": map[string]any{"backlinks": []any{
+					map[string]any{"pageid": 1, "ns": 0, "title": "A"},
+					map[string]any{"pageid": 2, "ns": 0, "title": "R", "redirect": true, "redirlinks": []any{
+						map[string]any{"pageid": 10, "ns": 0, "title": "X"},
+					}},
+				}},
+			}
+		}
+		return map[string]any{"query": map[string]any{"backlinks": []any{
+			map[string]any{"pageid": 2, "ns": 0, "title": "R", "redirect": true, "redirlinks": []any{
+				map[string]any{"pageid": 11, "ns": 0, "title": "Y"},
+			}},
+			map[string]any{"pageid": 3, "ns": 0, "title": "B"},
+		}}}
+	})
+
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	var got []string
+	for ref, err := range c.Backlinks(ctx, "Target", BacklinksOptions{FollowRedirects: true}) {
+		if err != nil {
+			t.Fatalf("Backlinks: %v", err)
+		}
+		got = append(got, ref.Title+"<"+ref.Via)
+	}
+	want := []string{"A<", "R<", "X<R", "Y<R", "B<"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("backlinks = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,99 @@
+package mwapi
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// defaultMaxLagWait is used when a maxlag error carries no usable Retry-After.
+const defaultMaxLagWait = 5 * time.Second
+
+// WithMaxLag sends maxlag=seconds with every request (unless the request sets
+// it), so the wiki refuses work while its replicas lag further behind. Refused
+// requests are retried as configured by WithMaxLagRetry.
+func WithMaxLag(seconds int) Option {
+	return func(c *Client) {
+		if seconds >= 0 {
+			c.maxLag = seconds
+		}
+	}
+}
+
+// WithMaxLagRetry sets how often a request refused with a maxlag error is
+// retried (default 3) and the longest wait between tries (default 30s); the
+// wait is the server's Retry-After. Zero retries surfaces maxlag at once, as
+// do requests whose body cannot be sent twice, such as file uploads.
+// Without WithMaxLag, maxlag errors are returned as they come. With a
+// Scheduler the wait is left to it: it sees the maxlag reply in After and
+// holds the retry in Before, so the client does not pause a second time.
+func WithMaxLagRetry(retries int, maxWait time.Duration) Option {
+	return func(c *Client) {
+		if retries >= 0 {
+			c.maxLagRetry = retries
+		}
+		if maxWait > 0 {
+			c.maxLagWait = maxWait
+		}
+	}
+}
+
+// doOnceMaxLag is doOnce, retried while the server answers with maxlag.
+// Once retries run out, or the body cannot be sent again (file uploads,
+// streams that cannot rewind), the maxlag error is returned even if throw is
+// false.
+func (c *Client) doOnceMaxLag(ctx context.Context, method string, np normalizedParams, throw bool) (*Response, error) {
+	if c.maxLag <= 0 {
+		return c.doOnceRetry(ctx, method, np, throw)
+	}
+	for attempt := 0; ; attempt++ {
+		resp, err := c.doOnceRetry(ctx, method, np, throw)
+		if !isMaxLag(resp, err) {
+			return resp, err
+		}
+		if attempt >= c.maxLagRetry || !np.replayable() {
+			if err == nil {
+				err = responseApiError(resp)
+			}
+			return resp, err
+		}
+		if c.scheduler != nil {
+			continue
+		}
+		if err := sleepCtx(ctx, c.maxLagDelay(resp)); err != nil {
+			return resp, err
+		}
+	}
+}
+
+func isMaxLag(resp *Response, err error) bool {
+	if e, ok := IsMediaWikiApiError(err); ok {
+		return strings.EqualFold(e.Code, "maxlag")
+	}
+	return err == nil && strings.EqualFold(responseErrorCode(resp), "maxlag")
+}
+
+func (c *Client) maxLagDelay(resp *Response) time.Duration {
+	d, ok := retryAfter(resp.Header)
+	if !ok {
+		d = defaultMaxLagWait
+	}
+	return min(d, c.maxLagWait)
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		if ctx.Err() != nil {
+			return canceled(ctx, nil)
+		}
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return canceled(ctx, nil)
+	}
+}
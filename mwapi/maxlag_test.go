@@ -0,0 +1,128 @@
+package mwapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func maxLagServer(t *testing.T, failures int32) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("maxlag") != "5" {
+			t.Errorf("maxlag = %q, want 5", r.URL.Query().Get("maxlag"))
+		}
+		if calls.Add(1) <= failures {
+			w.Header().Set("Retry-After", "5")
+			_ = json.NewEncoder(w).Encode(map[string]any{"errors": []any{map[string]any{
+				"code": "maxlag", "text": "Waiting for a database server: 7 seconds lagged.",
+			}}})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"batchcomplete": true})
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func TestMaxLag_RetriesAfterBackoff(t *testing.T) {
+	t.Parallel()
+
+	srv, calls := maxLagServer(t, 2)
+	const wait = 30 * time.Millisecond
+	c := New(srv.URL+"/api.php", WithMaxLag(5), WithMaxLagRetry(3, wait))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	start := time.Now()
+	resp, err := c.Get(ctx, map[string]any{"meta": "siteinfo"})
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if responseErrorCode(resp) != "" || calls.Load() != 3 {
+		t.Fatalf("calls = %d, code = %q; want success on the third try", calls.Load(), responseErrorCode(resp))
+	}
+	// Retry-After says 5s; the wait is capped at maxWait per retry.
+	if d := time.Since(start); d < 2*wait || d > time.Second {
+		t.Fatalf("took %v, want about %v", d, 2*wait)
+	}
+}
+
+func TestMaxLag_SurfacesErrorWhenExhausted(t *testing.T) {
+	t.Parallel()
+
+	srv, calls := maxLagServer(t, 100)
+	c := New(srv.URL+"/api.php", WithMaxLag(5), WithMaxLagRetry(1, time.Millisecond))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	_, err := c.Get(ctx, map[string]any{"meta": "siteinfo"})
+	if e, ok := IsMediaWikiApiError(err); !ok || e.Code != "maxlag" {
+		t.Fatalf("err = %v, want maxlag", err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("calls = %d, want 2", calls.Load())
+	}
+
+	// The sleep honors the context.
+	c = New(srv.URL+"/api.php", WithMaxLag(5), WithMaxLagRetry(3, time.Minute))
+	short, cancelShort := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancelShort()
+	if _, err := c.Get(short, map[string]any{"meta": "siteinfo"}); err == nil {
+		t.Fatalf("Get succeeded, want the context to end the wait")
+	}
+}
+
+func TestMaxLag_DoesNotResendUploads(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		_ = r.ParseMultipartForm(1 << 20)
+		w.Header().Set("Retry-After", "1")
+		_ = json.NewEncoder(w).Encode(map[string]any{"errors": []any{map[string]any{
+			"code": "maxlag", "text": "Waiting for a database server: 7 seconds lagged.",
+		}}})
+	}))
+	t.Cleanup(srv.Close)
+
+	c := New(srv.URL+"/api.php", WithMaxLag(5), WithMaxLagRetry(3, time.Millisecond))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	_, err := c.Post(ctx, map[string]any{
+		"action": "upload",
+		"file":   File{Filename: "a.txt", Reader: onlyReader{strings.NewReader("content")}},
+	})
+	if e, ok := IsMediaWikiApiError(err); !ok || e.Code != "maxlag" {
+		t.Fatalf("err = %v, want maxlag", err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("calls = %d, want 1 (a drained upload must not be resent)", n)
+	}
+}
+
+func TestMaxLag_SchedulerOwnsTheWait(t *testing.T) {
+	t.Parallel()
+
+	srv, calls := maxLagServer(t, 1)
+	rec := &recordingScheduler{}
+	c := New(srv.URL+"/api.php", WithMaxLag(5), WithMaxLagRetry(3, time.Minute), WithScheduler(rec))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	// Retry-After says 5s; the recording scheduler never waits, so neither
+	// does the retry.
+	if _, err := c.Get(ctx, map[string]any{"meta": "siteinfo"}); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if calls.Load() != 2 || rec.after != 2 {
+		t.Fatalf("calls = %d, after = %d; want 2 and 2", calls.Load(), rec.after)
+	}
+}
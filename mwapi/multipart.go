@@ -0,0 +1,152 @@
+package mwapi
+
+import (
+	"io"
+	"mime/multipart"
+	"net/url"
+	"sort"
+)
+
+// multipartBody encodes params with files (and streams) as
+// multipart/form-data. It is written through a pipe as the request is sent,
+// so uploads are never held in memory.
+type multipartBody struct {
+	values   url.Values
+	parts    []multipartPart
+	boundary string
+}
+
+type multipartPart struct {
+	field    string
+	filename string // "" for a plain form field
+	r        io.Reader
+}
+
+func newMultipartBody(np normalizedParams) (*multipartBody, error) {
+	b := &multipartBody{
+		values:   np.Values,
+		boundary: multipart.NewWriter(io.Discard).Boundary(),
+	}
+	for _, f := range np.Files {
+		filename := f.File.Filename
+		if filename == "" {
+			filename = f.Field
+		}
+		b.parts = append(b.parts, multipartPart{field: f.Field, filename: filename, r: f.File.Reader})
+	}
+	for _, sf := range np.Streams {
+		r, err := sf.Stream.open()
+		if err != nil {
+			return nil, err
+		}
+		b.parts = append(b.parts, multipartPart{field: sf.Field, r: r})
+	}
+	return b, nil
+}
+
+func (b *multipartBody) contentType() string {
+	return "multipart/form-data; boundary=" + b.boundary
+}
+
+// size returns the encoded length, or -1 when a part's reader does not
+// tell its size and the body must be sent chunked.
+func (b *multipartBody) size() int64 {
+	var total int64
+	for _, p := range b.parts {
+		n, ok := readerSize(p.r)
+		if !ok {
+			return -1
+		}
+		total += n
+	}
+	var cw countingWriter
+	if err := b.write(&cw, false); err != nil {
+		return -1
+	}
+	return total + cw.n
+}
+
+// pipe starts writing the body and returns its reading end. A failed write
+// (e.g. a read error from a file) closes the pipe with that error, which
+// fails the request.
+func (b *multipartBody) pipe() io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(b.write(pw, true))
+	}()
+	return pr
+}
+
+// write encodes the body to dst; without contents the part bodies are left
+// out, to measure the framing.
+func (b *multipartBody) write(dst io.Writer, contents bool) error {
+	w := multipart.NewWriter(dst)
+	if err := w.SetBoundary(b.boundary); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(b.values))
+	for k, vs := range b.values {
+		if k != "token" && len(vs) > 0 {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	// The token goes last, as in streamingFormBody.
+	if len(b.values["token"]) > 0 {
+		keys = append(keys, "token")
+	}
+	for _, k := range keys {
+		if err := w.WriteField(k, b.values[k][0]); err != nil {
+			return err
+		}
+	}
+	for _, p := range b.parts {
+		var pw io.Writer
+		var err error
+		if p.filename != "" {
+			pw, err = w.CreateFormFile(p.field, p.filename)
+		} else {
+			pw, err = w.CreateFormField(p.field)
+		}
+		if err != nil {
+			return err
+		}
+		if !contents {
+			continue
+		}
+		if _, err := io.Copy(pw, p.r); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// readerSize reports how many bytes r has left, if it can tell without
+// reading.
+func readerSize(r io.Reader) (int64, bool) {
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		return int64(v.Len()), true
+	case io.Seeker:
+		cur, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, false
+		}
+		end, err := v.Seek(0, io.SeekEnd)
+		if err != nil {
+			return 0, false
+		}
+		if _, err := v.Seek(cur, io.SeekStart); err != nil {
+			return 0, false
+		}
+		return end - cur, true
+	}
+	return 0, false
+}
+
+type countingWriter struct{ n int64 }
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
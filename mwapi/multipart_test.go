@@ -0,0 +1,104 @@
+package mwapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// onlyReader hides Len and Seek, so the body size is unknown.
+type onlyReader struct{ r io.Reader }
+
+func (o onlyReader) Read(p []byte) (int, error) { return o.r.Read(p) }
+
+type failingReader struct{ err error }
+
+func (f failingReader) Read([]byte) (int, error) { return 0, f.err }
+
+func TestMultipart_StreamsBody(t *testing.T) {
+	t.Parallel()
+
+	type seen struct {
+		contentLength int64
+		chunked       bool
+		file, comment string
+	}
+	var mu sync.Mutex
+	var got []seen
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := seen{contentLength: r.ContentLength, chunked: len(r.TransferEncoding) > 0}
+		if err := r.ParseMultipartForm(1 << 20); err == nil {
+			s.comment = r.FormValue("comment")
+			if f, _, err := r.FormFile("file"); err == nil {
+				b, _ := io.ReadAll(f)
+				s.file = string(b)
+			}
+		}
+		mu.Lock()
+		got = append(got, s)
+		mu.Unlock()
+		_ = json.NewEncoder(w).Encode(map[string]any{"upload": map[string]any{"result": "Success"}})
+	}))
+	t.Cleanup(srv.Close)
+
+	c := New(srv.URL + "/api.php")
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	content := strings.Repeat("0123456789", 10000)
+	for _, r := range []io.Reader{strings.NewReader(content), onlyReader{strings.NewReader(content)}} {
+		_, err := c.Post(ctx, map[string]any{
+			"action":  "upload",
+			"comment": "hi",
+			"file":    File{Filename: "a.txt", Reader: r},
+		})
+		if err != nil {
+			t.Fatalf("Post: %v", err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 2 {
+		t.Fatalf("requests = %d, want 2", len(got))
+	}
+	sized, chunked := got[0], got[1]
+	if sized.contentLength <= int64(len(content)) || sized.chunked || sized.file != content || sized.comment != "hi" {
+		t.Fatalf("sized request = len %d chunked %v file %d bytes comment %q",
+			sized.contentLength, sized.chunked, len(sized.file), sized.comment)
+	}
+	if chunked.contentLength != -1 || !chunked.chunked || chunked.file != content {
+		t.Fatalf("unsized request = len %d chunked %v file %d bytes",
+			chunked.contentLength, chunked.chunked, len(chunked.file))
+	}
+}
+
+func TestMultipart_ReadErrorFailsRequest(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		_ = json.NewEncoder(w).Encode(map[string]any{"upload": map[string]any{"result": "Success"}})
+	}))
+	t.Cleanup(srv.Close)
+
+	c := New(srv.URL + "/api.php")
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	boom := errors.New("disk on fire")
+	_, err := c.Post(ctx, map[string]any{
+		"action": "upload",
+		"file":   File{Filename: "a.txt", Reader: failingReader{boom}},
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("err = %v, want the reader's error", err)
+	}
+}
@@ -0,0 +1,436 @@
+// Package mwtest provides a fake MediaWiki Action API server for tests.
+//
+// A FakeWiki handles the session plumbing every client test needs (login tokens,
+// action=login, CSRF tokens, logout, meta=userinfo, assertuser checks) and lets the
+// test register handlers for the actions it actually cares about:
+//
+//	wiki := mwtest.NewFakeWiki(t)
+//	wiki.AddUser("UserA", "pass")
+//	wiki.Handle("edit", func(r *mwtest.Request) any {
+//		return map[string]any{"edit": map[string]any{"result": "Success"}}
+//	})
+//	c := mwapi.New(wiki.URL())
+package mwtest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const sessionCookie = "fakewiki_session"
+
+// Request is a recorded API request as seen by the fake server.
+type Request struct {
+	Method string
+	Header http.Header
+	Form   url.Values
+	Files  map[string]UploadedFile
+
+	// User is the session user the request was made as ("" for anonymous).
+	User string
+}
+
+type UploadedFile struct {
+	Filename string
+	Data     []byte
+}
+
+func (r *Request) Param(key string) string {
+	return r.Form.Get(key)
+}
+
+func (r *Request) Action() string {
+	return r.Form.Get("action")
+}
+
+// Handler produces the response for a registered action. Returning a Reply
+// controls the status code and headers; any other value is encoded as the JSON body.
+type Handler func(r *Request) any
+
+type Reply struct {
+	Status int
+	Header http.Header
+	Body   any
+}
+
+type FakeWiki struct {
+	Server *httptest.Server
+
+	t testing.TB
+
+	mu       sync.Mutex
+	handlers map[string]Handler
+	users    map[string]string
+	sessions map[string]string // session id -> user
+	tokens   map[string]map[string]string
+	requests []*Request
+	seq      int
+}
+
+func NewFakeWiki(t testing.TB) *FakeWiki {
+	t.Helper()
+
+	f := &FakeWiki{
+		t:        t,
+		handlers: map[string]Handler{},
+		users:    map[string]string{},
+		sessions: map[string]string{},
+		tokens:   map[string]map[string]string{},
+	}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	t.Cleanup(f.Server.Close)
+	return f
+}
+
+// URL returns the api.php endpoint of the fake wiki.
+func (f *FakeWiki) URL() string {
+	return f.Server.URL + "/api.php"
+}
+
+// AddUser registers credentials accepted by action=login. If no users are
+// registered, any username/password pair is accepted.
+func (f *FakeWiki) AddUser(name, pass string) {
+	f.mu.Lock()
+	f.users[name] = pass
+	f.mu.Unlock()
+}
+
+func (f *FakeWiki) Handle(action string, h Handler) {
+	f.mu.Lock()
+	f.handlers[action] = h
+	f.mu.Unlock()
+}
+
+// ExpireSessions drops all server-side sessions, so the next request asserting
+// a user fails with assertuserfailed (as on a real wiki after session expiry).
+func (f *FakeWiki) ExpireSessions() {
+	f.mu.Lock()
+	f.sessions = map[string]string{}
+	f.tokens = map[string]map[string]string{}
+	f.mu.Unlock()
+}
+
+// RotateTokens invalidates every issued token, so the next write fails with badtoken.
+func (f *FakeWiki) RotateTokens() {
+	f.mu.Lock()
+	f.tokens = map[string]map[string]string{}
+	f.mu.Unlock()
+}
+
+func (f *FakeWiki) Requests() []*Request {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]*Request(nil), f.requests...)
+}
+
+func (f *FakeWiki) RequestsFor(action string) []*Request {
+	var out []*Request
+	for _, r := range f.Requests() {
+		if r.Action() == action {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func (f *FakeWiki) LastRequest(action string) *Request {
+	reqs := f.RequestsFor(action)
+	if len(reqs) == 0 {
+		return nil
+	}
+	return reqs[len(reqs)-1]
+}
+
+// AssertSent fails the test unless the last request for action carried key=want.
+func (f *FakeWiki) AssertSent(action, key, want string) {
+	f.t.Helper()
+	r := f.LastRequest(action)
+	if r == nil {
+		f.t.Errorf("mwtest: no %s request was sent", action)
+		return
+	}
+	if got := r.Param(key); got != want {
+		f.t.Errorf("mwtest: action=%s %s=%q, want %q", action, key, got, want)
+	}
+}
+
+// AssertNotSent fails the test if the last request for action carried key at all.
+func (f *FakeWiki) AssertNotSent(action, key string) {
+	f.t.Helper()
+	r := f.LastRequest(action)
+	if r == nil {
+		f.t.Errorf("mwtest: no %s request was sent", action)
+		return
+	}
+	if r.Form.Has(key) {
+		f.t.Errorf("mwtest: action=%s unexpectedly sent %s=%q", action, key, r.Param(key))
+	}
+}
+
+func ErrorResponse(code, info string) map[string]any {
+	return map[string]any{
+		"error": map[string]any{"code": code, "info": info},
+	}
+}
+
+func BadToken() map[string]any {
+	return ErrorResponse("badtoken", "Invalid CSRF token.")
+}
+
+func AssertUserFailed() map[string]any {
+	return ErrorResponse("assertuserfailed", "You are no longer logged in, so the action could not be completed.")
+}
+
+// MaxLag returns a maxlag error reply with the headers MediaWiki sends alongside it.
+func MaxLag(lag time.Duration, retryAfter time.Duration) Reply {
+	secs := int(lag / time.Second)
+	h := http.Header{}
+	h.Set("Retry-After", strconv.Itoa(int(retryAfter/time.Second)))
+	h.Set("X-Database-Lag", strconv.Itoa(secs))
+	return Reply{
+		Status: http.StatusOK,
+		Header: h,
+		Body: map[string]any{
+			"error": map[string]any{
+				"code": "maxlag",
+				"info": "Waiting for a database server: " + strconv.Itoa(secs) + " seconds lagged.",
+				"lag":  secs,
+			},
+		},
+	}
+}
+
+func (f *FakeWiki) serveHTTP(w http.ResponseWriter, hr *http.Request) {
+	req, err := f.parse(hr)
+	if err != nil {
+		writeReply(w, Reply{Status: http.StatusBadRequest, Body: ErrorResponse("badrequest", err.Error())})
+		return
+	}
+
+	sid := ""
+	if ck, err := hr.Cookie(sessionCookie); err == nil {
+		sid = ck.Value
+	}
+
+	f.mu.Lock()
+	req.User = f.sessions[sid]
+	f.requests = append(f.requests, req)
+	h := f.handlers[req.Action()]
+	f.mu.Unlock()
+
+	if want := req.Param("assertuser"); want != "" && want != req.User {
+		writeReply(w, AssertUserFailed())
+		return
+	}
+	if req.Param("assert") == "user" && req.User == "" {
+		writeReply(w, AssertUserFailed())
+		return
+	}
+
+	switch req.Action() {
+	case "query":
+		if req.Param("meta") == "tokens" {
+			writeReply(w, f.issueTokens(sid, req.Param("type")))
+			return
+		}
+		if req.Param("meta") == "userinfo" && h == nil {
+			writeReply(w, userInfo(req.User))
+			return
+		}
+	case "login":
+		if h == nil {
+			f.login(w, sid, req)
+			return
+		}
+	case "logout":
+		if h == nil {
+			if !f.validToken(sid, req.Param("token")) {
+				writeReply(w, BadToken())
+				return
+			}
+			f.mu.Lock()
+			delete(f.sessions, sid)
+			delete(f.tokens, sid)
+			f.mu.Unlock()
+			writeReply(w, map[string]any{})
+			return
+		}
+	}
+
+	if h == nil {
+		writeReply(w, ErrorResponse("badvalue", "Unrecognized value for parameter \"action\": "+req.Action()+"."))
+		return
+	}
+	if tok := req.Param("token"); tok != "" && !f.validToken(sid, tok) {
+		writeReply(w, BadToken())
+		return
+	}
+	writeReply(w, h(req))
+}
+
+func (f *FakeWiki) parse(hr *http.Request) (*Request, error) {
+	req := &Request{Method: hr.Method, Header: hr.Header.Clone()}
+	if strings.HasPrefix(hr.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := hr.ParseMultipartForm(32 << 20); err != nil {
+			return nil, err
+		}
+		req.Files = map[string]UploadedFile{}
+		for field, fhs := range hr.MultipartForm.File {
+			if len(fhs) == 0 {
+				continue
+			}
+			fh, err := fhs[0].Open()
+			if err != nil {
+				return nil, err
+			}
+			data, err := io.ReadAll(fh)
+			_ = fh.Close()
+			if err != nil {
+				return nil, err
+			}
+			req.Files[field] = UploadedFile{Filename: fhs[0].Filename, Data: data}
+		}
+	} else if err := hr.ParseForm(); err != nil {
+		return nil, err
+	}
+	req.Form = hr.Form
+	return req, nil
+}
+
+func (f *FakeWiki) issueTokens(sid, types string) map[string]any {
+	if types == "" {
+		types = "csrf"
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	m := f.tokens[sid]
+	if m == nil {
+		m = map[string]string{}
+		f.tokens[sid] = m
+	}
+	out := map[string]any{}
+	for _, typ := range strings.Split(types, "|") {
+		if m[typ] == "" {
+			if typ == "csrf" && f.sessions[sid] == "" {
+				m[typ] = "+\\"
+			} else {
+				f.seq++
+				m[typ] = strings.ToUpper(typ) + "_" + strconv.Itoa(f.seq) + "+\\"
+			}
+		}
+		out[typ+"token"] = m[typ]
+	}
+	return map[string]any{"query": map[string]any{"tokens": out}}
+}
+
+func (f *FakeWiki) validToken(sid, tok string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, v := range f.tokens[sid] {
+		if v == tok {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *FakeWiki) login(w http.ResponseWriter, sid string, req *Request) {
+	f.mu.Lock()
+	loginTok := f.tokens[sid]["login"]
+	f.mu.Unlock()
+	if loginTok == "" || req.Param("lgtoken") != loginTok {
+		writeReply(w, map[string]any{"login": map[string]any{"result": "WrongToken"}})
+		return
+	}
+
+	name, pass := req.Param("lgname"), req.Param("lgpassword")
+	// Bot passwords log in as "User@BotName"; the session belongs to "User".
+	user, _, _ := strings.Cut(name, "@")
+
+	f.mu.Lock()
+	want, known := f.users[name]
+	anyUser := len(f.users) == 0
+	f.mu.Unlock()
+	if !anyUser && (!known || want != pass) {
+		writeReply(w, map[string]any{"login": map[string]any{
+			"result": "Failed",
+			"reason": "Incorrect username or password entered. Please try again.",
+		}})
+		return
+	}
+
+	newSID := randomID()
+	f.mu.Lock()
+	delete(f.sessions, sid)
+	delete(f.tokens, sid)
+	f.sessions[newSID] = user
+	f.mu.Unlock()
+
+	http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: newSID, Path: "/"})
+	writeReply(w, map[string]any{"login": map[string]any{
+		"result":     "Success",
+		"lguserid":   userID(user),
+		"lgusername": user,
+	}})
+}
+
+func userInfo(user string) map[string]any {
+	ui := map[string]any{"id": 0, "name": "127.0.0.1", "anon": true}
+	if user != "" {
+		ui = map[string]any{"id": userID(user), "name": user}
+	}
+	return map[string]any{"query": map[string]any{"userinfo": ui}}
+}
+
+// userID derives a stable, non-zero user id from the name.
+func userID(user string) int {
+	id := 0
+	for _, r := range user {
+		id = (id*31 + int(r)) % 1_000_000
+	}
+	return id + 1
+}
+
+func randomID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+func writeReply(w http.ResponseWriter, v any) {
+	rep, ok := v.(Reply)
+	if !ok {
+		if p, isPtr := v.(*Reply); isPtr && p != nil {
+			rep, ok = *p, true
+		}
+	}
+	if !ok {
+		rep = Reply{Body: v}
+	}
+	for k, vs := range rep.Header {
+		for _, hv := range vs {
+			w.Header().Add(k, hv)
+		}
+	}
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	}
+	if rep.Status != 0 {
+		w.WriteHeader(rep.Status)
+	}
+	if rep.Body == nil {
+		rep.Body = map[string]any{}
+	}
+	_ = json.NewEncoder(w).Encode(rep.Body)
+}
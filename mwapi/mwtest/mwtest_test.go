@@ -0,0 +1,101 @@
+package mwtest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi"
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+func TestFakeWiki_LoginAndEdit(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.AddUser("UserA", "pass")
+	wiki.Handle("edit", func(r *mwtest.Request) any {
+		return map[string]any{"edit": map[string]any{"result": "Success", "title": r.Param("title")}}
+	})
+
+	c := mwapi.New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	if _, err := c.Login(ctx, "UserA", "wrong"); err == nil {
+		t.Fatalf("Login with wrong password succeeded")
+	}
+	if _, err := c.Login(ctx, "UserA", "pass"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+
+	if _, err := mwapi.NewSession(c).Edit(ctx, mwapi.EditParams{Title: "Sandbox", Text: "hi"}); err != nil {
+		t.Fatalf("Edit: %v", err)
+	}
+	wiki.AssertSent("edit", "title", "Sandbox")
+	wiki.AssertSent("edit", "assertuser", "UserA")
+	if r := wiki.LastRequest("edit"); r.User != "UserA" {
+		t.Fatalf("edit made as %q, want UserA", r.User)
+	}
+}
+
+func TestFakeWiki_ExpiredSessionTriggersRelogin(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("parse", func(r *mwtest.Request) any {
+		return map[string]any{"parse": map[string]any{"title": r.Param("page")}}
+	})
+
+	c := mwapi.New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	if _, err := c.Login(ctx, "UserA", "pass"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	wiki.ExpireSessions()
+
+	if _, err := c.Post(ctx, map[string]any{"action": "parse", "page": "Main Page"}); err != nil {
+		t.Fatalf("Post(parse): %v", err)
+	}
+	if got := len(wiki.RequestsFor("login")); got != 2 {
+		t.Fatalf("login requests = %d, want 2", got)
+	}
+	if got := len(wiki.RequestsFor("parse")); got != 2 {
+		t.Fatalf("parse requests = %d, want 2", got)
+	}
+}
+
+func TestFakeWiki_CannedErrors(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("purge", func(r *mwtest.Request) any {
+		return mwtest.MaxLag(5*time.Second, 3*time.Second)
+	})
+
+	c := mwapi.New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	resp, err := c.Post(ctx, map[string]any{"action": "purge", "titles": "Main Page"})
+	if err != nil {
+		t.Fatalf("Post(purge): %v", err)
+	}
+	if resp.Error == nil || resp.Error.Code != "maxlag" {
+		t.Fatalf("error = %+v, want maxlag", resp.Error)
+	}
+	if got := resp.Header.Get("Retry-After"); got != "3" {
+		t.Fatalf("Retry-After = %q, want 3", got)
+	}
+
+	wiki.Handle("edit", func(r *mwtest.Request) any { return mwtest.BadToken() })
+	_, err = c.PostWithToken(ctx, mwapi.TokenCSRF, map[string]any{"action": "edit", "title": "X"}, nil)
+	if e, ok := mwapi.IsMediaWikiApiError(err); !ok || e.Code != "badtoken" {
+		t.Fatalf("err = %v, want badtoken", err)
+	}
+	if got := len(wiki.RequestsFor("edit")); got != 3 {
+		t.Fatalf("edit requests = %d, want 3 (token retry)", got)
+	}
+}
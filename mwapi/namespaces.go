@@ -0,0 +1,96 @@
+package mwapi
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// NamespaceID returns the id of the namespace called name: its localized or
+// canonical name, or an alias. Matching ignores case and treats underscores
+// as spaces, as MediaWiki does.
+func (si *SiteInfo) NamespaceID(name string) (int, bool) {
+	key := namespaceKey(name)
+	for _, ns := range si.Namespaces {
+		if namespaceKey(ns.Name) == key || (ns.Canonical != "" && namespaceKey(ns.Canonical) == key) {
+			return ns.ID, true
+		}
+	}
+	for _, a := range si.NamespaceAliases {
+		if namespaceKey(a.Alias) == key {
+			return a.ID, true
+		}
+	}
+	return 0, false
+}
+
+// NamespaceName returns the localized name of namespace id.
+func (si *SiteInfo) NamespaceName(id int) (string, bool) {
+	ns, ok := si.Namespaces[id]
+	return ns.Name, ok
+}
+
+// SplitTitle splits title into its namespace and the rest, capitalizing the
+// first letter of the rest when the namespace is first-letter. A title
+// without a known namespace prefix is in ns 0.
+func (si *SiteInfo) SplitTitle(title string) (int, string) {
+	title = strings.TrimPrefix(strings.TrimSpace(strings.ReplaceAll(title, "_", " ")), ":")
+	id, rest := 0, title
+	if prefix, after, ok := strings.Cut(title, ":"); ok {
+		if nsID, known := si.NamespaceID(prefix); known && nsID != 0 {
+			id, rest = nsID, strings.TrimSpace(after)
+		}
+	}
+	if si.Namespaces[id].Case != "case-sensitive" {
+		rest = upperFirst(rest)
+	}
+	return id, rest
+}
+
+func namespaceKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(strings.ReplaceAll(name, "_", " ")))
+}
+
+func upperFirst(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[n:]
+}
+
+// cachedSiteInfo returns the siteinfo cached by SiteInfo, or nil.
+func (c *Client) cachedSiteInfo() *SiteInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.siteInfo
+}
+
+// NamespaceID is SiteInfo.NamespaceID on the cached siteinfo. It reports
+// false until SiteInfo has fetched namespaces.
+func (c *Client) NamespaceID(name string) (int, bool) {
+	si := c.cachedSiteInfo()
+	if si == nil {
+		return 0, false
+	}
+	return si.NamespaceID(name)
+}
+
+// NamespaceName is SiteInfo.NamespaceName on the cached siteinfo.
+func (c *Client) NamespaceName(id int) (string, bool) {
+	si := c.cachedSiteInfo()
+	if si == nil {
+		return "", false
+	}
+	return si.NamespaceName(id)
+}
+
+// SplitTitle is SiteInfo.SplitTitle on the cached siteinfo. Without one,
+// every title is in ns 0 and left as is.
+func (c *Client) SplitTitle(title string) (int, string) {
+	si := c.cachedSiteInfo()
+	if si == nil {
+		return 0, title
+	}
+	return si.SplitTitle(title)
+}
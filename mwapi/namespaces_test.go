@@ -0,0 +1,57 @@
+package mwapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+func TestNamespaceHelpers(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("query", siteInfoHandler)
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	if _, ok := c.NamespaceID("Talk"); ok {
+		t.Fatalf("NamespaceID without cached siteinfo should report false")
+	}
+	if id, rest := c.SplitTitle("File:A.png"); id != 0 || rest != "File:A.png" {
+		t.Fatalf("SplitTitle without siteinfo = %d, %q", id, rest)
+	}
+	if _, err := c.SiteInfo(ctx); err != nil {
+		t.Fatalf("SiteInfo: %v", err)
+	}
+
+	for name, want := range map[string]int{"Talk": 1, "讨论": 1, "talk": 1, "图片": 6, "image": 6, "": 0} {
+		if id, ok := c.NamespaceID(name); !ok || id != want {
+			t.Fatalf("NamespaceID(%q) = %d, %v; want %d", name, id, ok, want)
+		}
+	}
+	if _, ok := c.NamespaceID("Nope"); ok {
+		t.Fatalf("NamespaceID(Nope) should report false")
+	}
+	if name, ok := c.NamespaceName(6); !ok || name != "文件" {
+		t.Fatalf("NamespaceName(6) = %q, %v", name, ok)
+	}
+
+	for _, tc := range []struct {
+		title string
+		id    int
+		rest  string
+	}{
+		{"图片:foo_bar.jpg", 6, "Foo bar.jpg"},
+		{"category: stubs", 14, "Stubs"},
+		{"Mainpage", 0, "Mainpage"},
+		{"unknown:thing", 0, "Unknown:thing"},
+		{":Category:X", 14, "X"},
+	} {
+		if id, rest := c.SplitTitle(tc.title); id != tc.id || rest != tc.rest {
+			t.Fatalf("SplitTitle(%q) = %d, %q; want %d, %q", tc.title, id, rest, tc.id, tc.rest)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package mwapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// ExportNDJSON runs a query to completion, writing every result item as one
+// JSON object per line to w: each page of query.pages when the query has
+// pages (prop/generator/titles), otherwise each row of the requested lists.
+// Only one batch is held in memory at a time. If w has a Flush method (e.g.
+// *bufio.Writer) it is flushed after every batch.
+//
+// With prop queries a page can appear in several batches, each time carrying
+// the props that continued; consumers should merge by pageid.
+func (c *Client) ExportNDJSON(ctx context.Context, p map[string]any, w io.Writer) error {
+	lists := paramList(p["list"])
+	flusher, _ := w.(interface{ Flush() error })
+
+	var line bytes.Buffer
+	return c.queryContinue(ctx, p, nil, func(resp *Response) error {
+		items, err := exportItems(resp, lists)
+		if err != nil {
+			return err
+		}
+		for _, raw := range items {
+			if ctx.Err() != nil {
+				return canceled(ctx, nil)
+			}
+			line.Reset()
+			if err := json.Compact(&line, raw); err != nil {
+				return err
+			}
+			line.WriteByte('\n')
+			if _, err := w.Write(line.Bytes()); err != nil {
+				return err
+			}
+		}
+		if flusher != nil {
+			return flusher.Flush()
+		}
+		return nil
+	})
+}
+
+func exportItems(resp *Response, lists []string) ([]json.RawMessage, error) {
+	var out struct {
+		Query map[string]json.RawMessage `json:"query"`
+	}
+	if err := resp.Into(&out); err != nil {
+		return nil, err
+	}
+
+	var items []json.RawMessage
+	if _, ok := out.Query["pages"]; ok {
+		pages, err := resp.Pages()
+		if err != nil {
+			return nil, err
+		}
+		for _, pg := range pages {
+			items = append(items, pg.Raw)
+		}
+	}
+	for _, list := range lists {
+		raw, ok := out.Query[list]
+		if !ok {
+			continue
+		}
+		var rows []json.RawMessage
+		if err := json.Unmarshal(raw, &rows); err != nil {
+			return nil, errors.New("query." + list + " is not a list")
+		}
+		items = append(items, rows...)
+	}
+	return items, nil
+}
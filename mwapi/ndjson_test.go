@@ -0,0 +1,68 @@
+package mwapi
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+func TestExportNDJSON_Paginated(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("query", func(r *mwtest.Request) any {
+		if r.Param("apcontinue") == "" {
+			return map[string]any{
+				"continue": map[string]any{"apcontinue": "C", "continue": "-||"},
+				"query": map[string]any{"allpages": []any{
+					map[string]any{"pageid": 1, "ns": 0, "title": "A"},
+					map[string]any{"pageid": 2, "ns": 0, "title": "B"},
+				}},
+			}
+		}
+		return map[string]any{
+			"batchcomplete": true,
+			"query": map[string]any{"allpages": []any{
+				map[string]any{"pageid": 3, "ns": 0, "title": "C"},
+			}},
+		}
+	})
+
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	var buf bytes.Buffer
+	bw := bufio.NewWriter(&buf)
+	if err := c.ExportNDJSON(ctx, map[string]any{"action": "query", "list": "allpages", "aplimit": 2}, bw); err != nil {
+		t.Fatalf("ExportNDJSON: %v", err)
+	}
+	if bw.Buffered() != 0 {
+		t.Fatalf("%d bytes left unflushed", bw.Buffered())
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines: %q", len(lines), buf.String())
+	}
+	for i, want := range []string{"A", "B", "C"} {
+		var row struct {
+			Title string `json:"title"`
+		}
+		if err := json.Unmarshal([]byte(lines[i]), &row); err != nil {
+			t.Fatalf("line %d %q: %v", i, lines[i], err)
+		}
+		if row.Title != want {
+			t.Fatalf("line %d title = %q, want %q", i, row.Title, want)
+		}
+	}
+	if got := len(wiki.RequestsFor("query")); got != 2 {
+		t.Fatalf("query requests = %d, want 2", got)
+	}
+}
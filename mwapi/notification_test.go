@@ -0,0 +1,58 @@
+package mwapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+func TestSetNotificationTimestamp_EntireWatchlist(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("setnotificationtimestamp", func(r *mwtest.Request) any {
+		if r.User == "" {
+			return mwtest.ErrorResponse("notloggedin", "Please log in to view and edit your watchlist.")
+		}
+		return map[string]any{"setnotificationtimestamp": map[string]any{
+			"notificationtimestamp": "2026-10-17T12:00:00Z",
+		}}
+	})
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	if _, err := c.SetNotificationTimestamp(ctx, SetNotifParams{EntireWatchlist: true}); !errors.Is(err, ErrNotLoggedIn) {
+		t.Fatalf("before login: err = %v, want ErrNotLoggedIn", err)
+	}
+	if got := len(wiki.RequestsFor("setnotificationtimestamp")); got != 0 {
+		t.Fatalf("requests before login = %d, want 0", got)
+	}
+
+	if _, err := c.Login(ctx, "UserA", "pass"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	res, err := c.SetNotificationTimestamp(ctx, SetNotifParams{EntireWatchlist: true})
+	if err != nil {
+		t.Fatalf("SetNotificationTimestamp: %v", err)
+	}
+	if res.NotificationTimestamp != "2026-10-17T12:00:00Z" || len(res.Pages) != 0 {
+		t.Fatalf("result = %+v", res)
+	}
+	wiki.AssertSent("setnotificationtimestamp", "entirewatchlist", "1")
+	wiki.AssertNotSent("setnotificationtimestamp", "timestamp")
+
+	// The server's notloggedin also maps to ErrNotLoggedIn.
+	wiki.ExpireSessions()
+	c2 := New(wiki.URL(), WithKeepLogin(false))
+	if _, err := c2.Login(ctx, "UserA", "pass"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	wiki.ExpireSessions()
+	if _, err := c2.SetNotificationTimestamp(ctx, SetNotifParams{Titles: []string{"A"}}); !errors.Is(err, ErrNotLoggedIn) {
+		t.Fatalf("expired session: err = %v, want ErrNotLoggedIn", err)
+	}
+}
@@ -0,0 +1,70 @@
+package mwapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// WhoAmI asks the wiki which user the client's requests run as. It returns
+// "" for an anonymous session. No assertuser is sent and no relogin is tried.
+func (c *Client) WhoAmI(ctx context.Context) (_ string, err error) {
+	ctx, done := c.startCall(ctx)
+	defer func() { err = done(err) }()
+	name, anon, err := c.whoAmI(ctx)
+	if err != nil || anon {
+		return "", err
+	}
+	return name, nil
+}
+
+// whoAmI probes meta=userinfo straight through doOnce, bypassing assertuser
+// injection and relogin.
+func (c *Client) whoAmI(ctx context.Context) (name string, anon bool, err error) {
+	np, err := normalizeParams(map[string]any{"action": "query", "meta": "userinfo"})
+	if err != nil {
+		return "", false, err
+	}
+	resp, err := c.doOnce(ctx, http.MethodGet, np, true)
+	if err != nil {
+		return "", false, err
+	}
+	var out struct {
+		Query struct {
+			UserInfo struct {
+				Name string `json:"name"`
+				Anon any    `json:"anon"`
+			} `json:"userinfo"`
+		} `json:"query"`
+	}
+	if err := resp.Into(&out); err != nil {
+		return "", false, err
+	}
+	ui := out.Query.UserInfo
+	return ui.Name, ui.Anon != nil && ui.Anon != false, nil
+}
+
+// identifyOAuthUser learns, once, which user an OAuth token acts as, so
+// assertuser and per-user token caching work as after a cookie login.
+func (c *Client) identifyOAuthUser(ctx context.Context) error {
+	c.mu.Lock()
+	skip := c.oauthToken == "" || c.oauthIdentified
+	c.mu.Unlock()
+	if skip {
+		return nil
+	}
+
+	name, anon, err := c.whoAmI(ctx)
+	if err != nil {
+		return fmt.Errorf("oauth: identify user: %w", err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.oauthIdentified {
+		c.oauthIdentified = true
+		if !anon {
+			c.loggedInUser = name
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package mwapi
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// RequestInfo describes one HTTP request made for an API call.
+type RequestInfo struct {
+	Method string
+	Action string
+	// Attempt counts the requests of one Get/Post call from 1; retries after
+	// maxlag, server errors or a relogin get higher numbers.
+	Attempt int
+	// RequestID is the call's correlation ID (see RequestIDFromContext).
+	RequestID string
+}
+
+// ResponseInfo describes the outcome of one HTTP request. StatusCode is 0 and
+// Bytes is 0 when no response arrived.
+type ResponseInfo struct {
+	StatusCode int
+	Bytes      int
+	Duration   time.Duration
+	// ErrorCode is the API error code of the response, if any.
+	ErrorCode string
+}
+
+// WithObserver calls fn after every HTTP request the client makes, with the
+// parsed action, e.g. to export metrics per action and error code. fn runs
+// on the request's goroutine and must not block.
+func WithObserver(fn func(RequestInfo, ResponseInfo, error)) Option {
+	return func(c *Client) {
+		c.observer = fn
+	}
+}
+
+type attemptKey struct{}
+
+// withAttempts gives a call its own request counter for RequestInfo.Attempt.
+func (c *Client) withAttempts(ctx context.Context) context.Context {
+	if c.observer == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, attemptKey{}, new(atomic.Int32))
+}
+
+func (c *Client) observe(ctx context.Context, method, action string, start time.Time, resp *Response, err error) {
+	attempt := 1
+	if n, ok := ctx.Value(attemptKey{}).(*atomic.Int32); ok {
+		attempt = int(n.Add(1))
+	}
+	ri := ResponseInfo{Duration: time.Since(start)}
+	if resp != nil {
+		ri.StatusCode = resp.StatusCode
+		ri.Bytes = len(resp.Raw)
+		ri.ErrorCode = responseErrorCode(resp)
+	}
+	if e, ok := IsMediaWikiApiError(err); ok {
+		ri.ErrorCode = e.Code
+	}
+	id, _ := RequestIDFromContext(ctx)
+	c.observer(RequestInfo{Method: method, Action: strings.ToLower(action), Attempt: attempt, RequestID: id}, ri, err)
+}
@@ -0,0 +1,97 @@
+package mwapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+type observed struct {
+	req  RequestInfo
+	resp ResponseInfo
+	err  error
+}
+
+func TestWithObserver(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.AddUser("UserA", "secret")
+	wiki.Handle("parse", func(r *mwtest.Request) any {
+		return map[string]any{"parse": map[string]any{"title": "A"}}
+	})
+
+	var mu sync.Mutex
+	var got []observed
+	c := New(wiki.URL(), WithObserver(func(req RequestInfo, resp ResponseInfo, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, observed{req, resp, err})
+	}))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	if _, err := c.Login(ctx, "UserA", "secret"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	wiki.ExpireSessions()
+	// assertuserfailed, relogin (token + login), then the retried parse.
+	if _, err := c.Get(ContextWithRequestID(ctx, "trace-1"), map[string]any{"action": "parse", "page": "A"}); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	var parses []observed
+	for _, o := range got {
+		if o.req.Action == "parse" {
+			parses = append(parses, o)
+		}
+	}
+	if len(parses) != 2 {
+		t.Fatalf("parse observations = %+v, want 2", parses)
+	}
+	first, second := parses[0], parses[1]
+	if first.req.Attempt != 1 || first.resp.ErrorCode != "assertuserfailed" || first.req.Method != http.MethodGet {
+		t.Fatalf("first = %+v", first)
+	}
+	if second.req.Attempt != 2 || second.resp.ErrorCode != "" || second.resp.StatusCode != http.StatusOK || second.resp.Bytes == 0 || second.err != nil {
+		t.Fatalf("second = %+v", second)
+	}
+	if first.req.RequestID != "trace-1" || second.req.RequestID != "trace-1" {
+		t.Fatalf("request ids = %q, %q; want trace-1", first.req.RequestID, second.req.RequestID)
+	}
+	if first.resp.Duration <= 0 {
+		t.Fatalf("duration not measured: %+v", first.resp)
+	}
+	for _, o := range got {
+		if o.req.Action == "login" && o.req.Attempt != 1 {
+			t.Fatalf("login counted as attempt %d of another call", o.req.Attempt)
+		}
+	}
+}
+
+func TestWithObserver_TransportError(t *testing.T) {
+	t.Parallel()
+
+	var got []observed
+	c := New("http://127.0.0.1:1/api.php", WithObserver(func(req RequestInfo, resp ResponseInfo, err error) {
+		got = append(got, observed{req, resp, err})
+	}))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	_, err := c.Get(ctx, map[string]any{"action": "query"})
+	var te *TransportError
+	if !errors.As(err, &te) {
+		t.Fatalf("err = %v, want *TransportError", err)
+	}
+	if len(got) != 1 || got[0].resp.StatusCode != 0 || got[0].req.Action != "query" || got[0].err == nil {
+		t.Fatalf("observed = %+v", got)
+	}
+}
@@ -0,0 +1,84 @@
+package mwapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// SetOptions changes preferences of the logged-in user (action=options).
+// Values may contain "=" and "|"; keys may not contain "=".
+func (c *Client) SetOptions(ctx context.Context, options map[string]string) error {
+	if len(options) == 0 {
+		return errors.New("options: no options given")
+	}
+	p := map[string]any{"action": "options"}
+	if len(options) == 1 {
+		// The single-change form takes the value verbatim.
+		for k, v := range options {
+			if err := checkOptionName(k); err != nil {
+				return err
+			}
+			p["optionname"] = k
+			p["optionvalue"] = v
+		}
+	} else {
+		change, err := encodeOptionChanges(options)
+		if err != nil {
+			return err
+		}
+		p["change"] = change
+	}
+	return c.postOptions(ctx, p)
+}
+
+// ResetOptions resets all preferences of the logged-in user to the defaults.
+func (c *Client) ResetOptions(ctx context.Context) error {
+	return c.postOptions(ctx, map[string]any{"action": "options", "reset": true})
+}
+
+func (c *Client) postOptions(ctx context.Context, p map[string]any) error {
+	resp, err := c.PostWithToken(ctx, TokenCSRF, p, nil)
+	if err != nil {
+		return fmt.Errorf("options: %w", err)
+	}
+	if err := apiError(resp); err != nil {
+		return fmt.Errorf("options: %w", err)
+	}
+	return nil
+}
+
+// encodeOptionChanges builds the change parameter: name=value items joined by
+// "|", or by U+001F (with a leading U+001F, as the API expects) when a value
+// itself contains "|". Items are sorted for a stable request.
+func encodeOptionChanges(options map[string]string) (string, error) {
+	sep := "|"
+	items := make([]string, 0, len(options))
+	for _, k := range slices.Sorted(maps.Keys(options)) {
+		if err := checkOptionName(k); err != nil {
+			return "", err
+		}
+		v := options[k]
+		if strings.Contains(v, "\x1f") {
+			return "", fmt.Errorf("options: value of %q contains U+001F", k)
+		}
+		if strings.Contains(v, "|") {
+			sep = "\x1f"
+		}
+		items = append(items, k+"="+v)
+	}
+	if sep == "|" {
+		return strings.Join(items, sep), nil
+	}
+	return sep + strings.Join(items, sep), nil
+}
+
+func checkOptionName(k string) error {
+	if k == "" || strings.ContainsAny(k, "=|\x1f") {
+		return fmt.Errorf("options: invalid option name %q", k)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package mwapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+func TestSetOptions_Encoding(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("options", func(r *mwtest.Request) any {
+		return map[string]any{"options": "success"}
+	})
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	if err := c.SetOptions(ctx, map[string]string{"skin": "vector", "enotifwatchlistpages": "0"}); err != nil {
+		t.Fatalf("SetOptions: %v", err)
+	}
+	wiki.AssertSent("options", "change", "enotifwatchlistpages=0|skin=vector")
+
+	if err := c.SetOptions(ctx, map[string]string{"nickname": "a=b|c", "skin": "vector"}); err != nil {
+		t.Fatalf("SetOptions: %v", err)
+	}
+	wiki.AssertSent("options", "change", "\x1fnickname=a=b|c\x1fskin=vector")
+
+	if err := c.SetOptions(ctx, map[string]string{"nickname": "x|y"}); err != nil {
+		t.Fatalf("SetOptions: %v", err)
+	}
+	wiki.AssertSent("options", "optionname", "nickname")
+	wiki.AssertSent("options", "optionvalue", "x|y")
+
+	if err := c.SetOptions(ctx, map[string]string{"bad=key": "1", "skin": "vector"}); err == nil {
+		t.Fatalf("SetOptions accepted an option name with '='")
+	}
+	for _, k := range []string{"", "bad=key"} {
+		if err := c.SetOptions(ctx, map[string]string{k: "1"}); err == nil {
+			t.Fatalf("SetOptions accepted the single option name %q", k)
+		}
+	}
+
+	if err := c.ResetOptions(ctx); err != nil {
+		t.Fatalf("ResetOptions: %v", err)
+	}
+	wiki.AssertSent("options", "reset", "1")
+}
@@ -0,0 +1,86 @@
+package mwapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// OrderedMap is a JSON object with its keys in document order. Values are left
+// undecoded; nested objects keep their original byte order inside the raw value.
+type OrderedMap struct {
+	Keys   []string
+	Values map[string]json.RawMessage
+}
+
+func (m *OrderedMap) Get(key string) (json.RawMessage, bool) {
+	v, ok := m.Values[key]
+	return v, ok
+}
+
+// MarshalJSON writes the object back with its keys in the original order.
+func (m *OrderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range m.Keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		kb, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(kb)
+		buf.WriteByte(':')
+		buf.Write(m.Values[k])
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (m *OrderedMap) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return errors.New("ordered map: top level is not a JSON object")
+	}
+
+	m.Keys = nil
+	m.Values = map[string]json.RawMessage{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return fmt.Errorf("ordered map: unexpected token %v", tok)
+		}
+		var v json.RawMessage
+		if err := dec.Decode(&v); err != nil {
+			return err
+		}
+		if _, dup := m.Values[key]; !dup {
+			m.Keys = append(m.Keys, key)
+		}
+		m.Values[key] = v
+	}
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// IntoOrdered decodes the top level of the response keeping key order, for
+// tools that dump or diff responses.
+func (r *Response) IntoOrdered() (*OrderedMap, error) {
+	var m OrderedMap
+	if err := json.Unmarshal(r.Raw, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
@@ -0,0 +1,37 @@
+package mwapi
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestResponse_IntoOrdered(t *testing.T) {
+	t.Parallel()
+
+	raw := `{"warnings":{"main":{"warnings":"x"}},"continue":{"rvcontinue":"1","continue":"||"},"batchcomplete":true,"query":{"z":1,"a":2}}`
+	resp := &Response{Raw: json.RawMessage(raw)}
+
+	m, err := resp.IntoOrdered()
+	if err != nil {
+		t.Fatalf("IntoOrdered: %v", err)
+	}
+	if want := []string{"warnings", "continue", "batchcomplete", "query"}; !slices.Equal(m.Keys, want) {
+		t.Fatalf("keys = %v, want %v", m.Keys, want)
+	}
+	if v, ok := m.Get("query"); !ok || string(v) != `{"z":1,"a":2}` {
+		t.Fatalf("query = %s, %v", v, ok)
+	}
+
+	out, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if string(out) != raw {
+		t.Fatalf("round trip = %s", out)
+	}
+
+	if _, err := (&Response{Raw: json.RawMessage(`[1,2]`)}).IntoOrdered(); err == nil {
+		t.Fatalf("IntoOrdered accepted a top-level array")
+	}
+}
@@ -0,0 +1,81 @@
+package mwapi
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// maxTitlesPerQuery is the titles/pageids limit for accounts without apihighlimits.
+const maxTitlesPerQuery = 50
+
+// maxTitlesPerQueryHigh is the limit with apihighlimits (bots, admins).
+const maxTitlesPerQueryHigh = 500
+
+// PageProps returns the page properties (prop=pageprops) of each title, keyed by
+// the title as passed in. props filters to specific properties (e.g. "wikibase_item");
+// empty means all. Missing or invalid pages have no entry; existing pages without
+// properties map to an empty map.
+func (c *Client) PageProps(ctx context.Context, titles []string, props []string) (map[string]map[string]string, error) {
+	out := make(map[string]map[string]string, len(titles))
+	for start := 0; start < len(titles); start += maxTitlesPerQuery {
+		end := min(start+maxTitlesPerQuery, len(titles))
+		chunk := titles[start:end]
+
+		err := c.queryContinue(ctx, map[string]any{
+			"action": "query",
+			"prop":   "pageprops",
+			"titles": chunk,
+			"ppprop": props,
+		}, nil, func(resp *Response) error {
+			pages, err := resp.Pages()
+			if err != nil {
+				return err
+			}
+			inputs := inputTitles(resp, chunk)
+			for _, pg := range pages {
+				if pg.Missing || pg.Invalid {
+					continue
+				}
+				var v struct {
+					PageProps map[string]string `json:"pageprops"`
+				}
+				if err := json.Unmarshal(pg.Raw, &v); err != nil {
+					return err
+				}
+				for _, in := range inputs[pg.Title] {
+					m := out[in]
+					if m == nil {
+						m = map[string]string{}
+						out[in] = m
+					}
+					for k, val := range v.PageProps {
+						m[k] = val
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// inputTitles maps each canonical title in resp back to the input titles that
+// normalized to it.
+func inputTitles(resp *Response, titles []string) map[string][]string {
+	norm := map[string]string{}
+	for _, n := range resp.Normalized() {
+		norm[n.From] = n.To
+	}
+	out := make(map[string][]string, len(titles))
+	for _, t := range titles {
+		canon := t
+		if to, ok := norm[t]; ok {
+			canon = to
+		}
+		out[canon] = append(out[canon], t)
+	}
+	return out
+}
@@ -0,0 +1,62 @@
+package mwapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+func TestPageProps(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("query", func(r *mwtest.Request) any {
+		var pages []any
+		var normalized []any
+		for _, title := range strings.Split(r.Param("titles"), "|") {
+			switch title {
+			case "earth":
+				normalized = append(normalized, map[string]any{"from": "earth", "to": "Earth"})
+				pages = append(pages, map[string]any{"pageid": 1, "ns": 0, "title": "Earth",
+					"pageprops": map[string]any{"wikibase_item": "Q2"}})
+			case "Sandbox":
+				pages = append(pages, map[string]any{"pageid": 2, "ns": 0, "title": "Sandbox"})
+			case "Nope":
+				pages = append(pages, map[string]any{"ns": 0, "title": "Nope", "missing": true})
+			default:
+				pages = append(pages, map[string]any{"pageid": 10, "ns": 0, "title": title})
+			}
+		}
+		return map[string]any{"query": map[string]any{"normalized": normalized, "pages": pages}}
+	})
+
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	titles := []string{"earth", "Sandbox", "Nope"}
+	for i := 0; i < 60; i++ {
+		titles = append(titles, fmt.Sprintf("Filler %d", i))
+	}
+	props, err := c.PageProps(ctx, titles, []string{"wikibase_item"})
+	if err != nil {
+		t.Fatalf("PageProps: %v", err)
+	}
+	if got := props["earth"]["wikibase_item"]; got != "Q2" {
+		t.Fatalf("earth wikibase_item = %q, want Q2", got)
+	}
+	if m, ok := props["Sandbox"]; !ok || len(m) != 0 {
+		t.Fatalf("Sandbox props = %v, %v; want empty map", m, ok)
+	}
+	if _, ok := props["Nope"]; ok {
+		t.Fatalf("missing page should have no entry")
+	}
+	if got := len(wiki.RequestsFor("query")); got != 2 {
+		t.Fatalf("query requests = %d, want 2 (chunks of 50)", got)
+	}
+	wiki.AssertSent("query", "ppprop", "wikibase_item")
+}
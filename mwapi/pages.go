@@ -0,0 +1,295 @@
+package mwapi
+
+import (
+	"context"
+	"encoding/json"
+	"iter"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Page struct {
+	PageID       int64  `json:"pageid"`
+	NS           int    `json:"ns"`
+	Title        string `json:"title"`
+	Missing      bool   `json:"missing,omitempty"`
+	Invalid      bool   `json:"invalid,omitempty"`
+	Redirect     bool   `json:"redirect,omitempty"`
+	ContentModel string `json:"contentmodel,omitempty"`
+	LastRevID    int64  `json:"lastrevid,omitempty"`
+	Touched      string `json:"touched,omitempty"`
+
+	Content   string `json:"-"`
+	Timestamp string `json:"-"`
+
+	// Raw is the page object as returned, for props not decoded above.
+	Raw json.RawMessage `json:"-"`
+}
+
+func (p *Page) UnmarshalJSON(b []byte) error {
+	type plain Page
+	var v struct {
+		plain
+		// formatversion=1 flags are present-as-"" rather than booleans.
+		Missing  json.RawMessage `json:"missing"`
+		Invalid  json.RawMessage `json:"invalid"`
+		Redirect json.RawMessage `json:"redirect"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*p = Page(v.plain)
+	p.Missing = rawFlag(v.Missing)
+	p.Invalid = rawFlag(v.Invalid)
+	p.Redirect = rawFlag(v.Redirect)
+	p.Raw = append(json.RawMessage(nil), b...)
+	return nil
+}
+
+// rawFlag interprets a MediaWiki boolean: fv2 true/false, fv1 presence ("").
+func rawFlag(raw json.RawMessage) bool {
+	if len(raw) == 0 {
+		return false
+	}
+	var b bool
+	if err := json.Unmarshal(raw, &b); err == nil {
+		return b
+	}
+	return string(raw) != "null"
+}
+
+// Pages decodes query.pages from either formatversion=2 (array) or
+// formatversion=1 (object keyed by page id). For fv1 the order follows
+// query.pageids when indexpageids was requested, otherwise page id order.
+func (r *Response) Pages() ([]Page, error) {
+	var out struct {
+		Query struct {
+			Pages   json.RawMessage `json:"pages"`
+			PageIDs []string        `json:"pageids"`
+		} `json:"query"`
+	}
+	if err := r.Into(&out); err != nil {
+		return nil, err
+	}
+	raw := out.Query.Pages
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	if raw[0] == '[' {
+		var pages []Page
+		if err := json.Unmarshal(raw, &pages); err != nil {
+			return nil, err
+		}
+		return pages, nil
+	}
+
+	var byID map[string]Page
+	if err := json.Unmarshal(raw, &byID); err != nil {
+		return nil, err
+	}
+	keys := out.Query.PageIDs
+	if len(keys) == 0 {
+		for k := range byID {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			a, _ := strconv.ParseInt(keys[i], 10, 64)
+			b, _ := strconv.ParseInt(keys[j], 10, 64)
+			return a < b
+		})
+	}
+	pages := make([]Page, 0, len(byID))
+	for _, k := range keys {
+		if pg, ok := byID[k]; ok {
+			pages = append(pages, pg)
+		}
+	}
+	return pages, nil
+}
+
+type QueryOption func(*queryOptions)
+
+type queryOptions struct {
+	preservePageOrder bool
+	limit             int
+	// limitParam is the request parameter bounding items per batch (e.g.
+	// aplimit); set by each iterator, not by options.
+	limitParam string
+}
+
+func newQueryOptions(opts []QueryOption) queryOptions {
+	var qo queryOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&qo)
+		}
+	}
+	return qo
+}
+
+// WithPreservePageOrder requests indexpageids=1 and returns pages in the order
+// of the input titles/pageids, following normalization and redirects.
+func WithPreservePageOrder(v bool) QueryOption {
+	return func(o *queryOptions) {
+		o.preservePageOrder = v
+	}
+}
+
+// WithLimit ends an iterator after n items in total, across batches. Zero
+// means no limit.
+func WithLimit(n int) QueryOption {
+	return func(o *queryOptions) {
+		if n >= 0 {
+			o.limit = n
+		}
+	}
+}
+
+// iterOptions resolves opts for an iterator whose batch size is limitParam.
+func iterOptions(opts []QueryOption, limitParam string) queryOptions {
+	qo := newQueryOptions(opts)
+	qo.limitParam = limitParam
+	return qo
+}
+
+// EachPage runs a query and yields every page of query.pages, following continuation.
+func (c *Client) EachPage(ctx context.Context, p map[string]any, opts ...QueryOption) iter.Seq2[Page, error] {
+	qo := newQueryOptions(opts)
+	params := make(map[string]any, len(p)+1)
+	for k, v := range p {
+		params[k] = v
+	}
+	if qo.preservePageOrder {
+		params["indexpageids"] = true
+	}
+
+	extract := func(resp *Response) ([]Page, error) {
+		pages, err := resp.Pages()
+		if err != nil || !qo.preservePageOrder {
+			return pages, err
+		}
+		return orderPages(resp, pages, paramList(p["titles"]), paramList(p["pageids"]))
+	}
+	// Pages are bounded by the generator's limit, if any (e.g. gaplimit).
+	if gen, ok := params["generator"].(string); ok {
+		if prefix, ok := generatorPrefixes[strings.ToLower(gen)]; ok {
+			qo.limitParam = "g" + prefix + "limit"
+		}
+	}
+	return queryItems(ctx, c, params, extract, qo)
+}
+
+// generatorPrefixes maps core generator modules to their parameter prefix.
+// Pages from other generators are still counted by WithLimit; only their
+// batch size is left as the caller set it.
+var generatorPrefixes = map[string]string{
+	"allcategories":       "ac",
+	"alldeletedrevisions": "adr",
+	"allfileusages":       "af",
+	"allimages":           "ai",
+	"alllinks":            "al",
+	"allpages":            "ap",
+	"allredirects":        "ar",
+	"allrevisions":        "arv",
+	"alltransclusions":    "at",
+	"backlinks":           "bl",
+	"categories":          "cl",
+	"categorymembers":     "cm",
+	"deletedrevisions":    "drv",
+	"embeddedin":          "ei",
+	"exturlusage":         "eu",
+	"fileusage":           "fu",
+	"images":              "im",
+	"imageusage":          "iu",
+	"iwbacklinks":         "iwbl",
+	"langbacklinks":       "lbl",
+	"links":               "pl",
+	"linkshere":           "lh",
+	"pageswithprop":       "pwp",
+	"prefixsearch":        "ps",
+	"protectedtitles":     "pt",
+	"querypage":           "qp",
+	"random":              "rn",
+	"recentchanges":       "rc",
+	"redirects":           "rd",
+	"revisions":           "rv",
+	"search":              "sr",
+	"templates":           "tl",
+	"transcludedin":       "ti",
+	"watchlist":           "wl",
+	"watchlistraw":        "wr",
+}
+
+// orderPages sorts pages to follow the input titles/pageids. Pages that cannot
+// be correlated keep their relative order at the end.
+func orderPages(resp *Response, pages []Page, titles, pageIDs []string) ([]Page, error) {
+	if len(titles) == 0 && len(pageIDs) == 0 {
+		return pages, nil
+	}
+
+	norm := map[string]string{}
+	for _, n := range resp.Normalized() {
+		norm[n.From] = n.To
+	}
+	redir := map[string]string{}
+	for _, r := range resp.Redirects() {
+		redir[r.From] = r.To
+	}
+
+	rank := map[string]int{}
+	for i, t := range titles {
+		if to, ok := norm[t]; ok {
+			t = to
+		}
+		if to, ok := redir[t]; ok {
+			t = to
+		}
+		if _, seen := rank["t:"+t]; !seen {
+			rank["t:"+t] = i
+		}
+	}
+	for i, id := range pageIDs {
+		if _, seen := rank["i:"+id]; !seen {
+			rank["i:"+id] = len(titles) + i
+		}
+	}
+
+	pos := func(pg Page) int {
+		if r, ok := rank["t:"+pg.Title]; ok {
+			return r
+		}
+		if r, ok := rank["i:"+strconv.FormatInt(pg.PageID, 10)]; ok {
+			return r
+		}
+		return len(titles) + len(pageIDs)
+	}
+	out := append([]Page(nil), pages...)
+	sort.SliceStable(out, func(i, j int) bool { return pos(out[i]) < pos(out[j]) })
+	return out, nil
+}
+
+// paramList returns the individual values of a multi-value parameter.
+func paramList(v any) []string {
+	switch x := v.(type) {
+	case nil:
+		return nil
+	case string:
+		if x == "" {
+			return nil
+		}
+		return strings.Split(x, "|")
+	case []string:
+		return x
+	default:
+		np := normalizedParams{Values: map[string][]string{}}
+		if err := addAny(&np, "v", v); err != nil {
+			return nil
+		}
+		if s := np.Values.Get("v"); s != "" {
+			return strings.Split(s, "|")
+		}
+		return nil
+	}
+}
@@ -0,0 +1,102 @@
+package mwapi
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+func TestEachPage_PreservePageOrder(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("query", func(r *mwtest.Request) any {
+		return map[string]any{"query": map[string]any{
+			"normalized": []any{map[string]any{"from": "zeta", "to": "Zeta"}},
+			"redirects":  []any{map[string]any{"from": "Redir", "to": "Target"}},
+			"pageids":    []string{"1", "2", "3"},
+			"pages": []any{
+				map[string]any{"pageid": 1, "ns": 0, "title": "Alpha"},
+				map[string]any{"pageid": 2, "ns": 0, "title": "Target"},
+				map[string]any{"pageid": 3, "ns": 0, "title": "Zeta"},
+			},
+		}}
+	})
+
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	collect := func(opts ...QueryOption) []string {
+		var titles []string
+		for pg, err := range c.EachPage(ctx, map[string]any{
+			"titles":    []string{"zeta", "Alpha", "Redir"},
+			"redirects": true,
+		}, opts...) {
+			if err != nil {
+				t.Fatalf("EachPage: %v", err)
+			}
+			titles = append(titles, pg.Title)
+		}
+		return titles
+	}
+
+	if got := collect(); got[0] != "Alpha" {
+		t.Fatalf("default order = %v, want server order", got)
+	}
+	wiki.AssertNotSent("query", "indexpageids")
+
+	got := collect(WithPreservePageOrder(true))
+	want := []string{"Zeta", "Alpha", "Target"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("preserved order = %v, want %v", got, want)
+		}
+	}
+	wiki.AssertSent("query", "indexpageids", "1")
+}
+
+func TestResponse_PagesFormatVersion1(t *testing.T) {
+	t.Parallel()
+
+	raw := `{"query":{"pageids":["-1","5"],"pages":{
+		"5":{"pageid":5,"ns":0,"title":"Five"},
+		"-1":{"ns":0,"title":"Nope","missing":""}}}}`
+	resp := &Response{Raw: json.RawMessage(raw)}
+
+	pages, err := resp.Pages()
+	if err != nil {
+		t.Fatalf("Pages: %v", err)
+	}
+	if len(pages) != 2 || pages[0].Title != "Nope" || !pages[0].Missing || pages[1].Missing {
+		t.Fatalf("pages = %+v", pages)
+	}
+}
+
+func TestEachPage_CapsGeneratorLimit(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("query", func(r *mwtest.Request) any {
+		return map[string]any{"query": map[string]any{"pages": []any{
+			map[string]any{"pageid": 1, "ns": 0, "title": "A"},
+			map[string]any{"pageid": 2, "ns": 0, "title": "B"},
+		}}}
+	})
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	// A stray limit of another generator must not be picked instead.
+	p := map[string]any{"generator": "search", "gsrsearch": "x", "gsrlimit": 50, "gaplimit": 50}
+	for _, err := range c.EachPage(ctx, p, WithLimit(2)) {
+		if err != nil {
+			t.Fatalf("EachPage: %v", err)
+		}
+	}
+	wiki.AssertSent("query", "gsrlimit", "2")
+	wiki.AssertSent("query", "gaplimit", "50")
+}
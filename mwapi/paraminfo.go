@@ -0,0 +1,169 @@
+package mwapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+)
+
+// ParamInfoResult describes API modules as reported by action=paraminfo.
+type ParamInfoResult struct {
+	// Modules lists the known modules among those asked for; unknown
+	// module paths are left out.
+	Modules []ModuleInfo
+}
+
+// Module returns the module with the given path (e.g. "query+revisions").
+func (r *ParamInfoResult) Module(path string) (ModuleInfo, bool) {
+	for _, m := range r.Modules {
+		if m.Path == path {
+			return m, true
+		}
+	}
+	return ModuleInfo{}, false
+}
+
+type ModuleInfo struct {
+	Name         string      `json:"name"`
+	Path         string      `json:"path"`
+	Group        string      `json:"group,omitempty"`
+	Prefix       string      `json:"prefix"`
+	Source       string      `json:"source,omitempty"`
+	MustBePosted bool        `json:"mustbeposted,omitempty"`
+	WriteRights  bool        `json:"writerights,omitempty"`
+	Deprecated   bool        `json:"deprecated,omitempty"`
+	Internal     bool        `json:"internal,omitempty"`
+	Parameters   []ParamSpec `json:"parameters"`
+}
+
+// Param returns the parameter named name, without the module prefix.
+func (m *ModuleInfo) Param(name string) (ParamSpec, bool) {
+	for _, p := range m.Parameters {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return ParamSpec{}, false
+}
+
+// ParamSpec describes one parameter. Name lacks the module prefix. Type is
+// the value type (string, integer, boolean, timestamp, ...), or "enum" with
+// the accepted values in Values.
+type ParamSpec struct {
+	Name       string
+	Type       string
+	Values     []string
+	Required   bool
+	Multi      bool
+	Deprecated bool
+	// Default is the raw JSON default value, if any.
+	Default json.RawMessage
+	// Limit and HighLimit are the maximum number of values of a multi-value
+	// parameter, without and with apihighlimits.
+	Limit     int
+	HighLimit int
+	// Min and Max bound integer parameters; nil when unbounded.
+	Min *int64
+	Max *int64
+}
+
+func (p *ParamSpec) UnmarshalJSON(b []byte) error {
+	var v struct {
+		Name       string          `json:"name"`
+		Type       json.RawMessage `json:"type"`
+		Required   json.RawMessage `json:"required"`
+		Multi      json.RawMessage `json:"multi"`
+		Deprecated json.RawMessage `json:"deprecated"`
+		Default    json.RawMessage `json:"default"`
+		Limit      int             `json:"limit"`
+		HighLimit  int             `json:"highlimit"`
+		Min        *int64          `json:"min"`
+		Max        *int64          `json:"max"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*p = ParamSpec{
+		Name:       v.Name,
+		Required:   rawFlag(v.Required),
+		Multi:      rawFlag(v.Multi),
+		Deprecated: rawFlag(v.Deprecated),
+		Default:    v.Default,
+		Limit:      v.Limit,
+		HighLimit:  v.HighLimit,
+		Min:        v.Min,
+		Max:        v.Max,
+	}
+	// Enum parameters list their values in place of a type name.
+	if len(v.Type) > 0 && v.Type[0] == '[' {
+		p.Type = "enum"
+		return json.Unmarshal(v.Type, &p.Values)
+	}
+	if len(v.Type) > 0 {
+		return json.Unmarshal(v.Type, &p.Type)
+	}
+	return nil
+}
+
+type paramInfoCache struct {
+	mu      sync.Mutex
+	modules map[string]ModuleInfo
+}
+
+// ParamInfo describes the API modules at the given paths (e.g. "edit",
+// "query+revisions"). Results are cached per module for the life of the client.
+func (c *Client) ParamInfo(ctx context.Context, modules []string) (*ParamInfoResult, error) {
+	if len(modules) == 0 {
+		return nil, errors.New("paraminfo: missing modules")
+	}
+	c.paramInfo.mu.Lock()
+	var missing []string
+	for _, m := range modules {
+		if _, ok := c.paramInfo.modules[m]; !ok && !slices.Contains(missing, m) {
+			missing = append(missing, m)
+		}
+	}
+	c.paramInfo.mu.Unlock()
+
+	for start := 0; start < len(missing); start += maxTitlesPerQuery {
+		end := min(start+maxTitlesPerQuery, len(missing))
+		resp, err := c.Get(ctx, map[string]any{
+			"action":  "paraminfo",
+			"modules": missing[start:end],
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := apiError(resp); err != nil {
+			return nil, err
+		}
+		var out struct {
+			ParamInfo struct {
+				Modules []ModuleInfo `json:"modules"`
+			} `json:"paraminfo"`
+		}
+		if err := resp.Into(&out); err != nil {
+			return nil, err
+		}
+		c.paramInfo.mu.Lock()
+		if c.paramInfo.modules == nil {
+			c.paramInfo.modules = map[string]ModuleInfo{}
+		}
+		for _, m := range out.ParamInfo.Modules {
+			c.paramInfo.modules[m.Path] = m
+		}
+		c.paramInfo.mu.Unlock()
+	}
+
+	res := &ParamInfoResult{}
+	c.paramInfo.mu.Lock()
+	defer c.paramInfo.mu.Unlock()
+	for _, m := range modules {
+		if mi, ok := c.paramInfo.modules[m]; ok {
+			res.Modules = append(res.Modules, mi)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,65 @@
+package mwapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+func TestParamInfo(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("paraminfo", func(r *mwtest.Request) any {
+		var modules []any
+		if r.Param("modules") != "" {
+			modules = append(modules, map[string]any{
+				"name": "revisions", "path": "query+revisions", "group": "prop", "prefix": "rv",
+				"parameters": []any{
+					map[string]any{"name": "prop", "type": []string{"ids", "content"}, "multi": true,
+						"default": "ids", "limit": 50, "highlimit": 500},
+					map[string]any{"name": "limit", "type": "limit", "min": 1, "max": 500},
+					map[string]any{"name": "section", "type": "string", "deprecated": true},
+				},
+			})
+		}
+		return map[string]any{"paraminfo": map[string]any{"modules": modules}}
+	})
+
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	res, err := c.ParamInfo(ctx, []string{"query+revisions", "nosuchmodule"})
+	if err != nil {
+		t.Fatalf("ParamInfo: %v", err)
+	}
+	if len(res.Modules) != 1 {
+		t.Fatalf("modules = %+v, want query+revisions only", res.Modules)
+	}
+	m, ok := res.Module("query+revisions")
+	if !ok || m.Prefix != "rv" || m.Group != "prop" {
+		t.Fatalf("module = %+v", m)
+	}
+	prop, _ := m.Param("prop")
+	if prop.Type != "enum" || len(prop.Values) != 2 || !prop.Multi || prop.Limit != 50 || prop.HighLimit != 500 ||
+		string(prop.Default) != `"ids"` {
+		t.Fatalf("prop = %+v", prop)
+	}
+	limit, _ := m.Param("limit")
+	if limit.Type != "limit" || limit.Min == nil || *limit.Min != 1 || limit.Max == nil || *limit.Max != 500 {
+		t.Fatalf("limit = %+v", limit)
+	}
+	if section, _ := m.Param("section"); !section.Deprecated {
+		t.Fatalf("section = %+v, want deprecated", section)
+	}
+
+	if _, err := c.ParamInfo(ctx, []string{"query+revisions"}); err != nil {
+		t.Fatalf("ParamInfo: %v", err)
+	}
+	if n := len(wiki.RequestsFor("paraminfo")); n != 1 {
+		t.Fatalf("paraminfo requests = %d, want 1 (cached)", n)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/go-querystring/query"
 )
@@ -25,8 +26,9 @@ type fileField struct {
 }
 
 type normalizedParams struct {
-	Values url.Values
-	Files  []fileField
+	Values  url.Values
+	Files   []fileField
+	Streams []streamField
 }
 
 func normalizeParams(p any) (normalizedParams, error) {
@@ -67,6 +69,15 @@ func normalizeParams(p any) (normalizedParams, error) {
 			if err != nil {
 				return normalizedParams{}, err
 			}
+			// MediaWiki treats any present boolean as true ("minor=false" is a minor edit),
+			// so mirror the map path: true -> "1", false -> omitted.
+			for name, val := range structBoolFields(rv) {
+				if val {
+					values.Set(name, "1")
+				} else {
+					values.Del(name)
+				}
+			}
 			for k, vs := range values {
 				if len(vs) == 0 {
 					continue
@@ -86,6 +97,53 @@ func normalizeParams(p any) (normalizedParams, error) {
 	return np, nil
 }
 
+// WithParamRewriter adds fn to the rewriters run on the final parameters of
+// every request, after defaults, just before sending, e.g. to add a tag to all
+// edits or strip a parameter policy forbids. Rewriters run in the order added.
+// They see every request, including action=login and token fetches, so they
+// must leave fields such as lgpassword and token alone.
+func WithParamRewriter(fn func(action string, v url.Values)) Option {
+	return func(c *Client) {
+		if fn != nil {
+			c.paramRewriters = append(c.paramRewriters, fn)
+		}
+	}
+}
+
+// WithActionDefaults sets parameters sent with every request of action (e.g.
+// redirects=1 for query) unless the request sets them itself. Calling it again
+// for the same action adds to, and overrides, the earlier defaults.
+func WithActionDefaults(action string, params map[string]any) Option {
+	return func(c *Client) {
+		action = strings.ToLower(strings.TrimSpace(action))
+		if action == "" || len(params) == 0 {
+			return
+		}
+		if c.actionDefaults == nil {
+			c.actionDefaults = map[string]map[string]any{}
+		}
+		if c.actionDefaults[action] == nil {
+			c.actionDefaults[action] = map[string]any{}
+		}
+		for k, v := range params {
+			c.actionDefaults[action][k] = v
+		}
+	}
+}
+
+func (c *Client) applyActionDefaults(np *normalizedParams) error {
+	defaults := c.actionDefaults[strings.ToLower(np.Values.Get("action"))]
+	for k, v := range defaults {
+		if np.Values.Has(k) {
+			continue
+		}
+		if err := addAny(np, k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func setDefaultIfMissing(v url.Values, key, value string) {
 	if v.Get(key) == "" {
 		v.Set(key, value)
@@ -101,6 +159,11 @@ func addAny(np *normalizedParams, key string, val any) error {
 	case string:
 		np.Values.Set(key, x)
 		return nil
+	case *Stream:
+		if x != nil {
+			np.Streams = append(np.Streams, streamField{Field: key, Stream: x})
+		}
+		return nil
 	case []byte:
 		np.Files = append(np.Files, fileField{
 			Field: key,
@@ -145,6 +208,27 @@ func addAny(np *normalizedParams, key string, val any) error {
 		}
 		np.Values.Set(key, strings.Join(x, "|"))
 		return nil
+	case []int:
+		if len(x) == 0 {
+			return nil
+		}
+		parts := make([]string, len(x))
+		for i, n := range x {
+			parts[i] = strconv.Itoa(n)
+		}
+		np.Values.Set(key, strings.Join(parts, "|"))
+		return nil
+	case []int64:
+		// IDs (pageids, revids) are exact integers; never let them pass through float formatting.
+		if len(x) == 0 {
+			return nil
+		}
+		parts := make([]string, len(x))
+		for i, n := range x {
+			parts[i] = strconv.FormatInt(n, 10)
+		}
+		np.Values.Set(key, strings.Join(parts, "|"))
+		return nil
 	case []any:
 		if len(x) == 0 {
 			return nil
@@ -160,6 +244,21 @@ func addAny(np *normalizedParams, key string, val any) error {
 			np.Values.Set(key, strings.Join(parts, "|"))
 		}
 		return nil
+	case time.Time:
+		if !x.IsZero() {
+			np.Values.Set(key, formatISOTimestamp(x))
+		}
+		return nil
+	case *time.Time:
+		if x != nil && !x.IsZero() {
+			np.Values.Set(key, formatISOTimestamp(*x))
+		}
+		return nil
+	case MWTime:
+		if !time.Time(x).IsZero() {
+			np.Values.Set(key, x.String())
+		}
+		return nil
 	case fmt.Stringer:
 		np.Values.Set(key, x.String())
 		return nil
@@ -199,3 +298,66 @@ func addAny(np *normalizedParams, key string, val any) error {
 		}
 	}
 }
+
+// structBoolFields maps the url names of bool (and non-nil *bool) fields of a
+// struct to their values, following go-querystring's naming rules.
+func structBoolFields(rv reflect.Value) map[string]bool {
+	out := map[string]bool{}
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue
+		}
+		tag := sf.Tag.Get("url")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		fv := rv.Field(i)
+		if fv.Kind() == reflect.Pointer {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+		if sf.Anonymous && name == "" && fv.Kind() == reflect.Struct {
+			for k, v := range structBoolFields(fv) {
+				out[k] = v
+			}
+			continue
+		}
+		if fv.Kind() != reflect.Bool {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		out[name] = fv.Bool()
+	}
+	return out
+}
+
+// knownBooleanParams are core parameters MediaWiki treats as booleans.
+var knownBooleanParams = []string{
+	"bot", "cascade", "converttitles", "createonly", "curtimestamp", "export",
+	"exportnowrap", "fullhistory", "ignorewarnings", "includecomments", "indexpageids",
+	"markbot", "minor", "movesubpages", "movetalk", "nocreate", "noimageredirect",
+	"notminor", "noredirect", "rawcontinue", "recreate", "redirects", "unwatch",
+}
+
+// dropFalseyBooleans removes known boolean params whose string value reads as
+// false ("false", "0", "no", "off"), since sending them at all means true.
+func dropFalseyBooleans(v url.Values) {
+	for _, k := range knownBooleanParams {
+		vs, ok := v[k]
+		if !ok || len(vs) == 0 {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(vs[0])) {
+		case "false", "0", "no", "off":
+			v.Del(k)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package mwapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+func TestNormalizeParams_FalseBooleansOmitted(t *testing.T) {
+	t.Parallel()
+
+	np, err := normalizeParams(map[string]any{"action": "edit", "minor": false, "bot": true})
+	if err != nil {
+		t.Fatalf("normalizeParams(map): %v", err)
+	}
+	if np.Values.Has("minor") || np.Values.Get("bot") != "1" {
+		t.Fatalf("map path: %v", np.Values)
+	}
+
+	type common struct {
+		Bot bool `url:"bot"`
+	}
+	type editParams struct {
+		common
+		Action    string `url:"action"`
+		Title     string `url:"title"`
+		Minor     bool   `url:"minor"`
+		NoCreate  bool   `url:"nocreate,omitempty"`
+		Watch     *bool  `url:"watch"`
+		Recreate  bool
+		Untouched string `url:"-"`
+	}
+	yes := true
+	np, err = normalizeParams(editParams{
+		common: common{Bot: false},
+		Action: "edit",
+		Title:  "Sandbox",
+		Minor:  false,
+		Watch:  &yes,
+	})
+	if err != nil {
+		t.Fatalf("normalizeParams(struct): %v", err)
+	}
+	for _, k := range []string{"minor", "bot", "nocreate", "Recreate"} {
+		if np.Values.Has(k) {
+			t.Fatalf("struct path sent false boolean %s=%q", k, np.Values.Get(k))
+		}
+	}
+	if np.Values.Get("watch") != "1" || np.Values.Get("title") != "Sandbox" {
+		t.Fatalf("struct path: %v", np.Values)
+	}
+}
+
+func TestStrictBooleans_DropsFalseyStrings(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("edit", func(r *mwtest.Request) any {
+		return map[string]any{"edit": map[string]any{"result": "Success"}}
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	params := map[string]any{"action": "edit", "title": "X", "minor": "false", "bot": "0", "summary": "false"}
+
+	c := New(wiki.URL())
+	if _, err := c.PostWithToken(ctx, TokenCSRF, params, nil); err != nil {
+		t.Fatalf("PostWithToken: %v", err)
+	}
+	wiki.AssertSent("edit", "minor", "false")
+
+	c = New(wiki.URL(), WithStrictBooleans(true))
+	if _, err := c.PostWithToken(ctx, TokenCSRF, params, nil); err != nil {
+		t.Fatalf("PostWithToken: %v", err)
+	}
+	wiki.AssertNotSent("edit", "minor")
+	wiki.AssertNotSent("edit", "bot")
+	wiki.AssertSent("edit", "summary", "false")
+}
+
+func TestNormalizeParams_IntegerIDs(t *testing.T) {
+	t.Parallel()
+
+	// 2^53 + 1 is not representable as float64; it must survive encoding unchanged.
+	const big int64 = 9007199254740993
+	np, err := normalizeParams(map[string]any{
+		"pageids": []int64{1, 42, big},
+		"revids":  []int{7, 8},
+		"oldid":   big,
+		"fromid":  int32(5),
+		"toid":    []uint32{9},
+		"empty":   []int64{},
+	})
+	if err != nil {
+		t.Fatalf("normalizeParams: %v", err)
+	}
+	for k, want := range map[string]string{
+		"pageids": "1|42|9007199254740993",
+		"revids":  "7|8",
+		"oldid":   "9007199254740993",
+		"fromid":  "5",
+		"toid":    "9",
+	} {
+		if got := np.Values.Get(k); got != want {
+			t.Fatalf("%s = %q, want %q", k, got, want)
+		}
+	}
+	if np.Values.Has("empty") {
+		t.Fatalf("empty id list was sent")
+	}
+}
+
+func TestParamRewriter_TagsEditsOnly(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("edit", func(r *mwtest.Request) any {
+		return map[string]any{"edit": map[string]any{"result": "Success"}}
+	})
+	wiki.Handle("parse", func(r *mwtest.Request) any {
+		return map[string]any{"parse": map[string]any{}}
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	var seen []string
+	c := New(wiki.URL(),
+		WithParamRewriter(func(action string, v url.Values) {
+			seen = append(seen, action)
+		}),
+		WithParamRewriter(func(action string, v url.Values) {
+			if action == "edit" {
+				v.Set("tags", "fleet-bot")
+			}
+		}),
+	)
+	if _, err := c.PostWithToken(ctx, TokenCSRF, map[string]any{"action": "edit", "title": "X", "text": "y"}, nil); err != nil {
+		t.Fatalf("PostWithToken: %v", err)
+	}
+	if _, err := c.Get(ctx, map[string]any{"action": "parse", "text": "y"}); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	wiki.AssertSent("edit", "tags", "fleet-bot")
+	wiki.AssertNotSent("parse", "tags")
+	if len(seen) != 3 || seen[0] != "query" {
+		t.Fatalf("rewriter saw %v, want token query, edit and parse", seen)
+	}
+}
+
+func TestNormalizeParams_CallerFormatWins(t *testing.T) {
+	t.Parallel()
+
+	type debugParams struct {
+		Action        string `url:"action"`
+		Format        string `url:"format"`
+		FormatVersion string `url:"formatversion"`
+	}
+	np, err := normalizeParams(debugParams{Action: "query", Format: "jsonfm", FormatVersion: "1"})
+	if err != nil {
+		t.Fatalf("normalizeParams(struct): %v", err)
+	}
+	if np.Values.Get("format") != "jsonfm" || np.Values.Get("formatversion") != "1" {
+		t.Fatalf("struct path: %v", np.Values)
+	}
+
+	np, err = normalizeParams(map[string]any{"format": "xml"})
+	if err != nil {
+		t.Fatalf("normalizeParams(map): %v", err)
+	}
+	if np.Values.Get("format") != "xml" || np.Values.Get("formatversion") != "2" {
+		t.Fatalf("map path: %v", np.Values)
+	}
+}
+
+func TestNonJSONFormat_ReturnsRawBody(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("format") {
+		case "xml":
+			w.Header().Set("Content-Type", "text/xml")
+			_, _ = io.WriteString(w, `<?xml version="1.0"?><api batchcomplete=""/>`)
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = io.WriteString(w, `<pre>{"batchcomplete": true}</pre>`)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	c := New(srv.URL + "/api.php")
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	for _, format := range []string{"xml", "jsonfm"} {
+		resp, err := c.Get(ctx, map[string]any{"meta": "siteinfo", "format": format})
+		if !errors.Is(err, ErrNonJSONFormat) {
+			t.Fatalf("format=%s: err = %v, want ErrNonJSONFormat", format, err)
+		}
+		if resp == nil || len(resp.Raw) == 0 || resp.Raw[0] != '<' {
+			t.Fatalf("format=%s: raw body not returned", format)
+		}
+	}
+}
+
+func TestActionDefaults_OnlyMatchingAction(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("query", func(r *mwtest.Request) any {
+		return map[string]any{"batchcomplete": true}
+	})
+	wiki.Handle("parse", func(r *mwtest.Request) any {
+		return map[string]any{"parse": map[string]any{}}
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	c := New(wiki.URL(), WithActionDefaults("Query", map[string]any{"redirects": true, "converttitles": true}))
+	if _, err := c.Get(ctx, map[string]any{"titles": "A", "converttitles": "0"}); err != nil {
+		t.Fatalf("Get(query): %v", err)
+	}
+	wiki.AssertSent("query", "redirects", "1")
+	wiki.AssertSent("query", "converttitles", "0")
+
+	if _, err := c.Get(ctx, map[string]any{"action": "parse", "page": "A"}); err != nil {
+		t.Fatalf("Get(parse): %v", err)
+	}
+	wiki.AssertNotSent("parse", "redirects")
+}
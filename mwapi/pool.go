@@ -0,0 +1,77 @@
+package mwapi
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+)
+
+// Clone returns a client with the same endpoint, options and HTTP transport
+// (so connections are pooled), but with its own cookie jar, token cache and
+// login state. A login on the clone never affects c, and vice versa.
+func (c *Client) Clone() (*Client, error) {
+	jar, err := c.newJar()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Options live in clientConfig, so a copy carries all of them; only the
+	// mutable parts are deep-copied below.
+	n := &Client{clientConfig: c.clientConfig}
+	u := *c.endpoint
+	n.endpoint = &u
+	n.writeActions = maps.Clone(c.writeActions)
+	n.forcePostActions = maps.Clone(c.forcePostActions)
+	if c.actionDefaults != nil {
+		n.actionDefaults = make(map[string]map[string]any, len(c.actionDefaults))
+		for a, params := range c.actionDefaults {
+			n.actionDefaults[a] = maps.Clone(params)
+		}
+	}
+	n.paramRewriters = slices.Clone(c.paramRewriters)
+
+	// Per-session state starts afresh, keeping the settings stored with it.
+	n.hc = &http.Client{
+		Transport:     c.hc.Transport,
+		CheckRedirect: c.hc.CheckRedirect,
+		Timeout:       c.hc.Timeout,
+		Jar:           jar,
+	}
+	n.writePacer.interval = c.writePacer.interval
+	n.reloginGuard.max = c.reloginGuard.max
+	n.tagCache.ttl = c.tagCache.ttl
+	if c.stats != nil {
+		n.stats = &statsCounters{}
+	}
+	// The clone gets a new cookie jar, hence a new session: never share tokens.
+	n.loginTokens = NewTokenCache()
+	n.tokens = n.loginTokens
+	return n, nil
+}
+
+// NewClientPool creates n clients for a worker pool where each worker logs in
+// as its own user. See Clone for what is shared between them.
+func NewClientPool(endpoint string, n int, opts ...Option) ([]*Client, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("invalid pool size: %d", n)
+	}
+	first, err := NewClient(endpoint, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := make([]*Client, 0, n)
+	pool = append(pool, first)
+	for i := 1; i < n; i++ {
+		c, err := first.Clone()
+		if err != nil {
+			return nil, err
+		}
+		pool = append(pool, c)
+	}
+	return pool, nil
+}
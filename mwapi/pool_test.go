@@ -0,0 +1,108 @@
+package mwapi
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+type countingTransport struct {
+	n atomic.Int32
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.n.Add(1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestClientPool_IsolatesSessions(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("edit", func(r *mwtest.Request) any {
+		return map[string]any{"edit": map[string]any{"result": "Success", "title": r.Param("title")}}
+	})
+
+	rt := &countingTransport{}
+	pool, err := NewClientPool(wiki.URL(), 2, WithTransport(rt))
+	if err != nil {
+		t.Fatalf("NewClientPool: %v", err)
+	}
+	if pool[0].hc.Jar == pool[1].hc.Jar {
+		t.Fatalf("pooled clients share a cookie jar")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	users := []string{"UserA", "UserB"}
+	tokens := make([]string, len(pool))
+	var wg sync.WaitGroup
+	for i, c := range pool {
+		wg.Add(1)
+		go func(i int, c *Client) {
+			defer wg.Done()
+			if _, err := c.Login(ctx, users[i], "pass"); err != nil {
+				t.Errorf("Login(%s): %v", users[i], err)
+				return
+			}
+			tok, err := c.GetToken(ctx, TokenCSRF)
+			if err != nil {
+				t.Errorf("GetToken(%s): %v", users[i], err)
+				return
+			}
+			tokens[i] = tok
+			if _, err := c.PostWithToken(ctx, TokenCSRF, map[string]any{
+				"action": "edit",
+				"title":  "User:" + users[i],
+			}, nil); err != nil {
+				t.Errorf("edit(%s): %v", users[i], err)
+			}
+		}(i, c)
+	}
+	wg.Wait()
+	if t.Failed() {
+		return
+	}
+
+	if tokens[0] == tokens[1] {
+		t.Fatalf("pooled clients got the same csrf token %q", tokens[0])
+	}
+	for _, r := range wiki.RequestsFor("edit") {
+		if r.Param("title") != "User:"+r.User {
+			t.Fatalf("edit of %s was made as %q", r.Param("title"), r.User)
+		}
+	}
+	if got, want := int(rt.n.Load()), len(wiki.Requests()); got != want {
+		t.Fatalf("shared transport saw %d requests, want %d", got, want)
+	}
+}
+
+func TestClone_KeepsOptionsResetsSession(t *testing.T) {
+	t.Parallel()
+
+	c := New("https://wiki.example/api.php",
+		WithUserAgent("bot/1.0"), WithRequestIDHeader("X-Trace"),
+		WithOverallDeadline(time.Minute), WithMinWriteInterval(time.Second))
+	c.loggedInUser = "Alice"
+	clone, err := c.Clone()
+	if err != nil {
+		t.Fatalf("Clone: %v", err)
+	}
+	if clone.ua != "bot/1.0" || clone.requestIDHeader != "X-Trace" ||
+		clone.overallDeadline != time.Minute || clone.writePacer.interval != time.Second {
+		t.Fatalf("clone lost options: %+v", clone.clientConfig)
+	}
+	if clone.loggedInUser != "" || clone.tokens == c.tokens {
+		t.Fatalf("clone shares session state")
+	}
+	clone.writeActions["purge"] = struct{}{}
+	if _, ok := c.writeActions["purge"]; ok {
+		t.Fatalf("clone shares the write action set")
+	}
+}
@@ -0,0 +1,129 @@
+package mwapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Protection is one restriction of a page. Level "all" removes the restriction.
+type Protection struct {
+	Type   string // edit, move, upload, create
+	Level  string // sysop, autoconfirmed, all, ...
+	Expiry string // "infinite", "1 week", an ISO 8601 timestamp; empty means infinite
+}
+
+type ProtectParams struct {
+	Title       string
+	PageID      int64
+	Protections []Protection
+	Reason      string
+	Cascade     bool
+}
+
+type ProtectResult struct {
+	Title       string
+	Reason      string
+	Cascade     bool
+	Protections []Protection
+}
+
+// Protect changes the protection levels of a page (action=protect). Failures
+// with code invalidlevel or cantedit match ErrInvalidLevel and ErrCantEdit.
+func (c *Client) Protect(ctx context.Context, params ProtectParams) (*ProtectResult, error) {
+	if params.Title == "" && params.PageID == 0 {
+		return nil, errors.New("protect: missing title or page id")
+	}
+	if len(params.Protections) == 0 {
+		return nil, errors.New("protect: no protections given")
+	}
+
+	levels := make([]string, 0, len(params.Protections))
+	expiries := make([]string, 0, len(params.Protections))
+	for _, pr := range params.Protections {
+		if pr.Type == "" || pr.Level == "" {
+			return nil, fmt.Errorf("protect: incomplete protection %+v", pr)
+		}
+		levels = append(levels, pr.Type+"="+pr.Level)
+		expiry := pr.Expiry
+		if expiry == "" {
+			expiry = "infinite"
+		}
+		expiries = append(expiries, expiry)
+	}
+
+	p := map[string]any{
+		"action":      "protect",
+		"protections": levels,
+		"expiry":      expiries,
+		"cascade":     params.Cascade,
+	}
+	if params.PageID != 0 {
+		p["pageid"] = params.PageID
+	} else {
+		p["title"] = params.Title
+	}
+	if params.Reason != "" {
+		p["reason"] = params.Reason
+	}
+
+	resp, err := c.PostWithToken(ctx, TokenCSRF, p, nil)
+	if err != nil {
+		return nil, fmt.Errorf("protect: %w", err)
+	}
+	if err := apiError(resp); err != nil {
+		return nil, fmt.Errorf("protect: %w", err)
+	}
+
+	var out struct {
+		Protect *struct {
+			Title       string                       `json:"title"`
+			Reason      string                       `json:"reason"`
+			Cascade     json.RawMessage              `json:"cascade"`
+			Protections []map[string]json.RawMessage `json:"protections"`
+		} `json:"protect"`
+	}
+	if err := resp.Into(&out); err != nil {
+		return nil, err
+	}
+	if out.Protect == nil {
+		return nil, errors.New("missing protect in response")
+	}
+
+	res := &ProtectResult{
+		Title:   out.Protect.Title,
+		Reason:  out.Protect.Reason,
+		Cascade: rawFlag(out.Protect.Cascade),
+	}
+	// Each entry is {"<type>": "<level>", "expiry": "..."}.
+	for _, m := range out.Protect.Protections {
+		var pr Protection
+		for k, v := range m {
+			var s string
+			if err := json.Unmarshal(v, &s); err != nil {
+				continue
+			}
+			if strings.EqualFold(k, "expiry") {
+				pr.Expiry = s
+			} else {
+				pr.Type, pr.Level = k, s
+			}
+		}
+		res.Protections = append(res.Protections, pr)
+	}
+	return res, nil
+}
+
+// Unprotect removes the given restriction types (default edit and move) from title.
+func (c *Client) Unprotect(ctx context.Context, title, reason string, types ...string) (*ProtectResult, error) {
+	if len(types) == 0 {
+		types = []string{"edit", "move"}
+	}
+	prs := make([]Protection, 0, len(types))
+	for _, t := range types {
+		prs = append(prs, Protection{Type: t, Level: "all"})
+	}
+	return c.Protect(ctx, ProtectParams{Title: title, Protections: prs, Reason: reason})
+}
@@ -0,0 +1,80 @@
+package mwapi
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+func TestProtect(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("protect", func(r *mwtest.Request) any {
+		var applied []any
+		expiries := strings.Split(r.Param("expiry"), "|")
+		for i, spec := range strings.Split(r.Param("protections"), "|") {
+			typ, level, _ := strings.Cut(spec, "=")
+			if level != "sysop" && level != "autoconfirmed" && level != "all" {
+				return mwtest.ErrorResponse("invalidlevel", "Invalid protection level \""+level+"\".")
+			}
+			if level == "all" {
+				level = ""
+			}
+			applied = append(applied, map[string]any{typ: level, "expiry": expiries[i]})
+		}
+		return map[string]any{"protect": map[string]any{
+			"title":       r.Param("title"),
+			"reason":      r.Param("reason"),
+			"cascade":     r.Param("cascade") == "1",
+			"protections": applied,
+		}}
+	})
+
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+	if _, err := c.Login(ctx, "Admin", "pass"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+
+	res, err := c.Protect(ctx, ProtectParams{
+		Title: "Main Page",
+		Protections: []Protection{
+			{Type: "edit", Level: "sysop"},
+			{Type: "move", Level: "sysop", Expiry: "1 week"},
+		},
+		Reason:  "High-traffic page",
+		Cascade: true,
+	})
+	if err != nil {
+		t.Fatalf("Protect: %v", err)
+	}
+	if !res.Cascade || len(res.Protections) != 2 ||
+		res.Protections[0] != (Protection{Type: "edit", Level: "sysop", Expiry: "infinite"}) ||
+		res.Protections[1] != (Protection{Type: "move", Level: "sysop", Expiry: "1 week"}) {
+		t.Fatalf("result = %+v", res)
+	}
+	wiki.AssertSent("protect", "protections", "edit=sysop|move=sysop")
+	wiki.AssertSent("protect", "expiry", "infinite|1 week")
+	wiki.AssertSent("protect", "cascade", "1")
+
+	res, err = c.Unprotect(ctx, "Main Page", "")
+	if err != nil {
+		t.Fatalf("Unprotect: %v", err)
+	}
+	if res.Cascade || len(res.Protections) != 2 || res.Protections[0].Level != "" {
+		t.Fatalf("clear result = %+v", res)
+	}
+	wiki.AssertSent("protect", "protections", "edit=all|move=all")
+	wiki.AssertNotSent("protect", "cascade")
+
+	_, err = c.Protect(ctx, ProtectParams{Title: "Main Page", Protections: []Protection{{Type: "edit", Level: "bureaucrat"}}})
+	if !errors.Is(err, ErrInvalidLevel) {
+		t.Fatalf("err = %v, want ErrInvalidLevel", err)
+	}
+}
@@ -0,0 +1,255 @@
+package mwapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"strconv"
+)
+
+// errStopIteration is returned by page callbacks when the consumer of an
+// iterator stops early; it never escapes to callers.
+var errStopIteration = errors.New("stop iteration")
+
+// QueryEach runs p and calls fn with every page of results, following the
+// continue protocol until the query is complete. An error from fn, a failed
+// request or a canceled ctx ends it. p itself is never modified.
+func (c *Client) QueryEach(ctx context.Context, p map[string]any, fn func(*Response) error) error {
+	return c.queryContinue(ctx, p, nil, fn)
+}
+
+// WithRawContinue makes the continuation helpers use the legacy protocol:
+// rawcontinue=1 is sent and the next request is built from query-continue.
+// Only needed for very old wikis and modules without continue support.
+func WithRawContinue(v bool) Option {
+	return func(c *Client) {
+		c.rawContinue = v
+	}
+}
+
+// queryContinue runs p and follows the continue protocol, calling fn with every page.
+// The caller's map is never modified; only continuation keys change between requests.
+// If before is non-nil it may adjust the parameters ahead of every request.
+func (c *Client) queryContinue(ctx context.Context, p map[string]any, before func(map[string]any), fn func(*Response) error) error {
+	params := make(map[string]any, len(p))
+	for k, v := range p {
+		params[k] = v
+	}
+	if c.rawContinue {
+		params["rawcontinue"] = true
+	}
+
+	var prevKeys []string
+	for {
+		if ctx.Err() != nil {
+			return canceled(ctx, nil)
+		}
+
+		if before != nil {
+			before(params)
+		}
+		resp, err := c.Get(ctx, params)
+		if err != nil {
+			return err
+		}
+		if err := apiError(resp); err != nil {
+			return err
+		}
+		if err := fn(resp); err != nil {
+			return err
+		}
+
+		readCont := continueParams
+		if c.rawContinue {
+			readCont = rawContinueParams
+		}
+		cont, err := readCont(resp)
+		if err != nil {
+			return err
+		}
+		if len(cont) == 0 {
+			return nil
+		}
+		for _, k := range prevKeys {
+			delete(params, k)
+		}
+		prevKeys = prevKeys[:0]
+		for k, v := range cont {
+			params[k] = v
+			prevKeys = append(prevKeys, k)
+		}
+	}
+}
+
+// continueParams reads the continue object from the raw body. Some modules use
+// numeric continuation values (e.g. sroffset), which Envelope.Continue cannot hold.
+func continueParams(resp *Response) (map[string]string, error) {
+	var r struct {
+		Continue map[string]json.RawMessage `json:"continue"`
+	}
+	if err := resp.Into(&r); err != nil {
+		return nil, err
+	}
+	out := make(map[string]string, len(r.Continue))
+	if err := addContinueValues(out, r.Continue); err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
+
+// rawContinueParams merges the per-module objects of a legacy query-continue
+// (e.g. {"allpages": {"apcontinue": "B"}}) into the parameters to send next.
+func rawContinueParams(resp *Response) (map[string]string, error) {
+	var r struct {
+		QueryContinue map[string]map[string]json.RawMessage `json:"query-continue"`
+	}
+	if err := resp.Into(&r); err != nil {
+		return nil, err
+	}
+	out := map[string]string{}
+	for _, module := range r.QueryContinue {
+		if err := addContinueValues(out, module); err != nil {
+			return nil, err
+		}
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
+
+func addContinueValues(out map[string]string, values map[string]json.RawMessage) error {
+	for k, raw := range values {
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			out[k] = s
+			continue
+		}
+		var n json.Number
+		if err := json.Unmarshal(raw, &n); err != nil {
+			return err
+		}
+		out[k] = n.String()
+	}
+	return nil
+}
+
+// QueryList iterates list=<list> (allpages, categorymembers, ...), decoding
+// each entry of query.<list> as T and following continuation until the list
+// ends or the loop breaks. params holds the list's own parameters, e.g.
+// cmtitle; action and list are set from the arguments. A failed request is
+// yielded once as (zero, err) and ends the sequence.
+func QueryList[T any](ctx context.Context, c *Client, list string, params map[string]any) iter.Seq2[T, error] {
+	if list == "" {
+		return failSeq[T](errors.New("query list: missing list"))
+	}
+	p := make(map[string]any, len(params)+2)
+	for k, v := range params {
+		p[k] = v
+	}
+	p["action"] = "query"
+	p["list"] = list
+	return queryItems(ctx, c, p, listItems[T](list), queryOptions{})
+}
+
+// queryItems turns a continued query into a stream of items extracted from each page.
+// A failed request is yielded once as (zero, err) and ends the sequence.
+// With WithLimit the stream ends after that many items, and qo.limitParam (if
+// set) is lowered to the number still wanted so the last batch is not overfetched.
+func queryItems[T any](ctx context.Context, c *Client, p map[string]any, extract func(*Response) ([]T, error), qo queryOptions) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		remaining := qo.limit
+		var before func(map[string]any)
+		if qo.limit > 0 && qo.limitParam != "" {
+			before = func(params map[string]any) {
+				capLimit(params, qo.limitParam, remaining)
+			}
+		}
+		err := c.queryContinue(ctx, p, before, func(resp *Response) error {
+			items, err := extract(resp)
+			if err != nil {
+				return err
+			}
+			for _, it := range items {
+				if !yield(it, nil) {
+					return errStopIteration
+				}
+				if qo.limit > 0 {
+					if remaining--; remaining == 0 {
+						return errStopIteration
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errStopIteration) {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
+
+// listItems extracts query.<list> as []T.
+func listItems[T any](list string) func(*Response) ([]T, error) {
+	return func(resp *Response) ([]T, error) {
+		var out struct {
+			Query map[string]json.RawMessage `json:"query"`
+		}
+		if err := resp.Into(&out); err != nil {
+			return nil, err
+		}
+		raw, ok := out.Query[list]
+		if !ok {
+			return nil, nil
+		}
+		var items []T
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil, err
+		}
+		return items, nil
+	}
+}
+
+// pagePropItems extracts query.pages[].<prop> as []T, concatenated across pages.
+func pagePropItems[T any](prop string) func(*Response) ([]T, error) {
+	return func(resp *Response) ([]T, error) {
+		var out struct {
+			Query struct {
+				Pages []map[string]json.RawMessage `json:"pages"`
+			} `json:"query"`
+		}
+		if err := resp.Into(&out); err != nil {
+			return nil, err
+		}
+		var items []T
+		for _, pg := range out.Query.Pages {
+			raw, ok := pg[prop]
+			if !ok {
+				continue
+			}
+			var part []T
+			if err := json.Unmarshal(raw, &part); err != nil {
+				return nil, err
+			}
+			items = append(items, part...)
+		}
+		return items, nil
+	}
+}
+
+// capLimit lowers params[key] ("max" or a number) to at most n.
+func capLimit(params map[string]any, key string, n int) {
+	v, ok := params[key]
+	if !ok {
+		return
+	}
+	if cur, err := strconv.Atoi(fmt.Sprint(v)); err == nil && cur <= n {
+		return
+	}
+	params[key] = n
+}
@@ -0,0 +1,186 @@
+package mwapi
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+func TestQueryEach_FollowsContinue(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("query", func(r *mwtest.Request) any {
+		switch {
+		case r.Param("rccontinue") == "" && r.Param("continue") == "":
+			return map[string]any{
+				"continue": map[string]any{"rccontinue": "20261017|2", "continue": "-||"},
+				"query":    map[string]any{"recentchanges": []any{map[string]any{"rcid": 1}}},
+			}
+		case r.Param("rccontinue") == "20261017|2":
+			// A different continuation key: rccontinue must not be carried over.
+			return map[string]any{
+				"continue": map[string]any{"rcstart": "x", "continue": "-||"},
+				"query":    map[string]any{"recentchanges": []any{map[string]any{"rcid": 2}}},
+			}
+		case r.Param("rcstart") == "x" && r.Param("rccontinue") == "":
+			return map[string]any{
+				"batchcomplete": true,
+				"query":         map[string]any{"recentchanges": []any{map[string]any{"rcid": 3}}},
+			}
+		}
+		return mwtest.ErrorResponse("badcontinue", "Invalid continue param.")
+	})
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	p := map[string]any{"list": "recentchanges", "rclimit": 1}
+	pages := 0
+	if err := c.QueryEach(ctx, p, func(resp *Response) error {
+		pages++
+		return nil
+	}); err != nil {
+		t.Fatalf("QueryEach: %v", err)
+	}
+	if pages != 3 {
+		t.Fatalf("pages = %d, want 3", pages)
+	}
+	if len(p) != 2 {
+		t.Fatalf("caller's params modified: %v", p)
+	}
+
+	stop := errors.New("stop")
+	pages = 0
+	err := c.QueryEach(ctx, p, func(resp *Response) error {
+		pages++
+		return stop
+	})
+	if !errors.Is(err, stop) || pages != 1 {
+		t.Fatalf("err = %v after %d pages, want fn's error after 1", err, pages)
+	}
+
+	canceled, cancelNow := context.WithCancel(ctx)
+	cancelNow()
+	if err := c.QueryEach(canceled, p, func(*Response) error { return nil }); !errors.Is(err, context.Canceled) ||
+		!errors.Is(err, ErrContextCanceled) {
+		t.Fatalf("err = %v, want ErrContextCanceled wrapping context.Canceled", err)
+	}
+}
+
+func TestQueryEach_RawContinue(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("query", func(r *mwtest.Request) any {
+		if r.Param("rawcontinue") != "1" {
+			return mwtest.ErrorResponse("badcontinue", "rawcontinue missing")
+		}
+		switch r.Param("apcontinue") {
+		case "":
+			return map[string]any{
+				"query-continue": map[string]any{"allpages": map[string]any{"apcontinue": "B"}},
+				"query":          map[string]any{"allpages": []any{map[string]any{"title": "A"}}},
+			}
+		case "B":
+			return map[string]any{
+				"query-continue": map[string]any{"allpages": map[string]any{"apcontinue": "C", "apoffset": 2}},
+				"query":          map[string]any{"allpages": []any{map[string]any{"title": "B"}}},
+			}
+		case "C":
+			if r.Param("apoffset") != "2" {
+				return mwtest.ErrorResponse("badcontinue", "apoffset missing")
+			}
+			return map[string]any{"query": map[string]any{"allpages": []any{map[string]any{"title": "C"}}}}
+		}
+		return mwtest.ErrorResponse("badcontinue", "Invalid continue param.")
+	})
+	c := New(wiki.URL(), WithRawContinue(true))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	var titles []string
+	err := c.QueryEach(ctx, map[string]any{"list": "allpages"}, func(resp *Response) error {
+		var out struct {
+			Query struct {
+				AllPages []struct {
+					Title string `json:"title"`
+				} `json:"allpages"`
+			} `json:"query"`
+		}
+		if err := resp.Into(&out); err != nil {
+			return err
+		}
+		for _, p := range out.Query.AllPages {
+			titles = append(titles, p.Title)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("QueryEach: %v", err)
+	}
+	if got := strings.Join(titles, ","); got != "A,B,C" {
+		t.Fatalf("titles = %s, want A,B,C", got)
+	}
+}
+
+func TestQueryList(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("query", func(r *mwtest.Request) any {
+		if r.Param("list") != "categorymembers" || r.Param("cmtitle") != "Category:A" {
+			return mwtest.ErrorResponse("badtest", "unexpected params")
+		}
+		if r.Param("cmcontinue") == "" {
+			return map[string]any{
+				"continue": map[string]any{"cmcontinue": "page|2", "continue": "-||"},
+				"query": map[string]any{"categorymembers": []any{
+					map[string]any{"pageid": 1, "ns": 0, "title": "One"},
+					map[string]any{"pageid": 2, "ns": 0, "title": "Two"},
+				}},
+			}
+		}
+		return map[string]any{"batchcomplete": true, "query": map[string]any{"categorymembers": []any{
+			map[string]any{"pageid": 3, "ns": 0, "title": "Three"},
+		}}}
+	})
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	type member struct {
+		PageID int64  `json:"pageid"`
+		Title  string `json:"title"`
+	}
+	var titles []string
+	for m, err := range QueryList[member](ctx, c, "categorymembers", map[string]any{"cmtitle": "Category:A"}) {
+		if err != nil {
+			t.Fatalf("QueryList: %v", err)
+		}
+		titles = append(titles, m.Title)
+	}
+	if strings.Join(titles, ",") != "One,Two,Three" {
+		t.Fatalf("titles = %v", titles)
+	}
+
+	before := len(wiki.RequestsFor("query"))
+	for range QueryList[member](ctx, c, "categorymembers", map[string]any{"cmtitle": "Category:A"}) {
+		break
+	}
+	if n := len(wiki.RequestsFor("query")) - before; n != 1 {
+		t.Fatalf("requests after break = %d, want 1", n)
+	}
+
+	var failed error
+	for _, err := range QueryList[member](ctx, c, "categorymembers", nil) {
+		failed = err
+	}
+	if failed == nil {
+		t.Fatalf("want error for a failed request")
+	}
+}
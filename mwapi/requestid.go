@@ -0,0 +1,45 @@
+package mwapi
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+type requestIDKey struct{}
+
+// ContextWithRequestID attaches a caller-provided correlation ID to ctx.
+// Every request made with ctx carries it (see WithRequestIDHeader).
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// WithRequestIDHeader sends the context's request ID in the named header
+// (e.g. "X-Request-Id"). Calls without one get a generated ID, shared by all
+// attempts (retries, relogins) of that call.
+func WithRequestIDHeader(name string) Option {
+	return func(c *Client) {
+		c.requestIDHeader = name
+	}
+}
+
+func (c *Client) ensureRequestID(ctx context.Context) context.Context {
+	if c.requestIDHeader == "" {
+		return ctx
+	}
+	if _, ok := RequestIDFromContext(ctx); ok {
+		return ctx
+	}
+	return ContextWithRequestID(ctx, newRequestID())
+}
+
+func newRequestID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
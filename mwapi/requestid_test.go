@@ -0,0 +1,39 @@
+package mwapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+func TestRequestIDHeader(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("edit", func(r *mwtest.Request) any {
+		return map[string]any{"edit": map[string]any{"result": "Success"}}
+	})
+
+	c := New(wiki.URL(), WithRequestIDHeader("X-Request-Id"))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	rctx := ContextWithRequestID(ctx, "trace-123")
+	if _, err := c.PostWithToken(rctx, TokenCSRF, map[string]any{"action": "edit", "title": "X"}, nil); err != nil {
+		t.Fatalf("PostWithToken: %v", err)
+	}
+	for _, r := range wiki.Requests() {
+		if got := r.Header.Get("X-Request-Id"); got != "trace-123" {
+			t.Fatalf("%s request id = %q, want trace-123", r.Action(), got)
+		}
+	}
+
+	if _, err := c.Get(ctx, map[string]any{"meta": "userinfo"}); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got := wiki.LastRequest("query").Header.Get("X-Request-Id"); got == "" || got == "trace-123" {
+		t.Fatalf("generated request id = %q", got)
+	}
+}
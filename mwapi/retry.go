@@ -0,0 +1,108 @@
+package mwapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// maxRetryAfter caps how long a server's Retry-After can stall a retry.
+const maxRetryAfter = time.Minute
+
+// WithRetryOnServerError retries a request up to n times when the server
+// answers 500, 502, 503 or 504, or the connection times out or drops. The
+// wait doubles from baseDelay on every try, with jitter, unless the response
+// has a Retry-After. Requests uploading files are not retried, since their
+// body cannot be sent twice.
+func WithRetryOnServerError(n int, baseDelay time.Duration) Option {
+	return func(c *Client) {
+		if n >= 0 {
+			c.serverRetries = n
+		}
+		if baseDelay > 0 {
+			c.serverRetryDelay = baseDelay
+		}
+	}
+}
+
+// doOnceRetry is doOnce, retried on transient server and network errors.
+func (c *Client) doOnceRetry(ctx context.Context, method string, np normalizedParams, throw bool) (*Response, error) {
+	if c.serverRetries <= 0 || !np.replayable() {
+		return c.doOnce(ctx, method, np, throw)
+	}
+	for attempt := 0; ; attempt++ {
+		resp, err := c.doOnce(ctx, method, np, throw)
+		if attempt >= c.serverRetries || ctx.Err() != nil || !isTransient(resp, err) {
+			return resp, err
+		}
+		if err := sleepCtx(ctx, c.serverRetryWait(resp, attempt)); err != nil {
+			return resp, err
+		}
+	}
+}
+
+func (c *Client) serverRetryWait(resp *Response, attempt int) time.Duration {
+	if resp != nil {
+		if d, ok := retryAfter(resp.Header); ok {
+			return min(d, maxRetryAfter)
+		}
+	}
+	d := c.serverRetryDelay << min(attempt, 16)
+	return d/2 + rand.N(d/2+1)
+}
+
+func isTransient(resp *Response, err error) bool {
+	if resp != nil {
+		switch resp.StatusCode {
+		case http.StatusInternalServerError, http.StatusBadGateway,
+			http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+	}
+	var te *TransportError
+	if !errors.As(err, &te) {
+		return false
+	}
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		return true
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// replayable reports whether the request body can be built again: file
+// readers are drained by the first attempt, streams only if they rewind.
+func (np normalizedParams) replayable() bool {
+	if len(np.Files) > 0 {
+		return false
+	}
+	for _, sf := range np.Streams {
+		if _, ok := sf.Stream.r.(io.Seeker); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// retryAfter parses a Retry-After header, in seconds or as an HTTP date.
+func retryAfter(h http.Header) (time.Duration, bool) {
+	v := strings.TrimSpace(h.Get("Retry-After"))
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
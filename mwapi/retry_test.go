@@ -0,0 +1,136 @@
+package mwapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryOnServerError(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if calls.Add(1)%3 != 0 {
+			if calls.Load()%2 == 0 {
+				w.Header().Set("Retry-After", "0")
+			}
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("<h1>503 Service Unavailable</h1>"))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"query": map[string]any{"echo": r.Form.Get("title")}})
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	c := New(srv.URL+"/api.php", WithRetryOnServerError(2, time.Millisecond))
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		var resp *Response
+		var err error
+		if method == http.MethodGet {
+			resp, err = c.Get(ctx, map[string]any{"title": "Sandbox"})
+		} else {
+			resp, err = c.Post(ctx, map[string]any{"title": "Sandbox"})
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", method, err)
+		}
+		if !strings.Contains(string(resp.Raw), "Sandbox") {
+			t.Fatalf("%s: body = %s (POST body not resent?)", method, resp.Raw)
+		}
+	}
+	if n := calls.Load(); n != 6 {
+		t.Fatalf("calls = %d, want 6", n)
+	}
+
+	// Retries exhausted: the last 503 is returned.
+	calls.Store(0)
+	c = New(srv.URL+"/api.php", WithRetryOnServerError(1, time.Millisecond))
+	var httpErr *HTTPError
+	if _, err := c.Get(ctx, nil); !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("err = %v, want http 503", err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("calls = %d, want 2", n)
+	}
+}
+
+type timeoutErr struct{}
+
+func (timeoutErr) Error() string   { return "i/o timeout" }
+func (timeoutErr) Timeout() bool   { return true }
+func (timeoutErr) Temporary() bool { return true }
+
+var _ net.Error = timeoutErr{}
+
+type flakyTransport struct {
+	fails atomic.Int32
+	next  http.RoundTripper
+}
+
+func (f *flakyTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if f.fails.Add(-1) >= 0 {
+		return nil, timeoutErr{}
+	}
+	return f.next.RoundTrip(r)
+}
+
+func TestRetryOnServerError_NetworkTimeout(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"batchcomplete":true}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	rt := &flakyTransport{next: http.DefaultTransport}
+	rt.fails.Store(2)
+	c := New(srv.URL+"/api.php", WithTransport(rt), WithRetryOnServerError(3, time.Millisecond))
+	if _, err := c.Get(ctx, nil); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	// Without the option the first timeout is returned.
+	rt.fails.Store(1)
+	c = New(srv.URL+"/api.php", WithTransport(rt))
+	var te *TransportError
+	if _, err := c.Get(ctx, nil); !errors.As(err, &te) {
+		t.Fatalf("err = %v, want *TransportError", err)
+	}
+}
+
+func TestRetryOnServerError_ContextAbortsBackoff(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	t.Cleanup(cancel)
+
+	c := New(srv.URL+"/api.php", WithRetryOnServerError(5, time.Hour))
+	start := time.Now()
+	if _, err := c.Get(ctx, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("backoff not aborted: took %v", d)
+	}
+}
@@ -0,0 +1,155 @@
+package mwapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// RevDelField is a bitfield of the parts of a revision that can be hidden.
+type RevDelField uint8
+
+const (
+	RevDelContent RevDelField = 1 << iota
+	RevDelComment
+	RevDelUser
+)
+
+func (f RevDelField) names() []string {
+	var out []string
+	if f&RevDelContent != 0 {
+		out = append(out, "content")
+	}
+	if f&RevDelComment != 0 {
+		out = append(out, "comment")
+	}
+	if f&RevDelUser != 0 {
+		out = append(out, "user")
+	}
+	return out
+}
+
+type RevDelParams struct {
+	Type   string // revision (default), logging, oldimage, filearchive
+	Target string // page title; required for all types but logging
+	IDs    []int64
+	Hide   RevDelField
+	Show   RevDelField
+	Reason string
+}
+
+type RevDelItem struct {
+	ID            int64
+	Status        string
+	ContentHidden bool
+	CommentHidden bool
+	UserHidden    bool
+	Errors        []MWError
+	Warnings      []MWError
+}
+
+type RevDelResult struct {
+	Status string
+	Target string
+	Type   string
+	Items  []RevDelItem
+}
+
+// RevisionDelete changes the visibility of revisions or log entries
+// (action=revisiondelete). An item that failed is reported as an error for
+// its first failure code, so errors.Is(err, ErrRevDelNoChange) and
+// errors.Is(err, ErrPermissionDenied) work; the result is still returned.
+func (c *Client) RevisionDelete(ctx context.Context, params RevDelParams) (*RevDelResult, error) {
+	typ := params.Type
+	if typ == "" {
+		typ = "revision"
+	}
+	if len(params.IDs) == 0 {
+		return nil, errors.New("revisiondelete: no ids given")
+	}
+	if params.Hide == 0 && params.Show == 0 {
+		return nil, errors.New("revisiondelete: nothing to hide or show")
+	}
+	if params.Hide&params.Show != 0 {
+		return nil, errors.New("revisiondelete: a field cannot be both hidden and shown")
+	}
+
+	p := map[string]any{
+		"action": "revisiondelete",
+		"type":   typ,
+		"ids":    params.IDs,
+		"hide":   params.Hide.names(),
+		"show":   params.Show.names(),
+	}
+	if params.Target != "" {
+		p["target"] = params.Target
+	}
+	if params.Reason != "" {
+		p["reason"] = params.Reason
+	}
+
+	resp, err := c.PostWithToken(ctx, TokenCSRF, p, nil)
+	if err != nil {
+		return nil, fmt.Errorf("revisiondelete: %w", err)
+	}
+	if err := apiError(resp); err != nil {
+		return nil, fmt.Errorf("revisiondelete: %w", err)
+	}
+
+	var out struct {
+		RevDel *struct {
+			Status string `json:"status"`
+			Target string `json:"target"`
+			Type   string `json:"type"`
+			Items  []struct {
+				ID            int64           `json:"id"`
+				Status        string          `json:"status"`
+				TextHidden    json.RawMessage `json:"texthidden"`
+				CommentHidden json.RawMessage `json:"commenthidden"`
+				UserHidden    json.RawMessage `json:"userhidden"`
+				Errors        []MWError       `json:"errors"`
+				Warnings      []MWError       `json:"warnings"`
+			} `json:"items"`
+		} `json:"revisiondelete"`
+	}
+	if err := resp.Into(&out); err != nil {
+		return nil, err
+	}
+	if out.RevDel == nil {
+		return nil, errors.New("missing revisiondelete in response")
+	}
+
+	res := &RevDelResult{Status: out.RevDel.Status, Target: out.RevDel.Target, Type: out.RevDel.Type}
+	var itemErr error
+	for _, it := range out.RevDel.Items {
+		res.Items = append(res.Items, RevDelItem{
+			ID:            it.ID,
+			Status:        it.Status,
+			ContentHidden: rawFlag(it.TextHidden),
+			CommentHidden: rawFlag(it.CommentHidden),
+			UserHidden:    rawFlag(it.UserHidden),
+			Errors:        it.Errors,
+			Warnings:      it.Warnings,
+		})
+		if itemErr == nil && len(it.Errors) > 0 {
+			e := it.Errors[0]
+			itemErr = &MediaWikiApiError{
+				Code:       e.Code,
+				Message:    fmt.Sprintf("id %d: %s", it.ID, firstNonEmpty(e.Info, e.Text)),
+				Action:     "revisiondelete",
+				HTTPStatus: resp.StatusCode,
+				Errors:     it.Errors,
+				Response:   resp,
+			}
+		}
+	}
+	if itemErr == nil && !strings.EqualFold(res.Status, "success") {
+		itemErr = fmt.Errorf("revisiondelete failed: %s", res.Status)
+	}
+	if itemErr != nil {
+		return res, fmt.Errorf("revisiondelete: %w", itemErr)
+	}
+	return res, nil
+}
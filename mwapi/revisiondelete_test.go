@@ -0,0 +1,81 @@
+package mwapi
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+func TestRevisionDelete_HideComment(t *testing.T) {
+	t.Parallel()
+
+	hidden := map[string]bool{}
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("revisiondelete", func(r *mwtest.Request) any {
+		if r.User != "Oversighter" {
+			return mwtest.ErrorResponse("permissiondenied", "You don't have permission to change visibility of revisions.")
+		}
+		var items []any
+		for _, id := range strings.Split(r.Param("ids"), "|") {
+			item := map[string]any{"status": "Success", "id": mustAtoi(t, id), "errors": []any{}, "warnings": []any{}}
+			if r.Param("hide") == "comment" {
+				if hidden[id] {
+					item["status"] = "Fail"
+					item["errors"] = []any{map[string]any{"code": "revdelete-no-change", "text": "Warning: the item dated 12:00, 1 January 2024 already had the requested visibility settings."}}
+				} else {
+					hidden[id] = true
+					item["commenthidden"] = true
+				}
+			}
+			items = append(items, item)
+		}
+		return map[string]any{"revisiondelete": map[string]any{
+			"status": "Success", "target": r.Param("target"), "type": r.Param("type"), "items": items,
+		}}
+	})
+
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	params := RevDelParams{Target: "Sandbox", IDs: []int64{123}, Hide: RevDelComment, Reason: "Personal information"}
+	if _, err := c.RevisionDelete(ctx, params); !errors.Is(err, ErrPermissionDenied) {
+		t.Fatalf("anonymous err = %v, want ErrPermissionDenied", err)
+	}
+
+	if _, err := c.Login(ctx, "Oversighter", "pass"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	res, err := c.RevisionDelete(ctx, params)
+	if err != nil {
+		t.Fatalf("RevisionDelete: %v", err)
+	}
+	if len(res.Items) != 1 || res.Items[0].ID != 123 || !res.Items[0].CommentHidden || res.Items[0].ContentHidden {
+		t.Fatalf("items = %+v", res.Items)
+	}
+	wiki.AssertSent("revisiondelete", "type", "revision")
+	wiki.AssertSent("revisiondelete", "hide", "comment")
+	wiki.AssertNotSent("revisiondelete", "show")
+
+	res, err = c.RevisionDelete(ctx, params)
+	if !errors.Is(err, ErrRevDelNoChange) {
+		t.Fatalf("repeat err = %v, want ErrRevDelNoChange", err)
+	}
+	if res == nil || res.Items[0].Status != "Fail" {
+		t.Fatalf("repeat result = %+v", res)
+	}
+}
+
+func mustAtoi(t *testing.T, s string) int {
+	t.Helper()
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		t.Fatalf("Atoi(%q): %v", s, err)
+	}
+	return n
+}
@@ -0,0 +1,128 @@
+package mwapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+type SlotContent struct {
+	ContentModel  string `json:"contentmodel,omitempty"`
+	ContentFormat string `json:"contentformat,omitempty"`
+	Content       string `json:"content"`
+}
+
+func (s *SlotContent) UnmarshalJSON(b []byte) error {
+	type plain SlotContent
+	var v struct {
+		plain
+		Star *string `json:"*"` // formatversion=1
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*s = SlotContent(v.plain)
+	if v.Star != nil && s.Content == "" {
+		s.Content = *v.Star
+	}
+	return nil
+}
+
+type Revision struct {
+	RevID     int64  `json:"revid"`
+	ParentID  int64  `json:"parentid"`
+	Minor     bool   `json:"minor,omitempty"`
+	User      string `json:"user,omitempty"`
+	Timestamp string `json:"timestamp,omitempty"`
+	Size      int    `json:"size,omitempty"`
+	SHA1      string `json:"sha1,omitempty"`
+	Comment   string `json:"comment,omitempty"`
+
+	Slots map[string]SlotContent `json:"slots,omitempty"`
+}
+
+func (r *Revision) UnmarshalJSON(b []byte) error {
+	type plain Revision
+	var v struct {
+		plain
+		// formatversion=1 flags are present-as-"" rather than booleans.
+		Minor json.RawMessage `json:"minor"`
+		// Pre-MCR shape: content lives directly on the revision.
+		ContentModel  string  `json:"contentmodel"`
+		ContentFormat string  `json:"contentformat"`
+		Content       *string `json:"content"`
+		Star          *string `json:"*"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*r = Revision(v.plain)
+	r.Minor = rawFlag(v.Minor)
+
+	if len(r.Slots) == 0 && (v.Content != nil || v.Star != nil) {
+		sc := SlotContent{ContentModel: v.ContentModel, ContentFormat: v.ContentFormat}
+		if v.Content != nil {
+			sc.Content = *v.Content
+		} else {
+			sc.Content = *v.Star
+		}
+		r.Slots = map[string]SlotContent{"main": sc}
+	}
+	return nil
+}
+
+func (r *Revision) MainContent() string {
+	if r == nil {
+		return ""
+	}
+	return r.Slots["main"].Content
+}
+
+// Revisions fetches up to limit revisions (newest first) of a single page, including content.
+// rvslots=* is always requested so content is decoded from both MCR and pre-MCR wikis.
+// A page that does not exist yields ErrPageMissing.
+func (c *Client) Revisions(ctx context.Context, title string, limit int) ([]Revision, error) {
+	if title == "" {
+		return nil, errors.New("revisions: missing title")
+	}
+	if limit <= 0 {
+		limit = 1
+	}
+
+	resp, err := c.Get(ctx, map[string]any{
+		"action":  "query",
+		"titles":  title,
+		"prop":    "revisions",
+		"rvprop":  []string{"ids", "flags", "timestamp", "user", "size", "sha1", "comment", "content"},
+		"rvslots": "*",
+		"rvlimit": limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := apiError(resp); err != nil {
+		return nil, err
+	}
+
+	var out struct {
+		Query struct {
+			Pages []struct {
+				Missing   json.RawMessage `json:"missing"`
+				Invalid   json.RawMessage `json:"invalid"`
+				Revisions []Revision      `json:"revisions"`
+			} `json:"pages"`
+		} `json:"query"`
+	}
+	if err := resp.Into(&out); err != nil {
+		return nil, err
+	}
+	if len(out.Query.Pages) == 0 {
+		return nil, errors.New("missing query.pages in response")
+	}
+	pg := out.Query.Pages[0]
+	if rawFlag(pg.Missing) || rawFlag(pg.Invalid) {
+		return nil, fmt.Errorf("%w: %s", ErrPageMissing, title)
+	}
+	return pg.Revisions, nil
+}
@@ -0,0 +1,117 @@
+package mwapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRevision_DecodeSlotShapes(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		raw  string
+	}{
+		{"mcr fv2", `{"revid":2,"slots":{"main":{"contentmodel":"wikitext","content":"hello"},"extra":{"content":"x"}}}`},
+		{"mcr fv1", `{"revid":2,"slots":{"main":{"contentmodel":"wikitext","*":"hello"}}}`},
+		{"pre-mcr fv2", `{"revid":2,"contentmodel":"wikitext","content":"hello"}`},
+		{"pre-mcr fv1", `{"revid":2,"contentmodel":"wikitext","*":"hello"}`},
+	}
+	for _, tc := range cases {
+		var r Revision
+		if err := json.Unmarshal([]byte(tc.raw), &r); err != nil {
+			t.Fatalf("%s: Unmarshal: %v", tc.name, err)
+		}
+		if r.RevID != 2 {
+			t.Fatalf("%s: revid = %d", tc.name, r.RevID)
+		}
+		if got := r.MainContent(); got != "hello" {
+			t.Fatalf("%s: MainContent = %q", tc.name, got)
+		}
+		if got := r.Slots["main"].ContentModel; got != "wikitext" {
+			t.Fatalf("%s: contentmodel = %q", tc.name, got)
+		}
+	}
+
+	for raw, want := range map[string]bool{
+		`{"revid":2,"minor":"","*":"hello"}`: true,
+		`{"revid":2,"minor":true}`:           true,
+		`{"revid":2,"minor":false}`:          false,
+		`{"revid":2,"*":"hello"}`:            false,
+	} {
+		var r Revision
+		if err := json.Unmarshal([]byte(raw), &r); err != nil {
+			t.Fatalf("%s: Unmarshal: %v", raw, err)
+		}
+		if r.Minor != want {
+			t.Fatalf("%s: Minor = %v, want %v", raw, r.Minor, want)
+		}
+	}
+}
+
+func TestRevisions_RequestsSlots(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if got := r.Form.Get("rvslots"); got != "*" {
+			t.Errorf("rvslots = %q, want *", got)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"query": map[string]any{"pages": []any{map[string]any{
+				"pageid": 1, "ns": 0, "title": "Main Page",
+				"revisions": []any{
+					map[string]any{"revid": 3, "parentid": 2, "slots": map[string]any{
+						"main": map[string]any{"content": "new"},
+					}},
+					map[string]any{"revid": 2, "parentid": 0, "slots": map[string]any{
+						"main": map[string]any{"content": "old"},
+					}},
+				},
+			}}},
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	c := New(srv.URL + "/api.php")
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	revs, err := c.Revisions(ctx, "Main Page", 2)
+	if err != nil {
+		t.Fatalf("Revisions: %v", err)
+	}
+	if len(revs) != 2 || revs[0].MainContent() != "new" || revs[1].MainContent() != "old" {
+		t.Fatalf("revisions = %+v", revs)
+	}
+}
+
+func TestRevisions_PageMissing(t *testing.T) {
+	t.Parallel()
+
+	shapes := map[string]any{
+		"fv2 flag":   map[string]any{"query": map[string]any{"pages": []any{map[string]any{"ns": 0, "title": "Nope", "missing": true}}}},
+		"fv1 flag":   map[string]any{"query": map[string]any{"pages": []any{map[string]any{"ns": 0, "title": "Nope", "missing": ""}}}},
+		"error code": map[string]any{"errors": []any{map[string]any{"code": "missingtitle", "text": "The page you specified doesn't exist."}}},
+	}
+	for name, body := range shapes {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(body)
+		}))
+		t.Cleanup(srv.Close)
+
+		c := New(srv.URL+"/api.php", WithThrowOnApiError(false))
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		t.Cleanup(cancel)
+
+		_, err := c.Revisions(ctx, "Nope", 1)
+		if !errors.Is(err, ErrPageMissing) {
+			t.Fatalf("%s: err = %v, want ErrPageMissing", name, err)
+		}
+	}
+}
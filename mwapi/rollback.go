@@ -0,0 +1,76 @@
+package mwapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+type RollbackParams struct {
+	Title  string
+	PageID int64
+	// User is the editor whose consecutive latest edits are reverted.
+	User    string
+	Summary string
+	// MarkBot hides the rollback and the reverted edits from recent changes.
+	MarkBot bool
+}
+
+type RollbackResult struct {
+	Title   string `json:"title"`
+	PageID  int64  `json:"pageid"`
+	Summary string `json:"summary"`
+	// RevID is the revision created by the rollback, OldRevID the last
+	// reverted one and LastRevID the one restored.
+	RevID     int64 `json:"revid"`
+	OldRevID  int64 `json:"old_revid"`
+	LastRevID int64 `json:"last_revid"`
+}
+
+// Rollback reverts the latest edits of params.User to the page
+// (action=rollback). A page whose only author is User fails with
+// ErrOnlyAuthor.
+func (c *Client) Rollback(ctx context.Context, params RollbackParams) (*RollbackResult, error) {
+	if params.Title == "" && params.PageID == 0 {
+		return nil, errors.New("rollback: missing title or page id")
+	}
+	if params.User == "" {
+		return nil, errors.New("rollback: missing user")
+	}
+
+	p := map[string]any{
+		"action": "rollback",
+		"user":   params.User,
+	}
+	if params.PageID != 0 {
+		p["pageid"] = params.PageID
+	} else {
+		p["title"] = params.Title
+	}
+	if params.Summary != "" {
+		p["summary"] = params.Summary
+	}
+	// Left unset otherwise, so WithBotEdits can still apply.
+	if params.MarkBot {
+		p["markbot"] = true
+	}
+
+	resp, err := c.PostWithToken(ctx, TokenRollback, p, nil)
+	if err != nil {
+		return nil, fmt.Errorf("rollback: %w", err)
+	}
+	if err := apiError(resp); err != nil {
+		return nil, fmt.Errorf("rollback: %w", err)
+	}
+
+	var out struct {
+		Rollback *RollbackResult `json:"rollback"`
+	}
+	if err := resp.Into(&out); err != nil {
+		return nil, err
+	}
+	if out.Rollback == nil {
+		return nil, errors.New("missing rollback in response")
+	}
+	return out.Rollback, nil
+}
@@ -0,0 +1,55 @@
+package mwapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+func TestRollback(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("rollback", func(r *mwtest.Request) any {
+		if r.Param("user") == "Creator" {
+			return mwtest.ErrorResponse("onlyauthor", "The page has only one author.")
+		}
+		return map[string]any{"rollback": map[string]any{
+			"title": r.Param("title"), "pageid": 7, "summary": r.Param("summary"),
+			"revid": 103, "old_revid": 102, "last_revid": 100,
+		}}
+	})
+
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+	if _, err := c.Login(ctx, "Patroller", "pass"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+
+	res, err := c.Rollback(ctx, RollbackParams{Title: "Sandbox", User: "Vandal", Summary: "rv", MarkBot: true})
+	if err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+	want := RollbackResult{Title: "Sandbox", PageID: 7, Summary: "rv", RevID: 103, OldRevID: 102, LastRevID: 100}
+	if *res != want {
+		t.Fatalf("result = %+v, want %+v", *res, want)
+	}
+	wiki.AssertSent("rollback", "markbot", "1")
+	tokReqs := wiki.RequestsFor("query")
+	if got := tokReqs[len(tokReqs)-1].Param("type"); got != "rollback" {
+		t.Fatalf("token type = %q, want rollback", got)
+	}
+
+	_, err = c.Rollback(ctx, RollbackParams{Title: "New page", User: "Creator"})
+	if !errors.Is(err, ErrOnlyAuthor) {
+		t.Fatalf("err = %v, want ErrOnlyAuthor", err)
+	}
+
+	if _, err := c.Rollback(ctx, RollbackParams{Title: "Sandbox"}); err == nil {
+		t.Fatalf("missing user should fail")
+	}
+}
@@ -0,0 +1,156 @@
+package mwapi
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scheduler decides when requests may be sent, giving one place for a wiki
+// politeness policy. The client calls Before ahead of every HTTP request
+// (retries included) and After with its outcome. Implementations must be
+// safe for concurrent use.
+type Scheduler interface {
+	// Before blocks until a request for action may be sent, or ctx is done.
+	Before(ctx context.Context, action string, isWrite bool) error
+	// After reports the outcome of the request; resp is nil on transport errors.
+	After(resp *Response, err error)
+}
+
+// WithScheduler makes the client consult s before and after every request.
+// Clones share s, so a pool of workers is paced as one. See
+// NewPoliteScheduler for the default policy.
+func WithScheduler(s Scheduler) Option {
+	return func(c *Client) {
+		c.scheduler = s
+	}
+}
+
+// SchedulerOptions configures NewPoliteScheduler. Zero fields take the
+// defaults noted below.
+type SchedulerOptions struct {
+	// ReadInterval and WriteInterval are the least time between two reads and
+	// two writes. Reads are unpaced by default, writes default to 1s.
+	ReadInterval  time.Duration
+	WriteInterval time.Duration
+	// LagThreshold is the replication lag (X-Database-Lag) above which all
+	// requests pause for as long as the reported lag; default 5s.
+	LagThreshold time.Duration
+	// ThrottleBackoff is the first pause after a throttle signal (HTTP 429,
+	// ratelimited, maxlag); it doubles with every further one and resets on
+	// success. A Retry-After takes precedence. Default 5s.
+	ThrottleBackoff time.Duration
+	// MaxDelay caps every pause; default 2m.
+	MaxDelay time.Duration
+}
+
+// PoliteScheduler is the default Scheduler: it paces reads and writes
+// separately and pauses all requests while the wiki reports lag or throttles.
+type PoliteScheduler struct {
+	opts SchedulerOptions
+
+	mu         sync.Mutex
+	nextRead   time.Time
+	nextWrite  time.Time
+	pauseUntil time.Time
+	throttles  int
+}
+
+// NewPoliteScheduler returns a PoliteScheduler with opts, defaults filled in.
+// Share one across clients that talk to the same wiki. It replaces the
+// client's own maxlag wait (see WithMaxLagRetry); WithMinWriteInterval still
+// applies on top of it, once per write rather than per attempt, so set
+// WriteInterval instead of both.
+func NewPoliteScheduler(opts SchedulerOptions) *PoliteScheduler {
+	if opts.WriteInterval == 0 {
+		opts.WriteInterval = time.Second
+	}
+	if opts.LagThreshold <= 0 {
+		opts.LagThreshold = 5 * time.Second
+	}
+	if opts.ThrottleBackoff <= 0 {
+		opts.ThrottleBackoff = 5 * time.Second
+	}
+	if opts.MaxDelay <= 0 {
+		opts.MaxDelay = 2 * time.Minute
+	}
+	return &PoliteScheduler{opts: opts}
+}
+
+func (s *PoliteScheduler) Before(ctx context.Context, action string, isWrite bool) error {
+	return sleepCtx(ctx, s.reserve(time.Now(), isWrite))
+}
+
+// reserve returns how long a request starting at now must wait, and books
+// its slot so that concurrent callers queue up behind it.
+func (s *PoliteScheduler) reserve(now time.Time, isWrite bool) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next, interval := &s.nextRead, s.opts.ReadInterval
+	if isWrite {
+		next, interval = &s.nextWrite, s.opts.WriteInterval
+	}
+	at := now
+	if s.pauseUntil.After(at) {
+		at = s.pauseUntil
+	}
+	if next.After(at) {
+		at = *next
+	}
+	*next = at.Add(interval)
+	return at.Sub(now)
+}
+
+func (s *PoliteScheduler) After(resp *Response, err error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if throttled, wait := throttleSignal(resp, err); throttled {
+		s.throttles++
+		if wait < 0 {
+			wait = s.opts.ThrottleBackoff << min(s.throttles-1, 16)
+		}
+		s.pause(now, wait)
+	} else if err == nil {
+		s.throttles = 0
+	}
+	if resp != nil && resp.DatabaseLag > s.opts.LagThreshold {
+		s.pause(now, resp.DatabaseLag)
+	}
+}
+
+func (s *PoliteScheduler) pause(now time.Time, d time.Duration) {
+	if until := now.Add(min(d, s.opts.MaxDelay)); until.After(s.pauseUntil) {
+		s.pauseUntil = until
+	}
+}
+
+// throttleSignal reports whether the wiki asked the client to slow down, and
+// for how long if it said so (-1 otherwise).
+func throttleSignal(resp *Response, err error) (bool, time.Duration) {
+	code := responseErrorCode(resp)
+	if e, ok := IsMediaWikiApiError(err); ok {
+		code = e.Code
+	}
+	throttled := false
+	switch strings.ToLower(code) {
+	case "ratelimited", "maxlag", "actionthrottled":
+		throttled = true
+	}
+	if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+		throttled = true
+	}
+	if !throttled {
+		return false, -1
+	}
+	if resp != nil {
+		if d, ok := retryAfter(resp.Header); ok {
+			return true, d
+		}
+	}
+	return true, -1
+}
@@ -0,0 +1,116 @@
+package mwapi
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+func approx(d, want time.Duration) bool {
+	return d > want-time.Second && d <= want
+}
+
+func TestPoliteScheduler_PacesWrites(t *testing.T) {
+	t.Parallel()
+
+	s := NewPoliteScheduler(SchedulerOptions{})
+	now := time.Now()
+	if d := s.reserve(now, true); d != 0 {
+		t.Fatalf("first write waits %v", d)
+	}
+	if d := s.reserve(now, true); d != time.Second {
+		t.Fatalf("second write waits %v, want 1s", d)
+	}
+	if d := s.reserve(now, false); d != 0 {
+		t.Fatalf("read waits %v behind writes", d)
+	}
+}
+
+func TestPoliteScheduler_BacksOffOnLag(t *testing.T) {
+	t.Parallel()
+
+	s := NewPoliteScheduler(SchedulerOptions{LagThreshold: 2 * time.Second})
+	s.After(&Response{DatabaseLag: time.Second}, nil)
+	if d := s.reserve(time.Now(), false); d != 0 {
+		t.Fatalf("lag under threshold paused for %v", d)
+	}
+	s.After(&Response{DatabaseLag: 8 * time.Second}, nil)
+	if d := s.reserve(time.Now(), false); !approx(d, 8*time.Second) {
+		t.Fatalf("wait = %v, want ~8s", d)
+	}
+}
+
+func TestPoliteScheduler_BacksOffOnThrottle(t *testing.T) {
+	t.Parallel()
+
+	ratelimited := &Response{Envelope: Envelope{Error: &MWError{Code: "ratelimited"}}}
+	s := NewPoliteScheduler(SchedulerOptions{ThrottleBackoff: 10 * time.Second, MaxDelay: 30 * time.Second})
+	for _, want := range []time.Duration{10 * time.Second, 20 * time.Second, 30 * time.Second} {
+		s.After(ratelimited, nil)
+		if d := s.reserve(time.Now(), false); !approx(d, want) {
+			t.Fatalf("wait = %v, want ~%v", d, want)
+		}
+	}
+
+	// A success resets the backoff; Retry-After on a 429 wins over it.
+	s = NewPoliteScheduler(SchedulerOptions{ThrottleBackoff: 10 * time.Second})
+	s.After(ratelimited, nil)
+	s.After(&Response{}, nil)
+	s.pauseUntil = time.Time{}
+	s.After(ratelimited, nil)
+	if d := s.reserve(time.Now(), false); !approx(d, 10*time.Second) {
+		t.Fatalf("wait after reset = %v, want ~10s", d)
+	}
+	s = NewPoliteScheduler(SchedulerOptions{})
+	s.After(&Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"42"}}}, nil)
+	if d := s.reserve(time.Now(), true); !approx(d, 42*time.Second) {
+		t.Fatalf("wait = %v, want ~42s from Retry-After", d)
+	}
+}
+
+type recordingScheduler struct {
+	mu     sync.Mutex
+	before []string
+	after  int
+}
+
+func (r *recordingScheduler) Before(ctx context.Context, action string, isWrite bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if isWrite {
+		action += "(write)"
+	}
+	r.before = append(r.before, action)
+	return nil
+}
+
+func (r *recordingScheduler) After(resp *Response, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.after++
+}
+
+func TestWithScheduler_ConsultedPerRequest(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("edit", func(r *mwtest.Request) any {
+		return map[string]any{"edit": map[string]any{"result": "Success"}}
+	})
+	rec := &recordingScheduler{}
+	c := New(wiki.URL(), WithScheduler(rec))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	if _, err := c.Edit(ctx, EditParams{Title: "Sandbox", Text: "x"}); err != nil {
+		t.Fatalf("Edit: %v", err)
+	}
+	want := []string{"query", "edit(write)"} // token fetch, then the edit
+	if len(rec.before) != 2 || rec.before[0] != want[0] || rec.before[1] != want[1] || rec.after != 2 {
+		t.Fatalf("before = %v, after = %d; want %v and 2", rec.before, rec.after, want)
+	}
+}
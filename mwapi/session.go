@@ -0,0 +1,431 @@
+package mwapi
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Session is a thin, typed facade over *Client for the common
+// login -> userinfo -> edit/upload workflow. It adds no state of its own;
+// use Client() to drop down to the raw API when needed.
+type Session struct {
+	c *Client
+}
+
+func NewSession(c *Client) *Session {
+	return &Session{c: c}
+}
+
+func (s *Session) Client() *Client {
+	return s.c
+}
+
+func (s *Session) Login(ctx context.Context, user, pass string) (*LoginResult, error) {
+	return s.c.Login(ctx, user, pass)
+}
+
+type UserInfo struct {
+	ID        int64    `json:"id"`
+	Name      string   `json:"name"`
+	Anon      bool     `json:"anon,omitempty"`
+	EditCount int      `json:"editcount,omitempty"`
+	Groups    []string `json:"groups,omitempty"`
+	Rights    []string `json:"rights,omitempty"`
+}
+
+func (s *Session) UserInfo(ctx context.Context) (*UserInfo, error) {
+	resp, err := s.c.Get(ctx, map[string]any{
+		"action": "query",
+		"meta":   "userinfo",
+		"uiprop": []string{"editcount", "groups", "rights"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := apiError(resp); err != nil {
+		return nil, err
+	}
+
+	var out struct {
+		Query struct {
+			UserInfo UserInfo `json:"userinfo"`
+		} `json:"query"`
+	}
+	if err := resp.Into(&out); err != nil {
+		return nil, err
+	}
+	if out.Query.UserInfo.Name == "" {
+		return nil, errors.New("missing userinfo.name in response")
+	}
+	return &out.Query.UserInfo, nil
+}
+
+// EditParams describes an action=edit request. Exactly one of Title and
+// PageID must be set.
+type EditParams struct {
+	Title  string
+	PageID int64
+	Text   string
+	// TextReader, if set, is streamed as the text instead of Text, so large
+	// pages need not be held in memory. It is consumed by the edit; pass a
+	// fresh (or rewound) reader to ResolveCaptcha.
+	TextReader io.Reader
+	Summary    string
+	Minor      bool
+	Bot        bool
+	CreateOnly bool
+	NoCreate   bool
+	// BaseTimestamp and StartTimestamp enable edit conflict and
+	// deleted-since-loaded detection; zero values are not sent.
+	BaseTimestamp  time.Time
+	StartTimestamp time.Time
+	// SkipIfUnchanged compares Text with the current revision (by SHA-1) first
+	// and, if equal, returns a NoChange result without editing.
+	SkipIfUnchanged bool
+}
+
+type EditResult struct {
+	Result       string `json:"result"`
+	PageID       int64  `json:"pageid"`
+	Title        string `json:"title"`
+	OldRevID     int64  `json:"oldrevid"`
+	NewRevID     int64  `json:"newrevid"`
+	NewTimestamp string `json:"newtimestamp"`
+	ContentModel string `json:"contentmodel,omitempty"`
+	// SpamBlacklist lists the blocked URLs of an edit refused by SpamBlacklist.
+	SpamBlacklist string `json:"spamblacklist,omitempty"`
+	// NoChange is set for null edits, including ones skipped by SkipIfUnchanged.
+	NoChange bool `json:"-"`
+	// New is set when the edit created the page.
+	New     bool `json:"-"`
+	Watched bool `json:"-"`
+	// Warnings are the edit module's warnings, e.g. from AbuseFilter on an
+	// edit that was saved anyway. See WithStrictWrites.
+	Warnings []MWError `json:"-"`
+}
+
+func (r *EditResult) UnmarshalJSON(b []byte) error {
+	type plain EditResult
+	var v struct {
+		plain
+		NoChange json.RawMessage `json:"nochange"`
+		New      json.RawMessage `json:"new"`
+		Watched  json.RawMessage `json:"watched"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*r = EditResult(v.plain)
+	r.NoChange = rawFlag(v.NoChange)
+	r.New = rawFlag(v.New)
+	r.Watched = rawFlag(v.Watched)
+	return nil
+}
+
+func (s *Session) Edit(ctx context.Context, params EditParams) (*EditResult, error) {
+	return s.c.edit(ctx, params, nil)
+}
+
+// Edit saves params.Text with a CSRF token. A result other than Success is
+// returned together with an error.
+func (c *Client) Edit(ctx context.Context, params EditParams) (*EditResult, error) {
+	return c.edit(ctx, params, nil)
+}
+
+// ResolveCaptcha resubmits an edit that failed with a *CaptchaError, answering
+// the CAPTCHA identified by captchaID.
+func (s *Session) ResolveCaptcha(ctx context.Context, params EditParams, captchaID, answer string) (*EditResult, error) {
+	if captchaID == "" {
+		return nil, errors.New("edit: missing captcha id")
+	}
+	return s.c.edit(ctx, params, map[string]any{
+		"captchaid":   captchaID,
+		"captchaword": answer,
+	})
+}
+
+func (c *Client) edit(ctx context.Context, params EditParams, extra map[string]any) (*EditResult, error) {
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+	if params.SkipIfUnchanged {
+		res, err := c.unchanged(ctx, params)
+		if err != nil || res != nil {
+			return res, err
+		}
+	}
+
+	p := map[string]any{
+		"action":         "edit",
+		"minor":          params.Minor,
+		"bot":            params.Bot,
+		"createonly":     params.CreateOnly,
+		"nocreate":       params.NoCreate,
+		"basetimestamp":  params.BaseTimestamp,
+		"starttimestamp": params.StartTimestamp,
+	}
+	if params.Title != "" {
+		p["title"] = params.Title
+	} else {
+		p["pageid"] = params.PageID
+	}
+	if params.TextReader != nil {
+		p["text"] = NewStream(params.TextReader)
+	} else {
+		p["text"] = params.Text
+	}
+	if params.Summary != "" {
+		p["summary"] = params.Summary
+	}
+	for k, v := range extra {
+		p[k] = v
+	}
+
+	resp, err := c.PostWithToken(ctx, TokenCSRF, p, nil)
+	if err != nil {
+		if ce := captchaFromError(err); ce != nil {
+			return nil, ce
+		}
+		return nil, err
+	}
+	if err := apiError(resp); err != nil {
+		if ce := captchaFromError(err); ce != nil {
+			return nil, ce
+		}
+		return nil, err
+	}
+
+	var out struct {
+		Edit json.RawMessage `json:"edit"`
+	}
+	if err := resp.Into(&out); err != nil {
+		return nil, err
+	}
+	res := &EditResult{}
+	if len(out.Edit) > 0 {
+		if err := json.Unmarshal(out.Edit, res); err != nil {
+			return nil, err
+		}
+	}
+	if res.Result == "" {
+		return nil, errors.New("missing edit.result in response")
+	}
+	var captcha struct {
+		Captcha *Captcha `json:"captcha"`
+	}
+	if err := json.Unmarshal(out.Edit, &captcha); err != nil {
+		return nil, err
+	}
+	if captcha.Captcha != nil {
+		return res, &CaptchaError{Captcha: *captcha.Captcha, Result: res}
+	}
+	if res.Warnings, err = moduleWarnings(resp.Raw, "edit"); err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(res.Result, "success") {
+		return res, fmt.Errorf("edit failed: %s", res.Result)
+	}
+	if err := c.strictWarnings("edit", res.Warnings); err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+func (p EditParams) validate() error {
+	switch {
+	case p.Title == "" && p.PageID == 0:
+		return errors.New("edit: missing title or page id")
+	case p.Title != "" && p.PageID != 0:
+		return errors.New("edit: title and page id are mutually exclusive")
+	case p.CreateOnly && p.NoCreate:
+		return errors.New("edit: createonly and nocreate are mutually exclusive")
+	case p.TextReader != nil && p.Text != "":
+		return errors.New("edit: text and text reader are mutually exclusive")
+	case p.TextReader != nil && p.SkipIfUnchanged:
+		return errors.New("edit: SkipIfUnchanged needs Text, not a text reader")
+	}
+	return nil
+}
+
+// unchanged returns a NoChange result if the latest revision of the page
+// already has params.Text, and nil if the edit should go ahead.
+func (c *Client) unchanged(ctx context.Context, params EditParams) (*EditResult, error) {
+	q := map[string]any{
+		"action":  "query",
+		"prop":    "revisions",
+		"rvprop":  []string{"ids", "sha1", "timestamp"},
+		"rvslots": "main",
+	}
+	if params.Title != "" {
+		q["titles"] = params.Title
+	} else {
+		q["pageids"] = params.PageID
+	}
+	resp, err := c.Get(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if err := apiError(resp); err != nil {
+		return nil, err
+	}
+	var out struct {
+		Query struct {
+			Pages []struct {
+				PageID    int64      `json:"pageid"`
+				Title     string     `json:"title"`
+				Revisions []Revision `json:"revisions"`
+			} `json:"pages"`
+		} `json:"query"`
+	}
+	if err := resp.Into(&out); err != nil {
+		return nil, err
+	}
+	if len(out.Query.Pages) == 0 || len(out.Query.Pages[0].Revisions) == 0 {
+		return nil, nil // missing page: the edit creates it
+	}
+	pg := out.Query.Pages[0]
+	rev := pg.Revisions[0]
+	if rev.SHA1 == "" || !strings.EqualFold(rev.SHA1, TextSHA1(params.Text)) {
+		return nil, nil
+	}
+	return &EditResult{
+		Result:       "Success",
+		PageID:       pg.PageID,
+		Title:        pg.Title,
+		OldRevID:     rev.RevID,
+		NewRevID:     rev.RevID,
+		NewTimestamp: rev.Timestamp,
+		NoChange:     true,
+	}, nil
+}
+
+// TextSHA1 returns the hex SHA-1 MediaWiki would record for wikitext saved as
+// text: line endings are normalized to \n and trailing whitespace is trimmed
+// before hashing, as on save.
+func TextSHA1(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	text = strings.TrimRight(text, " \t\n\r\v\x00")
+	sum := sha1.Sum([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// Page fetches a page with its latest revision. For a page that does not exist
+// it returns the flagged page together with ErrPageMissing.
+func (s *Session) Page(ctx context.Context, title string) (*Page, error) {
+	resp, err := s.c.Get(ctx, map[string]any{
+		"action":  "query",
+		"titles":  title,
+		"prop":    []string{"info", "revisions"},
+		"rvprop":  []string{"ids", "timestamp", "content"},
+		"rvslots": "main",
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := apiError(resp); err != nil {
+		return nil, err
+	}
+
+	pages, err := resp.Pages()
+	if err != nil {
+		return nil, err
+	}
+	if len(pages) == 0 {
+		return nil, errors.New("missing query.pages in response")
+	}
+
+	page := pages[0]
+	if page.Missing || page.Invalid {
+		return &page, fmt.Errorf("%w: %s", ErrPageMissing, title)
+	}
+	var revs struct {
+		Revisions []Revision `json:"revisions"`
+	}
+	if err := json.Unmarshal(page.Raw, &revs); err != nil {
+		return nil, err
+	}
+	if len(revs.Revisions) > 0 {
+		page.Content = revs.Revisions[0].MainContent()
+		page.Timestamp = revs.Revisions[0].Timestamp
+	}
+	return &page, nil
+}
+
+type UploadParams struct {
+	Filename       string
+	File           io.Reader
+	Comment        string
+	Text           string
+	IgnoreWarnings bool
+}
+
+type UploadResult struct {
+	Result   string         `json:"result"`
+	Filename string         `json:"filename"`
+	FileKey  string         `json:"filekey,omitempty"`
+	Warnings map[string]any `json:"warnings,omitempty"`
+}
+
+func (s *Session) Upload(ctx context.Context, params UploadParams) (*UploadResult, error) {
+	if params.Filename == "" {
+		return nil, errors.New("upload: missing filename")
+	}
+	if params.File == nil {
+		return nil, errors.New("upload: missing file")
+	}
+
+	p := map[string]any{
+		"action":         "upload",
+		"filename":       params.Filename,
+		"ignorewarnings": params.IgnoreWarnings,
+		"file":           File{Filename: params.Filename, Reader: params.File},
+	}
+	if params.Comment != "" {
+		p["comment"] = params.Comment
+	}
+	if params.Text != "" {
+		p["text"] = params.Text
+	}
+
+	resp, err := s.c.PostWithToken(ctx, TokenCSRF, p, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := apiError(resp); err != nil {
+		return nil, err
+	}
+
+	var out struct {
+		Upload *UploadResult `json:"upload"`
+	}
+	if err := resp.Into(&out); err != nil {
+		return nil, err
+	}
+	if out.Upload == nil || out.Upload.Result == "" {
+		return nil, errors.New("missing upload.result in response")
+	}
+	if !strings.EqualFold(out.Upload.Result, "success") {
+		return out.Upload, fmt.Errorf("upload failed: %s", out.Upload.Result)
+	}
+	return out.Upload, nil
+}
+
+// apiError returns the envelope error of resp (if any) regardless of throwOnApiError,
+// so typed helpers never silently decode an error response into a zero value.
+func apiError(resp *Response) error {
+	if resp == nil {
+		return nil
+	}
+	if e := responseApiError(resp); e != nil {
+		return asTooManyValues(e, nil)
+	}
+	return nil
+}
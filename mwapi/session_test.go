@@ -0,0 +1,486 @@
+package mwapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+func TestSession_LoginUserInfoEdit(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			_ = r.ParseMultipartForm(32 << 20)
+		} else {
+			_ = r.ParseForm()
+		}
+
+		action := r.Form.Get("action")
+		switch {
+		case action == "query" && r.Form.Get("meta") == "tokens" && r.Form.Get("type") == "login":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"query": map[string]any{"tokens": map[string]any{"logintoken": "LOGIN_TOKEN"}},
+			})
+		case action == "login":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "1", Path: "/"})
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"login": map[string]any{"result": "Success", "lguserid": 7, "lgusername": "UserA"},
+			})
+		case action == "query" && r.Form.Get("meta") == "userinfo":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"query": map[string]any{"userinfo": map[string]any{
+					"id": 7, "name": "UserA", "editcount": 42, "groups": []string{"*", "user"},
+				}},
+			})
+		case action == "query" && r.Form.Get("meta") == "tokens" && r.Form.Get("type") == "csrf":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"query": map[string]any{"tokens": map[string]any{"csrftoken": "CSRF_TOKEN"}},
+			})
+		case action == "edit":
+			if r.Form.Get("token") != "CSRF_TOKEN" {
+				t.Errorf("edit token=%q", r.Form.Get("token"))
+			}
+			if r.Form.Get("minor") != "1" || r.Form.Has("bot") {
+				t.Errorf("minor=%q bot=%q", r.Form.Get("minor"), r.Form.Get("bot"))
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"edit": map[string]any{
+					"result": "Success", "pageid": 12, "title": r.Form.Get("title"),
+					"oldrevid": 100, "newrevid": 101, "newtimestamp": "2026-01-01T00:00:00Z",
+				},
+			})
+		case action == "query" && r.Form.Get("titles") != "":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"query": map[string]any{"pages": []any{map[string]any{
+					"pageid": 12, "ns": 2, "title": r.Form.Get("titles"), "lastrevid": 101,
+					"revisions": []any{map[string]any{
+						"timestamp": "2026-01-01T00:00:00Z",
+						"slots":     map[string]any{"main": map[string]any{"content": "hello"}},
+					}},
+				}}},
+			})
+		case action == "upload":
+			f, hdr, err := r.FormFile("file")
+			if err != nil {
+				t.Errorf("FormFile: %v", err)
+				return
+			}
+			data, _ := io.ReadAll(f)
+			if string(data) != "PNGDATA" || hdr.Filename != "Demo.png" {
+				t.Errorf("upload file=%q name=%q", data, hdr.Filename)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"upload": map[string]any{"result": "Success", "filename": "Demo.png"},
+			})
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"error": map[string]any{"code": "badtest", "info": "unhandled request"},
+			})
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	s := NewSession(New(srv.URL + "/api.php"))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	if _, err := s.Login(ctx, "UserA", "pass"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+
+	ui, err := s.UserInfo(ctx)
+	if err != nil {
+		t.Fatalf("UserInfo: %v", err)
+	}
+	if ui.Name != "UserA" || ui.ID != 7 || ui.EditCount != 42 {
+		t.Fatalf("userinfo = %+v", ui)
+	}
+
+	title := "User:" + ui.Name + "/wiki-saikou-go"
+	edit, err := s.Edit(ctx, EditParams{Title: title, Text: "hello", Summary: "demo", Minor: true})
+	if err != nil {
+		t.Fatalf("Edit: %v", err)
+	}
+	if edit.Title != title || edit.NewRevID != 101 || edit.PageID != 12 {
+		t.Fatalf("edit = %+v", edit)
+	}
+
+	page, err := s.Page(ctx, title)
+	if err != nil {
+		t.Fatalf("Page: %v", err)
+	}
+	if page.Content != "hello" || page.LastRevID != 101 || page.Missing {
+		t.Fatalf("page = %+v", page)
+	}
+
+	up, err := s.Upload(ctx, UploadParams{Filename: "Demo.png", File: strings.NewReader("PNGDATA")})
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if up.Filename != "Demo.png" {
+		t.Fatalf("upload = %+v", up)
+	}
+}
+
+func TestSession_EditFailureIsError(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.Form.Get("meta") == "tokens" {
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"query": map[string]any{"tokens": map[string]any{"csrftoken": "CSRF_TOKEN"}},
+			})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"error": map[string]any{"code": "protectedpage", "info": "This page has been protected"},
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	s := NewSession(New(srv.URL + "/api.php"))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	_, err := s.Edit(ctx, EditParams{Title: "Main Page", Text: "x"})
+	e, ok := IsMediaWikiApiError(err)
+	if !ok || e.Code != "protectedpage" {
+		t.Fatalf("err = %v, want protectedpage", err)
+	}
+}
+
+func TestClientEdit_ByPageID(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("edit", func(r *mwtest.Request) any {
+		return map[string]any{"edit": map[string]any{
+			"result": "Success", "pageid": 12, "title": "Sandbox", "newrevid": 101, "newtimestamp": "2026-10-17T00:00:00Z",
+		}}
+	})
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	base := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	res, err := c.Edit(ctx, EditParams{PageID: 12, Text: "x", NoCreate: true, BaseTimestamp: base})
+	if err != nil {
+		t.Fatalf("Edit: %v", err)
+	}
+	if res.PageID != 12 || res.Title != "Sandbox" || res.NewRevID != 101 || res.NewTimestamp != "2026-10-17T00:00:00Z" {
+		t.Fatalf("result = %+v", res)
+	}
+	wiki.AssertSent("edit", "pageid", "12")
+	wiki.AssertSent("edit", "nocreate", "1")
+	wiki.AssertSent("edit", "basetimestamp", "2026-10-16T12:00:00Z")
+	wiki.AssertNotSent("edit", "title")
+	wiki.AssertNotSent("edit", "createonly")
+	wiki.AssertNotSent("edit", "starttimestamp")
+}
+
+func TestClientEdit_ValidatesParams(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	for _, p := range []EditParams{
+		{Text: "x"},
+		{Title: "Sandbox", PageID: 12, Text: "x"},
+		{Title: "Sandbox", Text: "x", CreateOnly: true, NoCreate: true},
+	} {
+		if _, err := c.Edit(ctx, p); err == nil {
+			t.Errorf("Edit(%+v) succeeded", p)
+		}
+	}
+	if n := len(wiki.Requests()); n != 0 {
+		t.Fatalf("requests = %d, want 0", n)
+	}
+}
+
+// sizedReader yields n bytes of 'a' without holding them in memory.
+type sizedReader struct{ n int }
+
+func (r *sizedReader) Read(p []byte) (int, error) {
+	if r.n == 0 {
+		return 0, io.EOF
+	}
+	k := min(len(p), r.n)
+	for i := range p[:k] {
+		p[i] = 'a'
+	}
+	r.n -= k
+	return k, nil
+}
+
+func TestClientEdit_StreamsTextReader(t *testing.T) {
+	t.Parallel()
+
+	const size = 4 << 20
+	var badTokenOnce atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		form, err := url.ParseQuery(string(raw))
+		if err != nil {
+			t.Errorf("ParseQuery: %v", err)
+		}
+		if form.Get("meta") == "tokens" {
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"query": map[string]any{"tokens": map[string]any{"csrftoken": "CSRF+\\"}},
+			})
+			return
+		}
+		if r.ContentLength != -1 {
+			t.Errorf("ContentLength = %d, want a streamed body", r.ContentLength)
+		}
+		if !strings.HasSuffix(string(raw), "&token=CSRF%2B%5C") {
+			t.Errorf("token is not the last field: ...%s", raw[max(0, len(raw)-40):])
+		}
+		text := form.Get("text")
+		if form.Get("title") != "Big" || (text != strings.Repeat("a", size) && text != "résumé & co") {
+			t.Errorf("title=%q len(text)=%d", form.Get("title"), len(text))
+		}
+		if text == "résumé & co" && badTokenOnce.CompareAndSwap(false, true) {
+			_ = json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"code": "badtoken", "info": "Invalid CSRF token."}})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"edit": map[string]any{"result": "Success", "title": "Big", "newrevid": 9}})
+	}))
+	t.Cleanup(srv.Close)
+
+	c := New(srv.URL+"/api.php", WithThrowOnApiError(true))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	res, err := c.Edit(ctx, EditParams{Title: "Big", TextReader: &sizedReader{n: size}})
+	if err != nil {
+		t.Fatalf("Edit: %v", err)
+	}
+	if res.NewRevID != 9 {
+		t.Fatalf("result = %+v", res)
+	}
+
+	// A seekable reader is rewound when the token retry resends the edit.
+	if _, err := c.Edit(ctx, EditParams{Title: "Big", TextReader: strings.NewReader("résumé & co")}); err != nil {
+		t.Fatalf("Edit(retry): %v", err)
+	}
+	if !badTokenOnce.Load() {
+		t.Fatal("token retry not exercised")
+	}
+}
+
+func TestStream_NotRewindableFailsRetry(t *testing.T) {
+	t.Parallel()
+
+	s := NewStream(&sizedReader{n: 10})
+	if _, err := s.open(); err != nil {
+		t.Fatalf("first open: %v", err)
+	}
+	if _, err := s.open(); !errors.Is(err, ErrStreamConsumed) {
+		t.Fatalf("second open err = %v, want ErrStreamConsumed", err)
+	}
+}
+
+func TestClientEdit_StrictWritesWarnings(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("edit", func(r *mwtest.Request) any {
+		return map[string]any{
+			"warnings": []any{
+				map[string]any{"code": "spamblacklist", "text": "The URL spam.example is blacklisted.", "module": "edit"},
+				map[string]any{"code": "deprecation", "text": "Deprecated parameter.", "module": "main"},
+			},
+			"edit": map[string]any{"result": "Success", "title": "Sandbox", "newrevid": 5},
+		}
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	params := EditParams{Title: "Sandbox", Text: "see spam.example"}
+	res, err := New(wiki.URL()).Edit(ctx, params)
+	if err != nil {
+		t.Fatalf("Edit: %v", err)
+	}
+	if len(res.Warnings) != 1 || res.Warnings[0].Code != "spamblacklist" {
+		t.Fatalf("warnings = %+v, want the edit module's spamblacklist", res.Warnings)
+	}
+
+	res, err = New(wiki.URL(), WithStrictWrites(true)).Edit(ctx, params)
+	var we *WriteWarningError
+	if !errors.As(err, &we) || !errors.Is(err, ErrWriteWarning) {
+		t.Fatalf("err = %v, want *WriteWarningError", err)
+	}
+	if we.Module != "edit" || len(we.Warnings) != 1 || res == nil || res.NewRevID != 5 {
+		t.Fatalf("error = %+v, result = %+v", we, res)
+	}
+}
+
+func TestDecodeWarnings_BC(t *testing.T) {
+	t.Parallel()
+
+	for name, raw := range map[string]string{
+		"fv2": `{"warnings":{"edit":{"warnings":"Hit AbuseFilter"}}}`,
+		"fv1": `{"warnings":{"edit":{"*":"Hit AbuseFilter"}}}`,
+	} {
+		got, err := moduleWarnings(json.RawMessage(raw), "edit")
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(got) != 1 || got[0].Text != "Hit AbuseFilter" || got[0].Module != "edit" {
+			t.Fatalf("%s: warnings = %+v", name, got)
+		}
+	}
+}
+
+func TestSession_EditCaptcha(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("edit", func(r *mwtest.Request) any {
+		switch {
+		case r.Param("captchaid") == "":
+			return map[string]any{"edit": map[string]any{
+				"result":  "Failure",
+				"captcha": map[string]any{"type": "simple", "mime": "text/plain", "id": 1234, "question": "2 + 3 = ?"},
+			}}
+		case r.Param("captchaword") != "5":
+			return map[string]any{"errors": []any{map[string]any{
+				"code": "captcha", "text": "Incorrect or missing CAPTCHA.",
+				"data": map[string]any{"captcha": map[string]any{"type": "simple", "id": "5678", "question": "4 + 4 = ?"}},
+			}}}
+		}
+		return map[string]any{"edit": map[string]any{"result": "Success", "title": r.Param("title"), "newrevid": 42}}
+	})
+
+	s := NewSession(New(wiki.URL()))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	params := EditParams{Title: "Sandbox", Text: "see http://example.com"}
+	_, err := s.Edit(ctx, params)
+	var ce *CaptchaError
+	if !errors.As(err, &ce) || !errors.Is(err, ErrCaptchaRequired) {
+		t.Fatalf("err = %v, want *CaptchaError", err)
+	}
+	if ce.ID != "1234" || ce.Type != "simple" || ce.Question != "2 + 3 = ?" || ce.Result == nil || ce.Result.Result != "Failure" {
+		t.Fatalf("captcha = %+v", ce)
+	}
+
+	_, err = s.ResolveCaptcha(ctx, params, ce.ID, "6")
+	if !errors.As(err, &ce) || ce.ID != "5678" || ce.Question != "4 + 4 = ?" {
+		t.Fatalf("wrong answer err = %v", err)
+	}
+
+	res, err := s.ResolveCaptcha(ctx, params, "1234", "5")
+	if err != nil {
+		t.Fatalf("ResolveCaptcha: %v", err)
+	}
+	if res.NewRevID != 42 {
+		t.Fatalf("result = %+v", res)
+	}
+	wiki.AssertSent("edit", "captchaid", "1234")
+	wiki.AssertSent("edit", "captchaword", "5")
+}
+
+func TestSession_EditSkipIfUnchanged(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("query", func(r *mwtest.Request) any {
+		return map[string]any{"query": map[string]any{"pages": []any{map[string]any{
+			"pageid": 7, "ns": 0, "title": "Sandbox",
+			"revisions": []any{map[string]any{"revid": 42, "timestamp": "2026-10-17T00:00:00Z", "sha1": TextSHA1("hello\nworld")}},
+		}}}}
+	})
+	wiki.Handle("edit", func(r *mwtest.Request) any {
+		return map[string]any{"edit": map[string]any{"result": "Success", "pageid": 7, "title": "Sandbox", "oldrevid": 42, "newrevid": 43}}
+	})
+	s := NewSession(New(wiki.URL()))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	// Same text after MediaWiki's normalization: CRLF and trailing whitespace.
+	res, err := s.Edit(ctx, EditParams{Title: "Sandbox", Text: "hello\r\nworld \n", SkipIfUnchanged: true})
+	if err != nil {
+		t.Fatalf("Edit(unchanged): %v", err)
+	}
+	if !res.NoChange || res.NewRevID != 42 {
+		t.Fatalf("result = %+v, want NoChange at revision 42", res)
+	}
+	if got := len(wiki.RequestsFor("edit")); got != 0 {
+		t.Fatalf("edit requests = %d, want 0", got)
+	}
+
+	res, err = s.Edit(ctx, EditParams{Title: "Sandbox", Text: "hello there", SkipIfUnchanged: true})
+	if err != nil {
+		t.Fatalf("Edit(changed): %v", err)
+	}
+	if res.NoChange || res.NewRevID != 43 {
+		t.Fatalf("result = %+v, want a real edit", res)
+	}
+	wiki.AssertSent("edit", "text", "hello there")
+}
+
+func TestTextSHA1(t *testing.T) {
+	t.Parallel()
+
+	// sha1("abc")
+	if got := TextSHA1("abc\r\n\n  "); got != "a9993e364706816aba3e25717850c26c9cd0d89d" {
+		t.Fatalf("TextSHA1 = %s", got)
+	}
+}
+
+func TestEditResult_Flags(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		raw  string
+		want EditResult
+	}{
+		"create": {
+			`{"result":"Success","pageid":9,"title":"New","contentmodel":"wikitext","oldrevid":0,"newrevid":100,"new":true,"watched":true}`,
+			EditResult{Result: "Success", PageID: 9, Title: "New", ContentModel: "wikitext", NewRevID: 100, New: true, Watched: true},
+		},
+		"null edit fv2": {
+			`{"result":"Success","pageid":9,"title":"New","contentmodel":"wikitext","nochange":true}`,
+			EditResult{Result: "Success", PageID: 9, Title: "New", ContentModel: "wikitext", NoChange: true},
+		},
+		"null edit fv1": {
+			`{"result":"Success","pageid":9,"title":"New","contentmodel":"wikitext","nochange":""}`,
+			EditResult{Result: "Success", PageID: 9, Title: "New", ContentModel: "wikitext", NoChange: true},
+		},
+		"normal": {
+			`{"result":"Success","pageid":9,"title":"New","contentmodel":"wikitext","oldrevid":100,"newrevid":101,"newtimestamp":"2026-10-17T00:00:00Z"}`,
+			EditResult{Result: "Success", PageID: 9, Title: "New", ContentModel: "wikitext", OldRevID: 100, NewRevID: 101, NewTimestamp: "2026-10-17T00:00:00Z"},
+		},
+		"spam blacklist": {
+			`{"result":"Failure","spamblacklist":"spam.example"}`,
+			EditResult{Result: "Failure", SpamBlacklist: "spam.example"},
+		},
+	}
+	for name, tc := range cases {
+		var got EditResult
+		if err := json.Unmarshal([]byte(tc.raw), &got); err != nil {
+			t.Fatalf("%s: Unmarshal: %v", name, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("%s: got %+v, want %+v", name, got, tc.want)
+		}
+	}
+}
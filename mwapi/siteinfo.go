@@ -0,0 +1,154 @@
+package mwapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+)
+
+// defaultSiteInfoProps are fetched by SiteInfo when no props are given.
+var defaultSiteInfoProps = []string{"general", "namespaces", "namespacealiases", "extensions"}
+
+// SiteInfo is the result of meta=siteinfo. Props other than the typed ones
+// are kept raw in Raw, keyed by prop name.
+type SiteInfo struct {
+	General          SiteGeneral
+	Namespaces       map[int]Namespace
+	NamespaceAliases []NamespaceAlias
+	Extensions       []Extension
+	Raw              map[string]json.RawMessage
+}
+
+type SiteGeneral struct {
+	SiteName    string `json:"sitename"`
+	MainPage    string `json:"mainpage"`
+	Base        string `json:"base"`
+	Generator   string `json:"generator"`
+	Lang        string `json:"lang"`
+	Case        string `json:"case"`
+	Server      string `json:"server"`
+	ArticlePath string `json:"articlepath"`
+	ScriptPath  string `json:"scriptpath"`
+	Script      string `json:"script"`
+	WikiID      string `json:"wikiid"`
+	Timezone    string `json:"timezone"`
+	// ReadOnly is set while the wiki is in read-only mode.
+	ReadOnly bool `json:"readonly,omitempty"`
+}
+
+type Namespace struct {
+	ID int `json:"id"`
+	// Name is the localized name, Canonical the English one ("" for ns 0).
+	Name      string `json:"name"`
+	Canonical string `json:"canonical,omitempty"`
+	// Case is "first-letter" when the first letter of titles is capitalized,
+	// "case-sensitive" otherwise.
+	Case          string `json:"case"`
+	Subpages      bool   `json:"subpages"`
+	Content       bool   `json:"content"`
+	NonIncludable bool   `json:"nonincludable"`
+}
+
+type NamespaceAlias struct {
+	ID    int    `json:"id"`
+	Alias string `json:"alias"`
+}
+
+type Extension struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	URL     string `json:"url,omitempty"`
+}
+
+// SiteInfo returns meta=siteinfo for props (general, namespaces,
+// namespacealiases and extensions by default). The result is cached for the
+// session and shared between callers; do not modify it. A call asking for
+// props not cached yet fetches them along with the cached ones.
+func (c *Client) SiteInfo(ctx context.Context, props ...string) (*SiteInfo, error) {
+	if len(props) == 0 {
+		props = defaultSiteInfoProps
+	}
+	c.mu.Lock()
+	si, cached := c.siteInfo, c.siteInfoProps
+	c.mu.Unlock()
+	if si != nil && !slices.ContainsFunc(props, func(p string) bool { return !slices.Contains(cached, p) }) {
+		return si, nil
+	}
+
+	all := slices.Clone(cached)
+	for _, p := range props {
+		if !slices.Contains(all, p) {
+			all = append(all, p)
+		}
+	}
+	return c.fetchSiteInfo(ctx, all)
+}
+
+// RefreshSiteInfo drops the cached siteinfo and fetches the props cached so
+// far (or the defaults) again.
+func (c *Client) RefreshSiteInfo(ctx context.Context) (*SiteInfo, error) {
+	c.mu.Lock()
+	props := c.siteInfoProps
+	c.mu.Unlock()
+	if len(props) == 0 {
+		props = defaultSiteInfoProps
+	}
+	return c.fetchSiteInfo(ctx, props)
+}
+
+func (c *Client) fetchSiteInfo(ctx context.Context, props []string) (*SiteInfo, error) {
+	resp, err := c.Get(ctx, map[string]any{
+		"action": "query",
+		"meta":   "siteinfo",
+		"siprop": props,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := apiError(resp); err != nil {
+		return nil, err
+	}
+	var out struct {
+		Query map[string]json.RawMessage `json:"query"`
+	}
+	if err := resp.Into(&out); err != nil {
+		return nil, err
+	}
+	if out.Query == nil {
+		return nil, errors.New("missing query in siteinfo response")
+	}
+
+	si := &SiteInfo{Namespaces: map[int]Namespace{}, Raw: out.Query}
+	if raw, ok := out.Query["general"]; ok {
+		if err := json.Unmarshal(raw, &si.General); err != nil {
+			return nil, err
+		}
+	}
+	if raw, ok := out.Query["namespaces"]; ok {
+		// formatversion=2 still returns an object keyed by namespace id.
+		var ns map[string]Namespace
+		if err := json.Unmarshal(raw, &ns); err != nil {
+			return nil, err
+		}
+		for _, n := range ns {
+			si.Namespaces[n.ID] = n
+		}
+	}
+	if raw, ok := out.Query["namespacealiases"]; ok {
+		if err := json.Unmarshal(raw, &si.NamespaceAliases); err != nil {
+			return nil, err
+		}
+	}
+	if raw, ok := out.Query["extensions"]; ok {
+		if err := json.Unmarshal(raw, &si.Extensions); err != nil {
+			return nil, err
+		}
+	}
+
+	c.mu.Lock()
+	c.siteInfo, c.siteInfoProps = si, slices.Clone(props)
+	c.mu.Unlock()
+	return si, nil
+}
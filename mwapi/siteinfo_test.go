@@ -0,0 +1,74 @@
+package mwapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+// siteInfoHandler answers meta=siteinfo like a zh wiki.
+func siteInfoHandler(r *mwtest.Request) any {
+	return map[string]any{"query": map[string]any{
+		"general": map[string]any{"sitename": "萌娘百科", "mainpage": "Mainpage", "lang": "zh-cn",
+			"case": "first-letter", "articlepath": "/$1", "generator": "MediaWiki 1.39.3"},
+		"namespaces": map[string]any{
+			"0":  map[string]any{"id": 0, "name": "", "case": "first-letter", "content": true},
+			"1":  map[string]any{"id": 1, "name": "讨论", "canonical": "Talk", "case": "first-letter", "subpages": true},
+			"6":  map[string]any{"id": 6, "name": "文件", "canonical": "File", "case": "first-letter"},
+			"14": map[string]any{"id": 14, "name": "Category", "canonical": "Category", "case": "first-letter"},
+		},
+		"namespacealiases": []any{map[string]any{"id": 6, "alias": "图片"}, map[string]any{"id": 6, "alias": "Image"}},
+		"extensions":       []any{map[string]any{"type": "parserhook", "name": "Cite", "version": "1.0"}},
+		"statistics":       map[string]any{"pages": 10},
+	}}
+}
+
+func TestSiteInfo(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("query", siteInfoHandler)
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	si, err := c.SiteInfo(ctx)
+	if err != nil {
+		t.Fatalf("SiteInfo: %v", err)
+	}
+	if si.General.ArticlePath != "/$1" || si.General.SiteName != "萌娘百科" {
+		t.Fatalf("General = %+v", si.General)
+	}
+	if ns := si.Namespaces[6]; ns.Name != "文件" || ns.Canonical != "File" {
+		t.Fatalf("ns 6 = %+v", ns)
+	}
+	if len(si.NamespaceAliases) != 2 || len(si.Extensions) != 1 || si.Extensions[0].Name != "Cite" {
+		t.Fatalf("aliases = %+v, extensions = %+v", si.NamespaceAliases, si.Extensions)
+	}
+	wiki.AssertSent("query", "siprop", "general|namespaces|namespacealiases|extensions")
+
+	if _, err := c.SiteInfo(ctx, "namespaces"); err != nil {
+		t.Fatalf("SiteInfo: %v", err)
+	}
+	if n := len(wiki.RequestsFor("query")); n != 1 {
+		t.Fatalf("query requests = %d, want 1 (cached)", n)
+	}
+
+	si, err = c.SiteInfo(ctx, "statistics")
+	if err != nil {
+		t.Fatalf("SiteInfo: %v", err)
+	}
+	if _, ok := si.Raw["statistics"]; !ok || len(si.Namespaces) == 0 {
+		t.Fatalf("statistics not merged into cache: %+v", si)
+	}
+	wiki.AssertSent("query", "siprop", "general|namespaces|namespacealiases|extensions|statistics")
+
+	if _, err := c.RefreshSiteInfo(ctx); err != nil {
+		t.Fatalf("RefreshSiteInfo: %v", err)
+	}
+	if n := len(wiki.RequestsFor("query")); n != 3 {
+		t.Fatalf("query requests = %d, want 3", n)
+	}
+}
@@ -0,0 +1,78 @@
+package mwapi
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// ClientStats are cumulative traffic counters of a client.
+type ClientStats struct {
+	Requests int64
+	// RequestBytes counts query strings and request bodies as sent.
+	RequestBytes int64
+	// ResponseBytes counts response bodies as read, after decompression.
+	ResponseBytes int64
+	// Relogins counts session re-establishments (see Relogin); it is always on.
+	Relogins int64
+}
+
+type statsCounters struct {
+	requests      atomic.Int64
+	requestBytes  atomic.Int64
+	responseBytes atomic.Int64
+}
+
+// WithStats enables the traffic counters reported by Stats. They are off by
+// default to keep the request path free of the extra bookkeeping.
+func WithStats(v bool) Option {
+	return func(c *Client) {
+		if !v {
+			c.stats = nil
+		} else if c.stats == nil {
+			c.stats = &statsCounters{}
+		}
+	}
+}
+
+// WithStatsHook enables the counters and calls fn with the current totals
+// before every HTTP request. fn can block to pause the client (e.g. when a
+// bandwidth quota is reached) or return an error to refuse the request.
+func WithStatsHook(fn func(ctx context.Context, s ClientStats) error) Option {
+	return func(c *Client) {
+		if c.stats == nil {
+			c.stats = &statsCounters{}
+		}
+		c.statsHook = fn
+	}
+}
+
+// Stats returns the totals so far; traffic counters are zero unless WithStats is on.
+func (c *Client) Stats() ClientStats {
+	s := ClientStats{Relogins: c.relogins.Load()}
+	if c.stats != nil {
+		s.Requests = c.stats.requests.Load()
+		s.RequestBytes = c.stats.requestBytes.Load()
+		s.ResponseBytes = c.stats.responseBytes.Load()
+	}
+	return s
+}
+
+func (c *Client) beforeRequest(ctx context.Context) error {
+	if c.statsHook == nil {
+		return nil
+	}
+	return c.statsHook(ctx, c.Stats())
+}
+
+func (c *Client) countRequest(n int64) {
+	if c.stats != nil {
+		c.stats.requests.Add(1)
+		c.stats.requestBytes.Add(n)
+	}
+}
+
+func (c *Client) countResponse(n int64) {
+	if c.stats != nil {
+		c.stats.responseBytes.Add(n)
+	}
+}
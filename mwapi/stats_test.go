@@ -0,0 +1,53 @@
+package mwapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+func TestStats_CountsTraffic(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("parse", func(r *mwtest.Request) any {
+		return map[string]any{"parse": map[string]any{"text": "<p>" + r.Param("text") + "</p>"}}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	if s := New(wiki.URL()).Stats(); s != (ClientStats{}) {
+		t.Fatalf("stats without WithStats = %+v", s)
+	}
+
+	c := New(wiki.URL(), WithStats(true))
+	var prev ClientStats
+	for i := 0; i < 2; i++ {
+		if _, err := c.Post(ctx, map[string]any{"action": "parse", "text": "hello"}); err != nil {
+			t.Fatalf("Post: %v", err)
+		}
+		s := c.Stats()
+		if s.Requests != prev.Requests+1 || s.RequestBytes <= prev.RequestBytes || s.ResponseBytes <= prev.ResponseBytes {
+			t.Fatalf("stats after request %d = %+v, previous %+v", i+1, s, prev)
+		}
+		prev = s
+	}
+
+	errQuota := errors.New("quota reached")
+	capped := New(wiki.URL(), WithStatsHook(func(ctx context.Context, s ClientStats) error {
+		if s.ResponseBytes > 0 {
+			return errQuota
+		}
+		return nil
+	}))
+	if _, err := capped.Post(ctx, map[string]any{"action": "parse", "text": "hello"}); err != nil {
+		t.Fatalf("first Post: %v", err)
+	}
+	if _, err := capped.Post(ctx, map[string]any{"action": "parse", "text": "hello"}); !errors.Is(err, errQuota) {
+		t.Fatalf("second Post err = %v, want quota error", err)
+	}
+}
@@ -0,0 +1,109 @@
+package mwapi
+
+import (
+	"errors"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrStreamConsumed is returned when a request carrying a Stream has to be
+// resent (token or maxlag retry, relogin) but the reader cannot be rewound.
+var ErrStreamConsumed = errors.New("stream already consumed and cannot be rewound")
+
+// Stream sends the contents of a reader as an ordinary form field (not a
+// file upload) without reading it into memory first, e.g. the text of a very
+// large edit. Pass it as a parameter value. An io.Seeker is rewound when the
+// request is resent; any other reader fails the retry with ErrStreamConsumed
+// rather than sending a truncated value.
+type Stream struct {
+	mu      sync.Mutex
+	r       io.Reader
+	started bool
+}
+
+func NewStream(r io.Reader) *Stream {
+	return &Stream{r: r}
+}
+
+// open returns the reader positioned at the start of the value.
+func (s *Stream) open() (io.Reader, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		sk, ok := s.r.(io.Seeker)
+		if !ok {
+			return nil, ErrStreamConsumed
+		}
+		if _, err := sk.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+	s.started = true
+	return s.r, nil
+}
+
+type streamField struct {
+	Field  string
+	Stream *Stream
+}
+
+// streamingFormBody encodes values followed by the streamed fields as an
+// application/x-www-form-urlencoded body. The token goes last, so a body cut
+// off mid-stream is rejected by MediaWiki instead of saved truncated.
+func streamingFormBody(values url.Values, streams []streamField) (io.Reader, error) {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		if k != "token" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var head strings.Builder
+	for _, k := range keys {
+		for _, v := range values[k] {
+			head.WriteString(url.QueryEscape(k) + "=" + url.QueryEscape(v) + "&")
+		}
+	}
+	parts := []io.Reader{strings.NewReader(head.String())}
+	for i, sf := range streams {
+		r, err := sf.Stream.open()
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			parts = append(parts, strings.NewReader("&"))
+		}
+		parts = append(parts, strings.NewReader(url.QueryEscape(sf.Field)+"="), &escapeReader{r: r})
+	}
+	if tok, ok := values["token"]; ok && len(tok) > 0 {
+		parts = append(parts, strings.NewReader("&token="+url.QueryEscape(tok[0])))
+	}
+	return io.MultiReader(parts...), nil
+}
+
+// escapeReader query-escapes r on the fly. Escaping is per byte, so chunk
+// boundaries (even inside a UTF-8 sequence) do not matter.
+type escapeReader struct {
+	r       io.Reader
+	buf     [16 << 10]byte
+	pending string
+	err     error
+}
+
+func (e *escapeReader) Read(p []byte) (int, error) {
+	for e.pending == "" {
+		if e.err != nil {
+			return 0, e.err
+		}
+		n, err := e.r.Read(e.buf[:])
+		e.pending = url.QueryEscape(string(e.buf[:n]))
+		e.err = err
+	}
+	n := copy(p, e.pending)
+	e.pending = e.pending[n:]
+	return n, nil
+}
@@ -0,0 +1,149 @@
+package mwapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+type ManageTagsResult struct {
+	Operation string          `json:"operation"`
+	Tag       string          `json:"tag"`
+	Success   json.RawMessage `json:"success"`
+	LogID     int64           `json:"logid,omitempty"`
+	// Warnings is set when the operation was refused only because of
+	// warnings; retry with ignoreWarnings to go ahead.
+	Warnings json.RawMessage `json:"warnings,omitempty"`
+}
+
+// ManageTags creates, deletes, activates or deactivates a change tag
+// (action=managetags); op is one of those four verbs.
+func (c *Client) ManageTags(ctx context.Context, op, tag, reason string, ignoreWarnings bool) (*ManageTagsResult, error) {
+	switch op {
+	case "create", "delete", "activate", "deactivate":
+	default:
+		return nil, fmt.Errorf("managetags: unknown operation %q", op)
+	}
+	if tag == "" {
+		return nil, errors.New("managetags: missing tag")
+	}
+	p := map[string]any{
+		"action":         "managetags",
+		"operation":      op,
+		"tag":            tag,
+		"ignorewarnings": ignoreWarnings,
+	}
+	if reason != "" {
+		p["reason"] = reason
+	}
+
+	resp, err := c.PostWithToken(ctx, TokenCSRF, p, nil)
+	if err != nil {
+		return nil, fmt.Errorf("managetags: %w", err)
+	}
+	if err := apiError(resp); err != nil {
+		return nil, fmt.Errorf("managetags: %w", err)
+	}
+	c.tagCache.invalidate()
+
+	var out struct {
+		ManageTags *ManageTagsResult `json:"managetags"`
+	}
+	if err := resp.Into(&out); err != nil {
+		return nil, err
+	}
+	if out.ManageTags == nil {
+		return nil, errors.New("missing managetags in response")
+	}
+	if !rawFlag(out.ManageTags.Success) {
+		return out.ManageTags, fmt.Errorf("managetags: %s %q refused with warnings", op, tag)
+	}
+	return out.ManageTags, nil
+}
+
+// WithTagValidation checks the tags parameter of every write against
+// ValidTags before sending it, failing with ErrBadTags instead of a server
+// round-trip. The tag list is cached for ttl; zero turns validation off.
+func WithTagValidation(ttl time.Duration) Option {
+	return func(c *Client) {
+		if ttl >= 0 {
+			c.tagCache.ttl = ttl
+		}
+	}
+}
+
+type tagCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	tags    []string
+	fetched time.Time
+}
+
+func (tc *tagCache) invalidate() {
+	tc.mu.Lock()
+	tc.tags = nil
+	tc.mu.Unlock()
+}
+
+// ValidTags returns the tags users and bots may apply: active tags that are
+// defined manually. With WithTagValidation the list is cached.
+func (c *Client) ValidTags(ctx context.Context) ([]string, error) {
+	c.tagCache.mu.Lock()
+	if c.tagCache.tags != nil && time.Since(c.tagCache.fetched) < c.tagCache.ttl {
+		tags := c.tagCache.tags
+		c.tagCache.mu.Unlock()
+		return slices.Clone(tags), nil
+	}
+	c.tagCache.mu.Unlock()
+
+	type tagEntry struct {
+		Name   string          `json:"name"`
+		Active json.RawMessage `json:"active"`
+		Source []string        `json:"source"`
+	}
+	tags := []string{}
+	for tg, err := range queryItems(ctx, c, map[string]any{
+		"action":  "query",
+		"list":    "tags",
+		"tgprop":  []string{"active", "source"},
+		"tglimit": "max",
+	}, listItems[tagEntry]("tags"), queryOptions{}) {
+		if err != nil {
+			return nil, err
+		}
+		if rawFlag(tg.Active) && slices.Contains(tg.Source, "manual") {
+			tags = append(tags, tg.Name)
+		}
+	}
+
+	c.tagCache.mu.Lock()
+	c.tagCache.tags, c.tagCache.fetched = tags, time.Now()
+	c.tagCache.mu.Unlock()
+	return slices.Clone(tags), nil
+}
+
+// checkTags fails with ErrBadTags if tags (a |-list) has tags ValidTags lacks.
+func (c *Client) checkTags(ctx context.Context, tags string) error {
+	if c.tagCache.ttl <= 0 || tags == "" {
+		return nil
+	}
+	valid, err := c.ValidTags(ctx)
+	if err != nil {
+		return fmt.Errorf("validate tags: %w", err)
+	}
+	var unknown []string
+	for _, t := range strings.Split(tags, "|") {
+		if !slices.Contains(valid, t) {
+			unknown = append(unknown, t)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("%w: %s", ErrBadTags, strings.Join(unknown, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package mwapi
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+func TestManageTags_Create(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("managetags", func(r *mwtest.Request) any {
+		return map[string]any{"managetags": map[string]any{
+			"operation": r.Param("operation"), "tag": r.Param("tag"), "success": true, "logid": 77,
+		}}
+	})
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	res, err := c.ManageTags(ctx, "create", "fleet-bot", "for bot edits", false)
+	if err != nil {
+		t.Fatalf("ManageTags: %v", err)
+	}
+	if res.Tag != "fleet-bot" || res.LogID != 77 {
+		t.Fatalf("result = %+v", res)
+	}
+	wiki.AssertSent("managetags", "operation", "create")
+	wiki.AssertSent("managetags", "reason", "for bot edits")
+	wiki.AssertNotSent("managetags", "ignorewarnings")
+
+	if _, err := c.ManageTags(ctx, "rename", "x", "", false); err == nil {
+		t.Fatalf("ManageTags accepted an unknown operation")
+	}
+}
+
+func TestTagValidation_RejectsUnknownTag(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("query", func(r *mwtest.Request) any {
+		return map[string]any{"batchcomplete": true, "query": map[string]any{"tags": []any{
+			map[string]any{"name": "fleet-bot", "active": true, "source": []any{"manual"}},
+			map[string]any{"name": "mw-replace", "active": true, "source": []any{"software"}},
+			map[string]any{"name": "retired", "source": []any{"manual"}},
+		}}}
+	})
+	wiki.Handle("edit", func(r *mwtest.Request) any {
+		return map[string]any{"edit": map[string]any{"result": "Success"}}
+	})
+	c := New(wiki.URL(), WithTagValidation(time.Minute))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	edit := func(tags string) error {
+		_, err := c.PostWithToken(ctx, TokenCSRF, map[string]any{"action": "edit", "title": "X", "text": "y", "tags": tags}, nil)
+		return err
+	}
+	if err := edit("fleet-bot"); err != nil {
+		t.Fatalf("edit with a valid tag: %v", err)
+	}
+	err := edit("fleet-bot|retired|mw-replace")
+	if !errors.Is(err, ErrBadTags) || !strings.Contains(err.Error(), "retired, mw-replace") {
+		t.Fatalf("err = %v, want ErrBadTags naming retired and mw-replace", err)
+	}
+	if got := len(wiki.RequestsFor("edit")); got != 1 {
+		t.Fatalf("edit requests = %d, want only the valid one sent", got)
+	}
+	lists := 0
+	for _, r := range wiki.RequestsFor("query") {
+		if r.Param("list") == "tags" {
+			lists++
+		}
+	}
+	if lists != 1 {
+		t.Fatalf("list=tags requests = %d, want 1 (cached)", lists)
+	}
+}
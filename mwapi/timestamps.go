@@ -0,0 +1,66 @@
+package mwapi
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	mwTimestampLayout  = "20060102150405"
+	isoTimestampLayout = "2006-01-02T15:04:05Z"
+)
+
+// MWTime is a time.Time that is sent as a 14-digit MediaWiki timestamp
+// (20240131120000) instead of ISO 8601. Plain time.Time params are sent as
+// ISO 8601 UTC, which every timestamp param accepts; use MWTime for the few
+// places that compare against the raw database format (e.g. continue values).
+type MWTime time.Time
+
+func (t MWTime) String() string { return FormatMWTimestamp(time.Time(t)) }
+
+// FormatMWTimestamp formats t in UTC as a 14-digit MediaWiki timestamp.
+func FormatMWTimestamp(t time.Time) string {
+	return t.UTC().Format(mwTimestampLayout)
+}
+
+// ParseMWTimestamp parses either a 14-digit MediaWiki timestamp or the ISO 8601
+// form used in API responses (2024-01-31T12:00:00Z).
+func ParseMWTimestamp(s string) (time.Time, error) {
+	if len(s) == len(mwTimestampLayout) {
+		if t, err := time.Parse(mwTimestampLayout, s); err == nil {
+			return t, nil
+		}
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid MediaWiki timestamp %q", s)
+	}
+	return t.UTC(), nil
+}
+
+// WithCurTimestamp adds curtimestamp=1 to query requests that do not set it,
+// so Response.CurTime reports the server time of each read. Pass it on as
+// EditParams.StartTimestamp to detect pages deleted while being edited.
+func WithCurTimestamp(v bool) Option {
+	return func(c *Client) {
+		if v {
+			WithActionDefaults("query", map[string]any{"curtimestamp": true})(c)
+			return
+		}
+		delete(c.actionDefaults["query"], "curtimestamp")
+	}
+}
+
+// CurTime returns the server time from curtimestamp; ok is false when the
+// response has none.
+func (r *Response) CurTime() (t time.Time, ok bool) {
+	if r == nil || r.CurTimestamp == "" {
+		return time.Time{}, false
+	}
+	t, err := ParseMWTimestamp(r.CurTimestamp)
+	return t, err == nil
+}
+
+func formatISOTimestamp(t time.Time) string {
+	return t.UTC().Format(isoTimestampLayout)
+}
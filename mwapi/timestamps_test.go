@@ -0,0 +1,87 @@
+package mwapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+func TestMWTimestamps_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	want := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+
+	if got := FormatMWTimestamp(want.In(time.FixedZone("JST", 9*3600))); got != "20240131120000" {
+		t.Fatalf("FormatMWTimestamp = %q", got)
+	}
+	for _, s := range []string{"20240131120000", "2024-01-31T12:00:00Z", "2024-01-31T21:00:00+09:00"} {
+		got, err := ParseMWTimestamp(s)
+		if err != nil {
+			t.Fatalf("ParseMWTimestamp(%q): %v", s, err)
+		}
+		if !got.Equal(want) {
+			t.Fatalf("ParseMWTimestamp(%q) = %v, want %v", s, got, want)
+		}
+	}
+	if _, err := ParseMWTimestamp("yesterday"); err == nil {
+		t.Fatalf("ParseMWTimestamp accepted garbage")
+	}
+
+	np, err := normalizeParams(map[string]any{
+		"rvstart":    want,
+		"rvend":      MWTime(want),
+		"rvcontinue": (*time.Time)(nil),
+		"ucstart":    time.Time{},
+	})
+	if err != nil {
+		t.Fatalf("normalizeParams: %v", err)
+	}
+	if got := np.Values.Get("rvstart"); got != "2024-01-31T12:00:00Z" {
+		t.Fatalf("rvstart = %q", got)
+	}
+	if got := np.Values.Get("rvend"); got != "20240131120000" {
+		t.Fatalf("rvend = %q", got)
+	}
+	if np.Values.Has("rvcontinue") || np.Values.Has("ucstart") {
+		t.Fatalf("zero times were sent: %v", np.Values)
+	}
+	for _, k := range []string{"rvstart", "rvend"} {
+		got, err := ParseMWTimestamp(np.Values.Get(k))
+		if err != nil || !got.Equal(want) {
+			t.Fatalf("round trip %s = %v, %v", k, got, err)
+		}
+	}
+}
+
+func TestWithCurTimestamp(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("query", func(r *mwtest.Request) any {
+		out := map[string]any{"query": map[string]any{}}
+		if r.Param("curtimestamp") != "" {
+			out["curtimestamp"] = "2024-01-31T12:00:00Z"
+		}
+		return out
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	resp, err := New(wiki.URL(), WithCurTimestamp(true)).Get(ctx, map[string]any{"action": "query"})
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got, ok := resp.CurTime(); !ok || !got.Equal(time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)) {
+		t.Fatalf("CurTime = %v, %v", got, ok)
+	}
+
+	resp, err = New(wiki.URL(), WithCurTimestamp(true), WithCurTimestamp(false)).Get(ctx, map[string]any{"action": "query"})
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if _, ok := resp.CurTime(); ok {
+		t.Fatalf("curtimestamp sent after WithCurTimestamp(false)")
+	}
+}
@@ -0,0 +1,171 @@
+package mwapi
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// TitleMap is one entry of query.normalized (or query.converted).
+type TitleMap struct {
+	From string
+	To   string
+	// FromEncoded is set when From was percent-decoded by the server.
+	FromEncoded bool
+}
+
+// RedirectMap is one entry of query.redirects.
+type RedirectMap struct {
+	From        string
+	To          string
+	ToFragment  string
+	ToInterwiki string
+}
+
+// InterwikiMap is one entry of query.interwiki: an input title that points
+// to another wiki and so has no page here.
+type InterwikiMap struct {
+	Title string
+	IW    string
+	URL   string
+}
+
+// Normalized returns how input titles were normalized (query.normalized).
+func (r *Response) Normalized() []TitleMap {
+	var m struct {
+		Query struct {
+			Normalized []struct {
+				From        string          `json:"from"`
+				To          string          `json:"to"`
+				FromEncoded json.RawMessage `json:"fromencoded"`
+			} `json:"normalized"`
+		} `json:"query"`
+	}
+	if err := r.Into(&m); err != nil {
+		return nil
+	}
+	out := make([]TitleMap, 0, len(m.Query.Normalized))
+	for _, n := range m.Query.Normalized {
+		out = append(out, TitleMap{From: n.From, To: n.To, FromEncoded: rawFlag(n.FromEncoded)})
+	}
+	return out
+}
+
+// Converted returns the titles converted to another language variant by
+// converttitles=1 (query.converted).
+func (r *Response) Converted() []TitleMap {
+	var m struct {
+		Query struct {
+			Converted []struct {
+				From string `json:"from"`
+				To   string `json:"to"`
+			} `json:"converted"`
+		} `json:"query"`
+	}
+	if err := r.Into(&m); err != nil {
+		return nil
+	}
+	out := make([]TitleMap, 0, len(m.Query.Converted))
+	for _, cv := range m.Query.Converted {
+		out = append(out, TitleMap{From: cv.From, To: cv.To})
+	}
+	return out
+}
+
+// Redirects returns the redirects resolved by redirects=1 (query.redirects).
+func (r *Response) Redirects() []RedirectMap {
+	var m struct {
+		Query struct {
+			Redirects []struct {
+				From        string `json:"from"`
+				To          string `json:"to"`
+				ToFragment  string `json:"tofragment"`
+				ToInterwiki string `json:"tointerwiki"`
+			} `json:"redirects"`
+		} `json:"query"`
+	}
+	if err := r.Into(&m); err != nil {
+		return nil
+	}
+	out := make([]RedirectMap, 0, len(m.Query.Redirects))
+	for _, rd := range m.Query.Redirects {
+		out = append(out, RedirectMap(rd))
+	}
+	return out
+}
+
+// Interwiki returns input titles recognized as interwiki links (query.interwiki).
+func (r *Response) Interwiki() []InterwikiMap {
+	var m struct {
+		Query struct {
+			Interwiki []struct {
+				Title string `json:"title"`
+				IW    string `json:"iw"`
+				URL   string `json:"url"`
+			} `json:"interwiki"`
+		} `json:"query"`
+	}
+	if err := r.Into(&m); err != nil {
+		return nil
+	}
+	out := make([]InterwikiMap, 0, len(m.Query.Interwiki))
+	for _, iw := range m.Query.Interwiki {
+		out = append(out, InterwikiMap(iw))
+	}
+	return out
+}
+
+// ResolveTitles maps each input title to its canonical form, applying
+// normalization and variant conversion and, with followRedirects, redirects.
+// Titles are sent in batches of 50, or 500 when the account has
+// apihighlimits. Titles the wiki left unchanged map to themselves.
+func (c *Client) ResolveTitles(ctx context.Context, titles []string, followRedirects bool) (map[string]string, error) {
+	batch := maxTitlesPerQuery
+	if len(titles) > batch && c.hasRight(ctx, "apihighlimits") {
+		batch = maxTitlesPerQueryHigh
+	}
+	out := make(map[string]string, len(titles))
+	for start := 0; start < len(titles); start += batch {
+		end := min(start+batch, len(titles))
+		chunk := titles[start:end]
+
+		p := map[string]any{
+			"action":        "query",
+			"titles":        chunk,
+			"converttitles": true,
+		}
+		if followRedirects {
+			p["redirects"] = true
+		}
+		resp, err := c.Get(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		if err := apiError(resp); err != nil {
+			return nil, err
+		}
+
+		next := map[string]string{}
+		for _, m := range resp.Normalized() {
+			next[m.From] = m.To
+		}
+		for _, m := range resp.Converted() {
+			next[m.From] = m.To
+		}
+		for _, m := range resp.Redirects() {
+			next[m.From] = m.To
+		}
+		for _, t := range chunk {
+			// Follow the chain; the bound guards against redirect loops.
+			cur := t
+			for i := 0; i < len(next)+1; i++ {
+				to, ok := next[cur]
+				if !ok || to == cur {
+					break
+				}
+				cur = to
+			}
+			out[t] = cur
+		}
+	}
+	return out, nil
+}
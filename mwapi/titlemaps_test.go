@@ -0,0 +1,113 @@
+package mwapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+func TestResponse_TitleMaps(t *testing.T) {
+	t.Parallel()
+
+	fv2 := &Response{Raw: json.RawMessage(`{"batchcomplete":true,"query":{
+		"normalized":[{"fromencoded":true,"from":"Main%20page","to":"Main page"},{"fromencoded":false,"from":"main page","to":"Main page"}],
+		"redirects":[{"from":"Main page","to":"Main Page","tofragment":"History"}],
+		"interwiki":[{"title":"en:Foo","iw":"en","url":"https://en.wikipedia.org/wiki/Foo"}],
+		"pages":[{"pageid":1,"ns":0,"title":"Main Page"}]}}`)}
+	fv1 := &Response{Raw: json.RawMessage(`{"batchcomplete":"","query":{
+		"normalized":[{"fromencoded":"","from":"Main%20page","to":"Main page"},{"from":"main page","to":"Main page"}],
+		"redirects":[{"from":"Main page","to":"Main Page","tofragment":"History"}],
+		"interwiki":[{"title":"en:Foo","iw":"en"}],
+		"pages":{"1":{"pageid":1,"ns":0,"title":"Main Page"}}}}`)}
+
+	for name, resp := range map[string]*Response{"fv2": fv2, "fv1": fv1} {
+		norm := resp.Normalized()
+		if len(norm) != 2 || norm[0] != (TitleMap{From: "Main%20page", To: "Main page", FromEncoded: true}) ||
+			norm[1] != (TitleMap{From: "main page", To: "Main page"}) {
+			t.Fatalf("%s: Normalized = %+v", name, norm)
+		}
+		redirs := resp.Redirects()
+		if len(redirs) != 1 || redirs[0] != (RedirectMap{From: "Main page", To: "Main Page", ToFragment: "History"}) {
+			t.Fatalf("%s: Redirects = %+v", name, redirs)
+		}
+		iw := resp.Interwiki()
+		if len(iw) != 1 || iw[0].Title != "en:Foo" || iw[0].IW != "en" {
+			t.Fatalf("%s: Interwiki = %+v", name, iw)
+		}
+	}
+	if got := fv2.Interwiki()[0].URL; got != "https://en.wikipedia.org/wiki/Foo" {
+		t.Fatalf("fv2 interwiki url = %q", got)
+	}
+
+	empty := &Response{Raw: json.RawMessage(`{"batchcomplete":true}`)}
+	if len(empty.Normalized()) != 0 || len(empty.Redirects()) != 0 || len(empty.Interwiki()) != 0 {
+		t.Fatalf("expected no mappings for a response without query")
+	}
+}
+
+func TestResolveTitles(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("query", func(r *mwtest.Request) any {
+		if r.Param("meta") == "userinfo" {
+			return map[string]any{"query": map[string]any{"userinfo": map[string]any{"name": "Bot", "rights": []string{"apihighlimits"}}}}
+		}
+		var normalized, converted, redirects []any
+		for _, title := range strings.Split(r.Param("titles"), "|") {
+			switch title {
+			case "main page":
+				normalized = append(normalized, map[string]any{"from": "main page", "to": "Main page"})
+				if r.Param("redirects") != "" {
+					redirects = append(redirects, map[string]any{"from": "Main page", "to": "Main Page"})
+				}
+			case "Loop":
+				if r.Param("redirects") != "" {
+					redirects = append(redirects, map[string]any{"from": "Loop", "to": "Loop"})
+				}
+			case "Color":
+				converted = append(converted, map[string]any{"from": "Color", "to": "Colour"})
+			}
+		}
+		return map[string]any{"query": map[string]any{"normalized": normalized, "converted": converted, "redirects": redirects}}
+	})
+
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	got, err := c.ResolveTitles(ctx, []string{"main page", "Color", "Loop", "Plain"}, true)
+	if err != nil {
+		t.Fatalf("ResolveTitles: %v", err)
+	}
+	want := map[string]string{"main page": "Main Page", "Color": "Colour", "Loop": "Loop", "Plain": "Plain"}
+	for k, v := range want {
+		if got[k] != v {
+			t.Fatalf("ResolveTitles = %v, want %v", got, want)
+		}
+	}
+	wiki.AssertSent("query", "converttitles", "1")
+
+	got, err = c.ResolveTitles(ctx, []string{"main page"}, false)
+	if err != nil || got["main page"] != "Main page" {
+		t.Fatalf("without redirects = %v, %v", got, err)
+	}
+
+	titles := make([]string, 600)
+	for i := range titles {
+		titles[i] = fmt.Sprintf("T%d", i)
+	}
+	before := len(wiki.RequestsFor("query"))
+	if _, err := c.ResolveTitles(ctx, titles, false); err != nil {
+		t.Fatalf("ResolveTitles: %v", err)
+	}
+	// One userinfo check, then batches of 500.
+	if n := len(wiki.RequestsFor("query")) - before; n != 3 {
+		t.Fatalf("query requests = %d, want 3", n)
+	}
+}
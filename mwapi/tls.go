@@ -0,0 +1,66 @@
+package mwapi
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// WithTLSConfig sets the TLS configuration of the client's transport, e.g. to
+// trust a private CA. It requires the transport to be an *http.Transport (the
+// default); a transport passed via WithTransport is cloned, not modified.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(c *Client) {
+		c.tlsConfig = cfg
+	}
+}
+
+// WithInsecureSkipVerify disables TLS certificate verification.
+//
+// WARNING: this makes the connection trivially interceptable, passwords and
+// session cookies included. It exists for local test wikis with self-signed
+// certificates only; never enable it against a production wiki. Prefer
+// WithTLSConfig with the test CA in RootCAs where possible.
+func WithInsecureSkipVerify(v bool) Option {
+	return func(c *Client) {
+		c.insecureSkipVerify = v
+	}
+}
+
+// applyTLSOptions runs after all options so it sees the final transport
+// regardless of option order.
+func (c *Client) applyTLSOptions() error {
+	if c.tlsConfig == nil && !c.insecureSkipVerify {
+		return nil
+	}
+	if c.tlsConfig != nil && c.insecureSkipVerify && !c.tlsConfig.InsecureSkipVerify {
+		return errors.New("conflicting TLS options: WithInsecureSkipVerify(true) with a WithTLSConfig that verifies certificates")
+	}
+
+	var tr *http.Transport
+	switch rt := c.hc.Transport.(type) {
+	case nil:
+		tr = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		tr = rt.Clone()
+	default:
+		return fmt.Errorf("TLS options need an *http.Transport, got %T", rt)
+	}
+
+	cfg := c.tlsConfig
+	if cfg == nil {
+		cfg = tr.TLSClientConfig
+	}
+	if cfg == nil {
+		cfg = &tls.Config{}
+	} else {
+		cfg = cfg.Clone()
+	}
+	if c.insecureSkipVerify {
+		cfg.InsecureSkipVerify = true
+	}
+	tr.TLSClientConfig = cfg
+	c.hc.Transport = tr
+	return nil
+}
@@ -0,0 +1,42 @@
+package mwapi
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTLSOptions(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"batchcomplete":true}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+	endpoint := srv.URL + "/api.php"
+
+	if _, err := New(endpoint).Get(ctx, nil); err == nil {
+		t.Fatalf("self-signed certificate was accepted without options")
+	}
+	if _, err := New(endpoint, WithInsecureSkipVerify(true)).Get(ctx, nil); err != nil {
+		t.Fatalf("WithInsecureSkipVerify: %v", err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+	if _, err := New(endpoint, WithTLSConfig(&tls.Config{RootCAs: pool})).Get(ctx, nil); err != nil {
+		t.Fatalf("WithTLSConfig: %v", err)
+	}
+
+	if _, err := NewClient(endpoint, WithTLSConfig(&tls.Config{RootCAs: pool}), WithInsecureSkipVerify(true)); err == nil {
+		t.Fatalf("conflicting TLS options were accepted")
+	}
+}
@@ -0,0 +1,137 @@
+package mwapi
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// TokenCache holds the tokens of one session in memory and deduplicates
+// concurrent fetches. Every client has its own unless WithSharedTokenCache is used.
+// Tokens are keyed by the logged-in user as well as the type, so a token of
+// a previous user (e.g. one fetched while SwitchUser ran) is never reused.
+type TokenCache struct {
+	mu      sync.Mutex
+	tokens  map[tokenKey]string
+	fetches map[tokenKey]*tokenFetch
+	sf      singleflight.Group
+}
+
+type tokenKey struct {
+	user string
+	typ  TokenType
+}
+
+func NewTokenCache() *TokenCache {
+	return &TokenCache{
+		tokens:  map[tokenKey]string{},
+		fetches: map[tokenKey]*tokenFetch{},
+	}
+}
+
+// WithSharedTokenCache makes the client keep its tokens (other than login
+// tokens) in cache, so a pool of workers fetches each token once.
+//
+// Only share a cache between clients that present the same session to the
+// wiki: the same logged-in user with the same cookies (e.g. clients built
+// with the same WithCookieJar) or the same OAuth token. Tokens are bound to
+// the session, so clients with different sessions would send each other's
+// tokens and fail with badtoken.
+func WithSharedTokenCache(cache *TokenCache) Option {
+	return func(c *Client) {
+		if cache != nil {
+			c.tokens = cache
+		}
+	}
+}
+
+func (tc *TokenCache) get(k tokenKey) string {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	return tc.tokens[k]
+}
+
+func (tc *TokenCache) set(k tokenKey, tok string) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.tokens[k] = tok
+}
+
+func (tc *TokenCache) delete(k tokenKey) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	delete(tc.tokens, k)
+}
+
+// clear drops every token, of every user, and reports which types were cached.
+func (tc *TokenCache) clear() []TokenType {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	seen := map[TokenType]bool{}
+	old := make([]TokenType, 0, len(tc.tokens))
+	for k := range tc.tokens {
+		if !seen[k.typ] {
+			seen[k.typ] = true
+			old = append(old, k.typ)
+		}
+	}
+	tc.tokens = map[tokenKey]string{}
+	return old
+}
+
+// tokenFetch tracks the callers waiting on one in-flight token request.
+type tokenFetch struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	waiters int
+}
+
+func tokenFetchKey(k tokenKey) string {
+	return "token:" + k.user + "\x00" + string(k.typ)
+}
+
+// join registers the caller as a waiter on the in-flight fetch for k,
+// starting one with fetch if needed. Registration and DoChan happen under tc.mu
+// so a waiter can never join a fetch that is being torn down.
+func (tc *TokenCache) join(ctx context.Context, k tokenKey, fetch func(context.Context) (string, error)) (*tokenFetch, <-chan singleflight.Result) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	f := tc.fetches[k]
+	if f == nil {
+		fctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		f = &tokenFetch{ctx: fctx, cancel: cancel}
+		tc.fetches[k] = f
+	}
+	f.waiters++
+	ch := tc.sf.DoChan(tokenFetchKey(k), func() (any, error) {
+		defer tc.finish(k, f)
+		return fetch(f.ctx)
+	})
+	return f, ch
+}
+
+func (tc *TokenCache) leave(k tokenKey, f *tokenFetch, abandoned bool) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	f.waiters--
+	if !abandoned || f.waiters > 0 {
+		return
+	}
+	// Nobody wants the result any more: stop the request, and make sure the
+	// next caller starts a fresh fetch instead of joining this one.
+	if tc.fetches[k] == f {
+		delete(tc.fetches, k)
+		tc.sf.Forget(tokenFetchKey(k))
+	}
+	f.cancel()
+}
+
+func (tc *TokenCache) finish(k tokenKey, f *tokenFetch) {
+	tc.mu.Lock()
+	if tc.fetches[k] == f {
+		delete(tc.fetches, k)
+	}
+	tc.mu.Unlock()
+	f.cancel()
+}
@@ -0,0 +1,86 @@
+package mwapi
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+func TestSharedTokenCache_SingleFetch(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	cache := NewTokenCache()
+	clients := []*Client{
+		New(wiki.URL(), WithSharedTokenCache(cache)),
+		New(wiki.URL(), WithSharedTokenCache(cache)),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(c *Client) {
+			defer wg.Done()
+			if _, err := c.GetToken(ctx, TokenCSRF); err != nil {
+				t.Errorf("GetToken: %v", err)
+			}
+		}(clients[i%len(clients)])
+	}
+	wg.Wait()
+
+	fetches := 0
+	for _, r := range wiki.RequestsFor("query") {
+		if r.Param("meta") == "tokens" {
+			fetches++
+		}
+	}
+	if fetches != 1 {
+		t.Fatalf("token fetches = %d, want 1 across both clients", fetches)
+	}
+
+	clients[1].InvalidateToken(TokenCSRF)
+	if tok := cache.get(clients[1].tokenKey(TokenCSRF)); tok != "" {
+		t.Fatalf("InvalidateToken on one client left %q in the shared cache", tok)
+	}
+}
+
+func TestSwitchUser_FetchesNewToken(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	if _, err := c.Login(ctx, "UserA", "pass"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	tokA, err := c.GetToken(ctx, TokenCSRF)
+	if err != nil {
+		t.Fatalf("GetToken(UserA): %v", err)
+	}
+
+	if _, err := c.SwitchUser(ctx, "UserB", "pass"); err != nil {
+		t.Fatalf("SwitchUser: %v", err)
+	}
+	// A fetch for UserA that lands after the switch stays with UserA.
+	c.tokens.set(tokenKey{user: "UserA", typ: TokenCSRF}, tokA)
+
+	tokB, err := c.GetToken(ctx, TokenCSRF)
+	if err != nil {
+		t.Fatalf("GetToken(UserB): %v", err)
+	}
+	if tokB == tokA {
+		t.Fatalf("GetToken after SwitchUser returned the previous user's token %q", tokA)
+	}
+	last := wiki.RequestsFor("query")
+	if r := last[len(last)-1]; r.Param("meta") != "tokens" || r.User != "UserB" {
+		t.Fatalf("last query = %v as %q, want a token fetch as UserB", r.Form, r.User)
+	}
+}
@@ -4,9 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"strings"
-
-	"golang.org/x/sync/singleflight"
 )
 
 type PostWithTokenOptions struct {
@@ -16,60 +15,164 @@ type PostWithTokenOptions struct {
 }
 
 func (c *Client) InvalidateToken(tokenType TokenType) {
-	c.mu.Lock()
-	delete(c.tokens, tokenType)
-	c.mu.Unlock()
-}
+	c.tokenCache(tokenType).delete(c.tokenKey(tokenType))
 
-func (c *Client) InvalidateAllTokens() {
-	c.mu.Lock()
-	c.tokens = map[TokenType]string{}
-	c.mu.Unlock()
+	if store := c.storeFor(tokenType); store != nil {
+		store.Delete(TokenKey{Session: c.sessionKey(), Type: tokenType})
+	}
 }
 
-func (c *Client) GetToken(ctx context.Context, tokenType TokenType) (string, error) {
-	c.mu.Lock()
-	if tok := c.tokens[tokenType]; tok != "" {
-		c.mu.Unlock()
-		return tok, nil
+func (c *Client) InvalidateAllTokens() {
+	old := c.tokens.clear()
+	if c.loginTokens != c.tokens {
+		old = append(old, c.loginTokens.clear()...)
 	}
-	c.mu.Unlock()
 
-	// Prevent token stampede within a single process.
-	v, err, _ := c.tokenSF().Do("token:"+string(tokenType), func() (any, error) {
-		c.mu.Lock()
-		if tok := c.tokens[tokenType]; tok != "" {
-			c.mu.Unlock()
-			return tok, nil
+	if c.tokenStore != nil {
+		session := c.sessionKey()
+		for _, tokenType := range old {
+			if store := c.storeFor(tokenType); store != nil {
+				store.Delete(TokenKey{Session: session, Type: tokenType})
+			}
 		}
-		c.mu.Unlock()
+	}
+}
 
-		resp, err := c.Post(ctx, map[string]any{
-			"action": "query",
-			"meta":   "tokens",
-			"type":   string(tokenType),
-		})
-		if err != nil {
+func (c *Client) GetToken(ctx context.Context, tokenType TokenType) (_ string, err error) {
+	ctx, done := c.startCall(ctx)
+	defer func() { err = done(err) }()
+
+	if tokenType != TokenLogin && c.keepLogin {
+		if err := c.identifyOAuthUser(ctx); err != nil {
 			return "", err
 		}
+	}
+	tc := c.tokenCache(tokenType)
+	key := c.tokenKey(tokenType)
+	if tok := tc.get(key); tok != "" {
+		return tok, nil
+	}
 
-		tok, err := extractToken(resp.Raw, tokenType)
-		if err != nil {
-			return "", err
+	// Prevent token stampede within a single process. The shared fetch runs on
+	// a context detached from whichever caller started it, so one caller giving
+	// up does not fail the others; it is canceled only once every caller has.
+	f, ch := tc.join(ctx, key, func(ctx context.Context) (string, error) {
+		return c.fetchToken(ctx, tc, key)
+	})
+
+	select {
+	case r := <-ch:
+		tc.leave(key, f, false)
+		if r.Err != nil {
+			return "", r.Err
 		}
+		return r.Val.(string), nil
+	case <-ctx.Done():
+		tc.leave(key, f, true)
+		return "", canceled(ctx, nil)
+	}
+}
+
+// tokenKey scopes tokenType to the user the client is logged in as right now.
+func (c *Client) tokenKey(tokenType TokenType) tokenKey {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return tokenKey{user: c.loggedInUser, typ: tokenType}
+}
 
-		c.mu.Lock()
-		c.tokens[tokenType] = tok
-		c.mu.Unlock()
+// fetchToken stores the token under k even if the client has switched users
+// meanwhile, so it can never be served to the new user.
+func (c *Client) fetchToken(ctx context.Context, tc *TokenCache, k tokenKey) (string, error) {
+	tokenType := k.typ
+	if tok := tc.get(k); tok != "" {
 		return tok, nil
+	}
+
+	store := c.storeFor(tokenType)
+	key := TokenKey{Session: c.sessionKey(), Type: tokenType}
+	if store != nil {
+		if tok, ok := store.Get(key); ok && tok != "" {
+			tc.set(k, tok)
+			return tok, nil
+		}
+	}
+
+	resp, err := c.Post(ctx, map[string]any{
+		"action": "query",
+		"meta":   "tokens",
+		"type":   string(tokenType),
 	})
 	if err != nil {
 		return "", err
 	}
-	return v.(string), nil
+
+	tok, err := extractToken(resp.Raw, tokenType)
+	if err != nil {
+		return "", err
+	}
+
+	tc.set(k, tok)
+	if store != nil {
+		store.Set(key, tok)
+	}
+	return tok, nil
+}
+
+// WarmTokens fetches several token types (default: csrf) in one meta=tokens
+// request and caches them, so the first writes after login skip the round-trip.
+func (c *Client) WarmTokens(ctx context.Context, types ...TokenType) (err error) {
+	ctx, done := c.startCall(ctx)
+	defer func() { err = done(err) }()
+
+	if len(types) == 0 {
+		types = []TokenType{TokenCSRF}
+	}
+	names := make([]string, 0, len(types))
+	keys := make([]tokenKey, 0, len(types))
+	for _, t := range types {
+		names = append(names, string(t))
+		keys = append(keys, c.tokenKey(t))
+	}
+
+	resp, err := c.Post(ctx, map[string]any{
+		"action": "query",
+		"meta":   "tokens",
+		"type":   names,
+	})
+	if err != nil {
+		return err
+	}
+	if err := apiError(resp); err != nil {
+		return err
+	}
+
+	session := c.sessionKey()
+	for i, t := range types {
+		tok, err := extractToken(resp.Raw, t)
+		if err != nil {
+			return err
+		}
+		c.tokenCache(t).set(keys[i], tok)
+		if store := c.storeFor(t); store != nil {
+			store.Set(TokenKey{Session: session, Type: t}, tok)
+		}
+	}
+	return nil
+}
+
+// tokenCache returns where tokens of tokenType live. Login tokens are bound to
+// this client's pre-login session, so they never go to a shared cache.
+func (c *Client) tokenCache(tokenType TokenType) *TokenCache {
+	if tokenType == TokenLogin {
+		return c.loginTokens
+	}
+	return c.tokens
 }
 
-func (c *Client) PostWithToken(ctx context.Context, tokenType TokenType, p map[string]any, opt *PostWithTokenOptions) (*Response, error) {
+func (c *Client) PostWithToken(ctx context.Context, tokenType TokenType, p map[string]any, opt *PostWithTokenOptions, reqOpts ...RequestOption) (_ *Response, err error) {
+	ctx, done := c.startCall(ctx)
+	defer func() { err = done(err) }()
+
 	tokenName := "token"
 	retry := c.tokenRetry
 	noCache := false
@@ -85,6 +188,13 @@ func (c *Client) PostWithToken(ctx context.Context, tokenType TokenType, p map[s
 		}
 	}
 
+	ctx = c.ensureRequestID(ctx)
+
+	// Fail write guards before spending a round-trip on the token.
+	if err := c.checkWrite(http.MethodPost, actionOf(p)); err != nil {
+		return nil, err
+	}
+
 	p2 := map[string]any{}
 	for k, v := range p {
 		p2[k] = v
@@ -92,6 +202,9 @@ func (c *Client) PostWithToken(ctx context.Context, tokenType TokenType, p map[s
 
 	var lastErr error
 	for attempt := 0; attempt < retry; attempt++ {
+		if ctx.Err() != nil {
+			return nil, canceled(ctx, lastErr)
+		}
 		if attempt > 0 || noCache {
 			c.InvalidateToken(tokenType)
 		}
@@ -102,13 +215,15 @@ func (c *Client) PostWithToken(ctx context.Context, tokenType TokenType, p map[s
 		}
 		p2[tokenName] = tok
 
-		resp, err := c.Post(ctx, p2)
+		resp, err := c.Post(ctx, p2, reqOpts...)
 		if err == nil {
 			// Even when throwOnApiError=false, token errors can appear in envelope.
 			if code := responseErrorCode(resp); isTokenErrorCode(code) {
 				lastErr = &MediaWikiApiError{
 					Code:       code,
 					Message:    "token error",
+					Action:     resp.action,
+					RequestURL: resp.requestURL,
 					HTTPStatus: resp.StatusCode,
 					Response:   resp,
 				}
@@ -130,6 +245,13 @@ func (c *Client) PostWithToken(ctx context.Context, tokenType TokenType, p map[s
 	return nil, fmt.Errorf("token retry exhausted: %w", lastErr)
 }
 
+func actionOf(p map[string]any) string {
+	if a, ok := p["action"].(string); ok {
+		return strings.ToLower(a)
+	}
+	return "query"
+}
+
 func responseErrorCode(resp *Response) string {
 	if resp == nil {
 		return ""
@@ -160,13 +282,3 @@ func extractToken(raw json.RawMessage, tokenType TokenType) (string, error) {
 	}
 	return tok, nil
 }
-
-func (c *Client) tokenSF() *singleflight.Group {
-	// singleflight.Group has no internal state that needs init; keep it in Client without pointer.
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	if c._sf == nil {
-		c._sf = &singleflight.Group{}
-	}
-	return c._sf
-}
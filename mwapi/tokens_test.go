@@ -0,0 +1,188 @@
+package mwapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+// blockingTokenServer answers meta=tokens only once release is closed, and
+// reports on aborted when a token request is abandoned by the client.
+func blockingTokenServer(t *testing.T) (srv *httptest.Server, started, release, aborted chan struct{}) {
+	started = make(chan struct{}, 8)
+	release = make(chan struct{})
+	aborted = make(chan struct{}, 8)
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server only notices a client hang-up once the body has been read.
+		_ = r.ParseForm()
+		started <- struct{}{}
+		select {
+		case <-release:
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"query":{"tokens":{"csrftoken":"abc+\\"}}}`))
+		case <-r.Context().Done():
+			aborted <- struct{}{}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() {
+		select {
+		case <-release:
+		default:
+			close(release)
+		}
+	})
+	return srv, started, release, aborted
+}
+
+func TestGetToken_CancelsFetchWhenAllCallersLeave(t *testing.T) {
+	t.Parallel()
+
+	srv, started, _, aborted := blockingTokenServer(t)
+	c := New(srv.URL + "/api.php")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = c.GetToken(ctx, TokenCSRF)
+		}(i)
+	}
+
+	<-started
+	cancel()
+	wg.Wait()
+	for _, err := range errs {
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("GetToken err = %v, want context.Canceled", err)
+		}
+	}
+
+	select {
+	case <-aborted:
+	case <-time.After(3 * time.Second):
+		t.Fatalf("token fetch kept running after every caller canceled")
+	}
+
+	c.tokens.mu.Lock()
+	inflight := len(c.tokens.fetches)
+	c.tokens.mu.Unlock()
+	if inflight != 0 {
+		t.Fatalf("in-flight token fetches = %d, want 0", inflight)
+	}
+}
+
+func TestGetToken_SurvivesLeaderCancel(t *testing.T) {
+	t.Parallel()
+
+	srv, started, release, aborted := blockingTokenServer(t)
+	c := New(srv.URL + "/api.php")
+
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := c.GetToken(leaderCtx, TokenCSRF)
+		leaderErr <- err
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+	tok := make(chan string, 1)
+	go func() {
+		v, err := c.GetToken(ctx, TokenCSRF)
+		if err != nil {
+			t.Errorf("waiter GetToken: %v", err)
+		}
+		tok <- v
+	}()
+
+	// Give the waiter time to join the in-flight fetch before the leader leaves.
+	deadline := time.Now().Add(time.Second)
+	for {
+		c.tokens.mu.Lock()
+		f := c.tokens.fetches[tokenKey{typ: TokenCSRF}]
+		joined := f != nil && f.waiters == 2
+		c.tokens.mu.Unlock()
+		if joined || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancelLeader()
+	if err := <-leaderErr; !errors.Is(err, context.Canceled) {
+		t.Fatalf("leader err = %v, want context.Canceled", err)
+	}
+	close(release)
+
+	if got := <-tok; got != `abc+\` {
+		t.Fatalf("waiter token = %q", got)
+	}
+	select {
+	case <-aborted:
+		t.Fatalf("fetch was aborted although a caller was still waiting")
+	default:
+	}
+}
+
+func TestWarmTokens_OneRequestForSeveralTypes(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	if _, err := c.Login(ctx, "UserA", "pass"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	before := len(wiki.RequestsFor("query"))
+
+	if err := c.WarmTokens(ctx, TokenCSRF, "watch", "patrol"); err != nil {
+		t.Fatalf("WarmTokens: %v", err)
+	}
+	reqs := wiki.RequestsFor("query")[before:]
+	if len(reqs) != 1 || reqs[0].Param("type") != "csrf|watch|patrol" {
+		t.Fatalf("warm-up requests = %d (type=%q), want 1", len(reqs), reqs[len(reqs)-1].Param("type"))
+	}
+
+	for _, typ := range []TokenType{TokenCSRF, "watch", "patrol"} {
+		if _, err := c.GetToken(ctx, typ); err != nil {
+			t.Fatalf("GetToken(%s): %v", typ, err)
+		}
+	}
+	if got := len(wiki.RequestsFor("query")) - before; got != 1 {
+		t.Fatalf("token requests after warm-up = %d, want 1", got)
+	}
+}
+
+func TestExtractToken_AllTypes(t *testing.T) {
+	t.Parallel()
+
+	raw := json.RawMessage(`{"batchcomplete":true,"query":{"tokens":{
+		"csrftoken":"c+\\","logintoken":"l+\\","watchtoken":"w+\\","patroltoken":"p+\\",
+		"rollbacktoken":"r+\\","userrightstoken":"u+\\","createaccounttoken":"a+\\",
+		"deleteglobalaccounttoken":"d+\\"}}}`)
+	want := map[TokenType]string{
+		TokenCSRF: "c+\\", TokenLogin: "l+\\", TokenWatch: "w+\\", TokenPatrol: "p+\\",
+		TokenRollback: "r+\\", TokenUserRights: "u+\\", TokenCreateAccount: "a+\\",
+		TokenDeleteGlobalAccount: "d+\\",
+	}
+	for typ, tok := range want {
+		got, err := extractToken(raw, typ)
+		if err != nil || got != tok {
+			t.Fatalf("extractToken(%s) = %q, %v; want %q", typ, got, err, tok)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package mwapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+)
+
+// TokenKey identifies a cached token. Session is an opaque fingerprint of the
+// client's session (cookies or OAuth token), so a token is only ever reused by
+// clients that present the same session to the wiki.
+type TokenKey struct {
+	Session string
+	Type    TokenType
+}
+
+// TokenStore is an external token cache (Redis, memcached, ...) shared across
+// processes. Implementations must be safe for concurrent use.
+type TokenStore interface {
+	Get(key TokenKey) (string, bool)
+	Set(key TokenKey, token string)
+	Delete(key TokenKey)
+}
+
+func WithTokenStore(s TokenStore) Option {
+	return func(c *Client) {
+		c.tokenStore = s
+	}
+}
+
+// sessionKey fingerprints the credentials the wiki sees for this client.
+func (c *Client) sessionKey() string {
+	h := sha256.New()
+	if c.oauthToken != "" {
+		h.Write([]byte("oauth\x00" + c.oauthToken))
+	} else if c.hc.Jar != nil {
+		cookies := c.hc.Jar.Cookies(c.endpoint)
+		pairs := make([]string, 0, len(cookies))
+		for _, ck := range cookies {
+			pairs = append(pairs, ck.Name+"="+ck.Value)
+		}
+		if len(pairs) == 0 {
+			return "anon"
+		}
+		sort.Strings(pairs)
+		for _, p := range pairs {
+			h.Write([]byte(p + "\x00"))
+		}
+	} else {
+		return "anon"
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Login tokens are bound to the pre-login session and are never shared.
+func (c *Client) storeFor(tokenType TokenType) TokenStore {
+	if tokenType == TokenLogin {
+		return nil
+	}
+	return c.tokenStore
+}
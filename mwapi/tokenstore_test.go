@@ -0,0 +1,86 @@
+package mwapi
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+type memTokenStore struct {
+	mu sync.Mutex
+	m  map[TokenKey]string
+}
+
+func (s *memTokenStore) Get(key TokenKey) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tok, ok := s.m[key]
+	return tok, ok
+}
+
+func (s *memTokenStore) Set(key TokenKey, token string) {
+	s.mu.Lock()
+	s.m[key] = token
+	s.mu.Unlock()
+}
+
+func (s *memTokenStore) Delete(key TokenKey) {
+	s.mu.Lock()
+	delete(s.m, key)
+	s.mu.Unlock()
+}
+
+func TestTokenStore_SharedPerSession(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	store := &memTokenStore{m: map[TokenKey]string{}}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	a := New(wiki.URL(), WithTokenStore(store))
+	if _, err := a.Login(ctx, "UserA", "pass"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	tokA, err := a.GetToken(ctx, TokenCSRF)
+	if err != nil {
+		t.Fatalf("GetToken(a): %v", err)
+	}
+
+	// Another instance resuming the same session reuses the stored token.
+	b := New(wiki.URL(), WithTokenStore(store))
+	b.hc.Jar.SetCookies(a.endpoint, a.hc.Jar.Cookies(a.endpoint))
+	fetches := len(wiki.RequestsFor("query"))
+	tokB, err := b.GetToken(ctx, TokenCSRF)
+	if err != nil {
+		t.Fatalf("GetToken(b): %v", err)
+	}
+	if tokB != tokA {
+		t.Fatalf("same-session token = %q, want %q", tokB, tokA)
+	}
+	if got := len(wiki.RequestsFor("query")); got != fetches {
+		t.Fatalf("token was fetched again despite the store")
+	}
+
+	// A different session must not see it.
+	c := New(wiki.URL(), WithTokenStore(store))
+	if _, err := c.Login(ctx, "UserB", "pass"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	tokC, err := c.GetToken(ctx, TokenCSRF)
+	if err != nil {
+		t.Fatalf("GetToken(c): %v", err)
+	}
+	if tokC == tokA {
+		t.Fatalf("token leaked across sessions")
+	}
+
+	// Invalidation removes the shared entry.
+	a.InvalidateToken(TokenCSRF)
+	if _, ok := store.Get(TokenKey{Session: a.sessionKey(), Type: TokenCSRF}); ok {
+		t.Fatalf("InvalidateToken did not delete from the store")
+	}
+}
@@ -2,20 +2,38 @@ package mwapi
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type TokenType string
 
 const (
-	TokenCSRF  TokenType = "csrf"
-	TokenLogin TokenType = "login"
+	TokenCSRF                TokenType = "csrf"
+	TokenLogin               TokenType = "login"
+	TokenWatch               TokenType = "watch"
+	TokenPatrol              TokenType = "patrol"
+	TokenRollback            TokenType = "rollback"
+	TokenUserRights          TokenType = "userrights"
+	TokenCreateAccount       TokenType = "createaccount"
+	TokenDeleteGlobalAccount TokenType = "deleteglobalaccount"
 )
 
 type MWError struct {
 	Code string `json:"code"`
 	Info string `json:"info,omitempty"`
 	Text string `json:"text,omitempty"`
+	// Module is the API module the error or warning came from (edit, query, ...).
+	Module string `json:"module,omitempty"`
+	// Key and Params are the message key and its parameters, sent with
+	// errorformat=raw for callers that localize messages themselves.
+	Key    string `json:"key,omitempty"`
+	Params []any  `json:"params,omitempty"`
+	// Data carries structured details some errors add (e.g. toomanyvalues limits).
+	Data json.RawMessage `json:"data,omitempty"`
 }
 
 type Envelope struct {
@@ -23,6 +41,8 @@ type Envelope struct {
 	Errors   []MWError         `json:"errors,omitempty"`
 	Warnings map[string]any    `json:"warnings,omitempty"`
 	Continue map[string]string `json:"continue,omitempty"`
+	// CurTimestamp is the server time, sent when the request had curtimestamp=1.
+	CurTimestamp string `json:"curtimestamp,omitempty"`
 }
 
 type Response struct {
@@ -30,9 +50,67 @@ type Response struct {
 	Header     http.Header
 	Envelope
 
+	// DatabaseLag is the replication lag the server reported in X-Database-Lag,
+	// or zero when absent. Bots can back off on it before maxlag errors start.
+	DatabaseLag time.Duration
+
 	Raw json.RawMessage
+
+	action     string // action param of the request, for error reporting
+	requestURL string // redacted request URL, for error reporting
 }
 
 func (r *Response) Into(out any) error {
 	return json.Unmarshal(r.Raw, out)
 }
+
+// Bool reads the boolean at path (object keys, or decimal indexes into arrays),
+// accepting both formatversion=2 true/false and formatversion=1 presence flags
+// (key present with value ""). An absent final key is false; an absent parent
+// is an error.
+func (r *Response) Bool(path ...string) (bool, error) {
+	raw, ok, err := r.lookup(path...)
+	if err != nil || !ok {
+		return false, err
+	}
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return false, err
+	}
+	switch x := v.(type) {
+	case bool:
+		return x, nil
+	case string:
+		return true, nil
+	case nil:
+		return false, nil
+	default:
+		return false, fmt.Errorf("%s: not a boolean: %s", strings.Join(path, "."), raw)
+	}
+}
+
+// lookup walks path through Raw. ok is false when only the last element is missing.
+func (r *Response) lookup(path ...string) (raw json.RawMessage, ok bool, err error) {
+	raw = r.Raw
+	for i, key := range path {
+		var next json.RawMessage
+		found := false
+		var obj map[string]json.RawMessage
+		var arr []json.RawMessage
+		if json.Unmarshal(raw, &obj) == nil && obj != nil {
+			next, found = obj[key]
+		} else if json.Unmarshal(raw, &arr) == nil {
+			if n, err := strconv.Atoi(key); err == nil && n >= 0 && n < len(arr) {
+				next, found = arr[n], true
+			}
+		}
+		if !found {
+			if i == len(path)-1 {
+				return nil, false, nil
+			}
+			return nil, false, fmt.Errorf("%s: not found in response", strings.Join(path[:i+1], "."))
+		}
+		raw = next
+	}
+	return raw, true, nil
+}
@@ -0,0 +1,71 @@
+package mwapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestResponse_Bool(t *testing.T) {
+	t.Parallel()
+
+	fv2 := &Response{Raw: json.RawMessage(`{"query":{"pages":[{"title":"A","missing":true,"redirect":false}]},"batchcomplete":true}`)}
+	fv1 := &Response{Raw: json.RawMessage(`{"query":{"pages":{"-1":{"title":"A","missing":""}}},"batchcomplete":""}`)}
+
+	for name, tc := range map[string]struct {
+		resp *Response
+		path []string
+		want bool
+	}{
+		"fv2 true":      {fv2, []string{"query", "pages", "0", "missing"}, true},
+		"fv2 false":     {fv2, []string{"query", "pages", "0", "redirect"}, false},
+		"fv2 absent":    {fv2, []string{"query", "pages", "0", "invalid"}, false},
+		"fv2 top level": {fv2, []string{"batchcomplete"}, true},
+		"fv1 presence":  {fv1, []string{"query", "pages", "-1", "missing"}, true},
+		"fv1 absent":    {fv1, []string{"query", "pages", "-1", "redirect"}, false},
+		"fv1 top level": {fv1, []string{"batchcomplete"}, true},
+	} {
+		got, err := tc.resp.Bool(tc.path...)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got != tc.want {
+			t.Fatalf("%s: got %v, want %v", name, got, tc.want)
+		}
+	}
+
+	if _, err := fv2.Bool("query", "nope", "missing"); err == nil {
+		t.Fatalf("missing parent should be an error")
+	}
+	if _, err := fv2.Bool("query", "pages"); err == nil {
+		t.Fatalf("non-boolean value should be an error")
+	}
+}
+
+func TestResponse_WarningMessages(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		raw  string
+		want []Warning
+	}{
+		"fv2 list": {
+			`{"warnings":[{"code":"deprecation","text":"list=foo is deprecated.","module":"query+foo"},{"code":"unrecognizedparams","text":"Unrecognized parameter: x.","module":"main"}]}`,
+			[]Warning{{"query+foo", "deprecation", "list=foo is deprecated."}, {"main", "unrecognizedparams", "Unrecognized parameter: x."}},
+		},
+		"bc fv2 string": {
+			`{"warnings":{"main":{"warnings":"Unrecognized parameter: x.\nSubscribe to the mediawiki-api-announce list."}}}`,
+			[]Warning{{"main", "", "Unrecognized parameter: x."}, {"main", "", "Subscribe to the mediawiki-api-announce list."}},
+		},
+		"bc fv1 star": {
+			`{"warnings":{"query":{"*":"Unrecognized value."},"main":{"*":"Unrecognized parameter: x."}}}`,
+			[]Warning{{"main", "", "Unrecognized parameter: x."}, {"query", "", "Unrecognized value."}},
+		},
+		"none": {`{"batchcomplete":true}`, []Warning{}},
+	} {
+		got := (&Response{Raw: json.RawMessage(tc.raw)}).WarningMessages()
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %+v, want %+v", name, got, tc.want)
+		}
+	}
+}
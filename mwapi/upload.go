@@ -0,0 +1,159 @@
+package mwapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const defaultChunkSize = 5 << 20 // 5MiB
+
+type ChunkedUploadOptions struct {
+	// ChunkSize is the number of bytes sent per request; defaults to 5MiB.
+	// It must stay below the wiki's POST size limit.
+	ChunkSize      int64
+	Comment        string
+	Text           string
+	IgnoreWarnings bool
+	// Retries is how often a chunk failing with stashfailed is resent
+	// (default 3).
+	Retries int
+}
+
+type uploadChunkResult struct {
+	Result  string `json:"result"`
+	Offset  int64  `json:"offset"`
+	FileKey string `json:"filekey"`
+}
+
+// UploadChunked uploads size bytes from r to the stash in chunks, then
+// publishes the stashed file as filename. Use it for files over the wiki's
+// POST size limit. It returns the response of the final upload request; a
+// result other than Success is returned together with an error.
+func (c *Client) UploadChunked(ctx context.Context, filename string, r io.Reader, size int64, opt *ChunkedUploadOptions) (*Response, error) {
+	if filename == "" {
+		return nil, errors.New("upload: missing filename")
+	}
+	if r == nil {
+		return nil, errors.New("upload: missing file")
+	}
+	if size <= 0 {
+		return nil, fmt.Errorf("upload: invalid file size %d", size)
+	}
+	o := ChunkedUploadOptions{}
+	if opt != nil {
+		o = *opt
+	}
+	if o.ChunkSize <= 0 {
+		o.ChunkSize = defaultChunkSize
+	}
+	if o.Retries <= 0 {
+		o.Retries = 3
+	}
+
+	buf := make([]byte, min(o.ChunkSize, size))
+	var fileKey string
+	for offset := int64(0); offset < size; {
+		n, err := io.ReadFull(r, buf[:min(o.ChunkSize, size-offset)])
+		if err != nil {
+			return nil, fmt.Errorf("upload: read chunk at offset %d: %w", offset, err)
+		}
+		p := map[string]any{
+			"action":         "upload",
+			"stash":          true,
+			"filename":       filename,
+			"filesize":       size,
+			"offset":         offset,
+			"ignorewarnings": o.IgnoreWarnings,
+			// PostWithToken rebuilds the request from the []byte on a badtoken
+			// retry, so that retry resends the chunk whole.
+			"chunk": buf[:n],
+		}
+		if fileKey != "" {
+			p["filekey"] = fileKey
+		}
+
+		res, resp, err := c.uploadChunk(ctx, p, o.Retries)
+		if err != nil {
+			return resp, fmt.Errorf("upload: chunk at offset %d: %w", offset, err)
+		}
+		if res.FileKey != "" {
+			fileKey = res.FileKey
+		}
+		offset += int64(n)
+
+		switch strings.ToLower(res.Result) {
+		case "continue":
+			if res.Offset != offset {
+				return resp, fmt.Errorf("upload: server expects offset %d, sent up to %d", res.Offset, offset)
+			}
+		case "success":
+			if offset < size {
+				return resp, fmt.Errorf("upload: stash finished at offset %d of %d", offset, size)
+			}
+		default:
+			return resp, fmt.Errorf("upload: chunk at offset %d: %s", offset-int64(n), res.Result)
+		}
+	}
+	if fileKey == "" {
+		return nil, errors.New("upload: missing upload.filekey in response")
+	}
+
+	p := map[string]any{
+		"action":         "upload",
+		"filename":       filename,
+		"filekey":        fileKey,
+		"ignorewarnings": o.IgnoreWarnings,
+	}
+	if o.Comment != "" {
+		p["comment"] = o.Comment
+	}
+	if o.Text != "" {
+		p["text"] = o.Text
+	}
+	resp, err := c.PostWithToken(ctx, TokenCSRF, p, nil)
+	if err != nil {
+		return resp, err
+	}
+	if err := apiError(resp); err != nil {
+		return resp, err
+	}
+	if err := UploadSucceeded(resp); err != nil {
+		return resp, fmt.Errorf("upload failed: %w", err)
+	}
+	return resp, nil
+}
+
+// uploadChunk posts one chunk, resending it up to retries times when the
+// stash fails.
+func (c *Client) uploadChunk(ctx context.Context, p map[string]any, retries int) (*uploadChunkResult, *Response, error) {
+	var lastErr error
+	var lastResp *Response
+	for attempt := 0; attempt <= retries; attempt++ {
+		resp, err := c.PostWithToken(ctx, TokenCSRF, p, nil)
+		if err == nil {
+			err = apiError(resp)
+		}
+		if err != nil {
+			if e, ok := IsMediaWikiApiError(err); ok && strings.EqualFold(e.Code, "stashfailed") {
+				lastErr, lastResp = err, resp
+				continue
+			}
+			return nil, resp, err
+		}
+
+		var out struct {
+			Upload *uploadChunkResult `json:"upload"`
+		}
+		if err := resp.Into(&out); err != nil {
+			return nil, resp, err
+		}
+		if out.Upload == nil || out.Upload.Result == "" {
+			return nil, resp, errors.New("missing upload.result in response")
+		}
+		return out.Upload, resp, nil
+	}
+	return nil, lastResp, fmt.Errorf("stash retry exhausted: %w", lastErr)
+}
@@ -0,0 +1,113 @@
+package mwapi
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+func TestUploadChunked(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(strings.Repeat("0123456789", 3)) // 30 bytes, 4 chunks of 8
+	var (
+		mu        sync.Mutex
+		stashed   []byte
+		failedOne bool
+	)
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("upload", func(r *mwtest.Request) any {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Param("stash") != "1" {
+			if r.Param("filekey") != "KEY" || r.Param("filename") != "Big.bin" || r.Param("comment") != "big file" {
+				return mwtest.ErrorResponse("badupload", "unexpected final request")
+			}
+			return map[string]any{"upload": map[string]any{"result": "Success", "filename": "Big.bin"}}
+		}
+		if r.Param("filesize") != "30" {
+			return mwtest.ErrorResponse("badupload", "filesize = "+r.Param("filesize"))
+		}
+		offset, _ := strconv.Atoi(r.Param("offset"))
+		if offset != len(stashed) || (offset > 0) != (r.Param("filekey") == "KEY") {
+			return mwtest.ErrorResponse("badupload", "unexpected offset or filekey")
+		}
+		if offset == 8 && !failedOne {
+			failedOne = true
+			return mwtest.ErrorResponse("stashfailed", "Could not store upload in the stash.")
+		}
+		stashed = append(stashed, r.Files["chunk"].Data...)
+		result := "Continue"
+		if len(stashed) == len(data) {
+			result = "Success"
+		}
+		return map[string]any{"upload": map[string]any{"result": result, "offset": len(stashed), "filekey": "KEY"}}
+	})
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	resp, err := c.UploadChunked(ctx, "Big.bin", bytes.NewReader(data), int64(len(data)), &ChunkedUploadOptions{
+		ChunkSize: 8,
+		Comment:   "big file",
+	})
+	if err != nil {
+		t.Fatalf("UploadChunked: %v", err)
+	}
+	if err := UploadSucceeded(resp); err != nil {
+		t.Fatalf("final response: %v", err)
+	}
+	if !bytes.Equal(stashed, data) {
+		t.Fatalf("stashed = %q, want %q", stashed, data)
+	}
+	// 4 chunks, one resent after stashfailed, and the final publish.
+	if n := len(wiki.RequestsFor("upload")); n != 6 {
+		t.Fatalf("upload requests = %d, want 6", n)
+	}
+}
+
+func TestUploadChunked_ShortReader(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	if _, err := c.UploadChunked(ctx, "Big.bin", strings.NewReader("short"), 100, nil); err == nil {
+		t.Fatal("UploadChunked succeeded with a reader shorter than size")
+	}
+	if n := len(wiki.RequestsFor("upload")); n != 0 {
+		t.Fatalf("upload requests = %d, want 0", n)
+	}
+}
+
+func TestUploadChunked_StashRetryExhausted(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("upload", func(r *mwtest.Request) any {
+		return mwtest.ErrorResponse("stashfailed", "Could not store upload in the stash.")
+	})
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	resp, err := c.UploadChunked(ctx, "Big.bin", strings.NewReader("data"), 4, &ChunkedUploadOptions{Retries: 2})
+	if err == nil {
+		t.Fatal("UploadChunked succeeded although every chunk failed")
+	}
+	if code := responseErrorCode(resp); code != "stashfailed" {
+		t.Fatalf("last response code = %q, want stashfailed", code)
+	}
+	// The first attempt and two resends.
+	if n := len(wiki.RequestsFor("upload")); n != 3 {
+		t.Fatalf("upload requests = %d, want 3", n)
+	}
+}
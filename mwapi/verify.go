@@ -0,0 +1,77 @@
+package mwapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+var ErrNotActionAPI = errors.New("endpoint is not a MediaWiki Action API")
+
+type endpointCheck struct {
+	mu   sync.Mutex
+	done bool
+	err  error
+}
+
+// WithVerifyEndpoint makes the client probe the endpoint with a siteinfo query
+// before its first real request, failing with ErrNotActionAPI if the answer is
+// not an Action API envelope (an article URL, a 404 page, ...). The outcome is
+// cached; only transport errors are retried on the next request.
+func WithVerifyEndpoint(v bool) Option {
+	return func(c *Client) {
+		c.verifyEndpoint = v
+	}
+}
+
+func (c *Client) checkEndpoint(ctx context.Context) error {
+	if !c.verifyEndpoint {
+		return nil
+	}
+	c.endpointCheck.mu.Lock()
+	defer c.endpointCheck.mu.Unlock()
+	if c.endpointCheck.done {
+		return c.endpointCheck.err
+	}
+
+	np, err := normalizeParams(map[string]any{"action": "query", "meta": "siteinfo"})
+	if err != nil {
+		return err
+	}
+	resp, err := c.doOnce(ctx, http.MethodGet, np, false)
+
+	var httpErr *HTTPError
+	switch {
+	case errors.As(err, &httpErr):
+		err = fmt.Errorf("%w: %s: %v", ErrNotActionAPI, c.endpoint, httpErr)
+	case err != nil:
+		// Network trouble says nothing about the endpoint; try again next time.
+		return err
+	case !isActionAPIEnvelope(resp):
+		err = fmt.Errorf("%w: %s: http %d response without query or error", ErrNotActionAPI, c.endpoint, resp.StatusCode)
+	}
+
+	c.endpointCheck.done = true
+	c.endpointCheck.err = err
+	return err
+}
+
+// isActionAPIEnvelope accepts a siteinfo result or any API error (e.g.
+// readapidenied on private wikis), both of which prove an api.php answered.
+func isActionAPIEnvelope(resp *Response) bool {
+	if resp.Error != nil || len(resp.Errors) > 0 {
+		return true
+	}
+	var v struct {
+		Query *struct {
+			General json.RawMessage `json:"general"`
+		} `json:"query"`
+	}
+	if err := resp.Into(&v); err != nil {
+		return false
+	}
+	return v.Query != nil && len(v.Query.General) > 0
+}
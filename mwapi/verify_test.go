@@ -0,0 +1,68 @@
+package mwapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+func TestVerifyEndpoint_RejectsNonAPI(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("<html><body><h1>Not Found</h1></body></html>"))
+	}))
+	t.Cleanup(srv.Close)
+
+	c := New(srv.URL+"/wiki/api.php", WithVerifyEndpoint(true))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	for i := 0; i < 2; i++ {
+		_, err := c.Post(ctx, map[string]any{"action": "purge", "titles": "Main Page"})
+		if !errors.Is(err, ErrNotActionAPI) {
+			t.Fatalf("err = %v, want ErrNotActionAPI", err)
+		}
+	}
+	if got := hits.Load(); got != 1 {
+		t.Fatalf("server hits = %d, want 1 cached probe", got)
+	}
+}
+
+func TestVerifyEndpoint_AcceptsAPI(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("query", func(r *mwtest.Request) any {
+		return map[string]any{"query": map[string]any{"general": map[string]any{"sitename": "Test"}}}
+	})
+
+	c := New(wiki.URL(), WithVerifyEndpoint(true))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.Get(ctx, map[string]any{"action": "query", "list": "allpages"}); err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+	}
+	var probes int
+	for _, r := range wiki.RequestsFor("query") {
+		if r.Param("meta") == "siteinfo" {
+			probes++
+		}
+	}
+	if probes != 1 {
+		t.Fatalf("siteinfo probes = %d, want 1", probes)
+	}
+}
@@ -0,0 +1,138 @@
+package mwapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ErrWriteWarning matches a *WriteWarningError.
+var ErrWriteWarning = errors.New("write succeeded with warnings")
+
+// strictWarningPrefixes are the warning codes WithStrictWrites treats as
+// failures: the write went through, but a filter flagged it.
+var strictWarningPrefixes = []string{"abusefilter", "spamblacklist", "titleblacklist"}
+
+// WithStrictWrites makes high-level write helpers such as Edit return a
+// *WriteWarningError when the write succeeds with an AbuseFilter,
+// SpamBlacklist or TitleBlacklist warning, instead of succeeding silently.
+func WithStrictWrites(v bool) Option {
+	return func(c *Client) {
+		c.strictWrites = v
+	}
+}
+
+// WriteWarningError reports a write that succeeded with filter warnings
+// under WithStrictWrites. The result of the write is returned alongside it.
+type WriteWarningError struct {
+	Module   string
+	Warnings []MWError
+}
+
+func (e *WriteWarningError) Error() string {
+	parts := make([]string, 0, len(e.Warnings))
+	for _, w := range e.Warnings {
+		parts = append(parts, firstNonEmpty(w.Code, w.Text, w.Info))
+	}
+	return fmt.Sprintf("%s: %s: %s", e.Module, ErrWriteWarning, strings.Join(parts, ", "))
+}
+
+func (e *WriteWarningError) Is(target error) bool { return target == ErrWriteWarning }
+
+// strictWarnings returns the warnings that fail a write under WithStrictWrites.
+func (c *Client) strictWarnings(module string, warnings []MWError) error {
+	if !c.strictWrites {
+		return nil
+	}
+	var bad []MWError
+	for _, w := range warnings {
+		code := strings.ToLower(w.Code)
+		for _, p := range strictWarningPrefixes {
+			if strings.HasPrefix(code, p) {
+				bad = append(bad, w)
+				break
+			}
+		}
+	}
+	if len(bad) == 0 {
+		return nil
+	}
+	return &WriteWarningError{Module: module, Warnings: bad}
+}
+
+// Warning is one API warning, normalized from any of the formats MediaWiki
+// uses for them.
+type Warning struct {
+	Module string
+	Code   string // empty for errorformat=bc warnings, which carry no code
+	Text   string
+}
+
+// WarningMessages returns the warnings of the response, e.g. to log
+// deprecations. Warnings that cannot be decoded are skipped.
+func (r *Response) WarningMessages() []Warning {
+	ws, _ := decodeWarnings(r.Raw)
+	out := make([]Warning, 0, len(ws))
+	for _, w := range ws {
+		out = append(out, Warning{Module: w.Module, Code: w.Code, Text: firstNonEmpty(w.Text, w.Info, w.Key)})
+	}
+	return out
+}
+
+// decodeWarnings reads the top-level warnings of a response in any of its
+// shapes: the list of errorformat=plaintext (and wikitext, html, raw), or the
+// errorformat=bc object keyed by module, holding {"warnings": text} in
+// formatversion=2 and {"*": text} in formatversion=1. A bc text holds one
+// warning per line; bc warnings carry no code.
+func decodeWarnings(raw json.RawMessage) ([]MWError, error) {
+	var r struct {
+		Warnings json.RawMessage `json:"warnings"`
+	}
+	if err := json.Unmarshal(raw, &r); err != nil {
+		return nil, err
+	}
+	w := r.Warnings
+	if len(w) == 0 || string(w) == "null" {
+		return nil, nil
+	}
+
+	var list []MWError
+	if err := json.Unmarshal(w, &list); err == nil {
+		return list, nil
+	}
+	var byModule map[string]struct {
+		Warnings string `json:"warnings"`
+		Star     string `json:"*"`
+	}
+	if err := json.Unmarshal(w, &byModule); err != nil {
+		return nil, fmt.Errorf("decode warnings: %w", err)
+	}
+	var out []MWError
+	for _, module := range slices.Sorted(maps.Keys(byModule)) {
+		v := byModule[module]
+		for _, line := range strings.Split(firstNonEmpty(v.Warnings, v.Star), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				out = append(out, MWError{Module: module, Text: line})
+			}
+		}
+	}
+	return out, nil
+}
+
+// moduleWarnings returns the warnings of one module (edit, move, ...).
+func moduleWarnings(raw json.RawMessage, module string) ([]MWError, error) {
+	all, err := decodeWarnings(raw)
+	if err != nil {
+		return nil, err
+	}
+	var out []MWError
+	for _, w := range all {
+		if strings.EqualFold(w.Module, module) {
+			out = append(out, w)
+		}
+	}
+	return out, nil
+}
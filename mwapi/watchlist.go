@@ -0,0 +1,210 @@
+package mwapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"time"
+)
+
+type WatchlistEntry struct {
+	Type      string `json:"type"`
+	NS        int    `json:"ns"`
+	Title     string `json:"title"`
+	PageID    int64  `json:"pageid"`
+	RevID     int64  `json:"revid"`
+	OldRevID  int64  `json:"old_revid"`
+	User      string `json:"user,omitempty"`
+	Timestamp string `json:"timestamp,omitempty"`
+	Comment   string `json:"comment,omitempty"`
+	Bot       bool   `json:"-"`
+	Minor     bool   `json:"-"`
+	New       bool   `json:"-"`
+}
+
+func (e *WatchlistEntry) UnmarshalJSON(b []byte) error {
+	type plain WatchlistEntry
+	var v struct {
+		plain
+		Bot   json.RawMessage `json:"bot"`
+		Minor json.RawMessage `json:"minor"`
+		New   json.RawMessage `json:"new"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*e = WatchlistEntry(v.plain)
+	e.Bot = rawFlag(v.Bot)
+	e.Minor = rawFlag(v.Minor)
+	e.New = rawFlag(v.New)
+	return nil
+}
+
+type WatchlistOptions struct {
+	Namespaces []int
+	// Start and End bound the listing; with the default direction Start is the newer end.
+	Start time.Time
+	End   time.Time
+	// Show filters by flags, e.g. "!bot", "minor", "unread".
+	Show []string
+	// Types limits change types: edit, new, log, external, categorize.
+	Types []string
+	// AllRevisions lists every change instead of only the latest per page.
+	AllRevisions bool
+}
+
+// Watchlist iterates recent changes to pages on the logged-in user's watchlist
+// (list=watchlist). It fails with ErrNotLoggedIn without a session.
+func (c *Client) Watchlist(ctx context.Context, opts WatchlistOptions, qopts ...QueryOption) iter.Seq2[WatchlistEntry, error] {
+	if err := c.requireLogin(); err != nil {
+		return failSeq[WatchlistEntry](err)
+	}
+	p := map[string]any{
+		"action":      "query",
+		"list":        "watchlist",
+		"wlprop":      []string{"ids", "title", "flags", "user", "comment", "timestamp"},
+		"wlnamespace": opts.Namespaces,
+		"wlstart":     opts.Start,
+		"wlend":       opts.End,
+		"wlshow":      opts.Show,
+		"wltype":      opts.Types,
+		"wlallrev":    opts.AllRevisions,
+		"wllimit":     "max",
+		"assert":      "user",
+	}
+	return queryItems(ctx, c, p, listItems[WatchlistEntry]("watchlist"), iterOptions(qopts, "wllimit"))
+}
+
+// WatchlistRaw iterates the titles on the logged-in user's watchlist
+// (list=watchlistraw). It fails with ErrNotLoggedIn without a session.
+func (c *Client) WatchlistRaw(ctx context.Context, opts ...QueryOption) iter.Seq2[string, error] {
+	if err := c.requireLogin(); err != nil {
+		return failSeq[string](err)
+	}
+	p := map[string]any{
+		"action":  "query",
+		"list":    "watchlistraw",
+		"wrlimit": "max",
+		"assert":  "user",
+	}
+	refs := queryItems(ctx, c, p, watchlistRawItems, iterOptions(opts, "wrlimit"))
+	return func(yield func(string, error) bool) {
+		for ref, err := range refs {
+			if !yield(ref.Title, err) {
+				return
+			}
+		}
+	}
+}
+
+// watchlistRawItems reads list=watchlistraw, which MediaWiki puts at the top
+// level of the response rather than under query.
+func watchlistRawItems(resp *Response) ([]PageRef, error) {
+	var out struct {
+		WatchlistRaw []PageRef `json:"watchlistraw"`
+	}
+	if err := resp.Into(&out); err != nil {
+		return nil, err
+	}
+	if out.WatchlistRaw != nil {
+		return out.WatchlistRaw, nil
+	}
+	return listItems[PageRef]("watchlistraw")(resp)
+}
+
+func (c *Client) requireLogin() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.loggedInUser == "" && c.oauthToken == "" {
+		return ErrNotLoggedIn
+	}
+	return nil
+}
+
+// failSeq yields err once.
+func failSeq[T any](err error) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		yield(zero, err)
+	}
+}
+
+type WatchResult struct {
+	Title     string
+	Watched   bool
+	Unwatched bool
+	// Missing is set for pages that do not exist; they are watched anyway.
+	Missing bool
+}
+
+func (r *WatchResult) UnmarshalJSON(b []byte) error {
+	var v struct {
+		Title     string          `json:"title"`
+		Watched   json.RawMessage `json:"watched"`
+		Unwatched json.RawMessage `json:"unwatched"`
+		Missing   json.RawMessage `json:"missing"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*r = WatchResult{
+		Title:     v.Title,
+		Watched:   rawFlag(v.Watched),
+		Unwatched: rawFlag(v.Unwatched),
+		Missing:   rawFlag(v.Missing),
+	}
+	return nil
+}
+
+// Watch adds titles to the logged-in user's watchlist, or removes them with
+// unwatch (action=watch), in batches of 50. It fails with ErrNotLoggedIn
+// without a session.
+func (c *Client) Watch(ctx context.Context, titles []string, unwatch bool) ([]WatchResult, error) {
+	if len(titles) == 0 {
+		return nil, errors.New("watch: missing titles")
+	}
+	if err := c.requireLogin(); err != nil {
+		return nil, err
+	}
+	out := make([]WatchResult, 0, len(titles))
+	for start := 0; start < len(titles); start += maxTitlesPerQuery {
+		end := min(start+maxTitlesPerQuery, len(titles))
+		resp, err := c.PostWithToken(ctx, TokenWatch, map[string]any{
+			"action":  "watch",
+			"titles":  titles[start:end],
+			"unwatch": unwatch,
+		}, nil)
+		if err != nil {
+			return out, fmt.Errorf("watch: %w", err)
+		}
+		if err := apiError(resp); err != nil {
+			return out, fmt.Errorf("watch: %w", err)
+		}
+		var v struct {
+			Watch json.RawMessage `json:"watch"`
+		}
+		if err := resp.Into(&v); err != nil {
+			return out, err
+		}
+		switch {
+		case len(v.Watch) == 0:
+			return out, errors.New("missing watch in response")
+		case v.Watch[0] == '{':
+			// Releases before titles= was supported answer with a single object.
+			var r WatchResult
+			if err := json.Unmarshal(v.Watch, &r); err != nil {
+				return out, err
+			}
+			out = append(out, r)
+		default:
+			var rs []WatchResult
+			if err := json.Unmarshal(v.Watch, &rs); err != nil {
+				return out, err
+			}
+			out = append(out, rs...)
+		}
+	}
+	return out, nil
+}
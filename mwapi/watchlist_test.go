@@ -0,0 +1,124 @@
+package mwapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+func TestWatchlistRaw_Continuation(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("query", func(r *mwtest.Request) any {
+		if r.User == "" {
+			return mwtest.AssertUserFailed()
+		}
+		switch r.Param("wrcontinue") {
+		case "":
+			return map[string]any{
+				"continue": map[string]any{"wrcontinue": "0|B", "continue": "-||"},
+				"watchlistraw": []any{
+					map[string]any{"ns": 0, "title": "A"},
+				},
+			}
+		case "0|B":
+			return map[string]any{
+				"batchcomplete": true,
+				"watchlistraw": []any{
+					map[string]any{"ns": 0, "title": "B"},
+					map[string]any{"ns": 1, "title": "Talk:B"},
+				},
+			}
+		}
+		return mwtest.ErrorResponse("badcontinue", "Invalid continue param.")
+	})
+
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	for _, err := range c.WatchlistRaw(ctx) {
+		if !errors.Is(err, ErrNotLoggedIn) {
+			t.Fatalf("WatchlistRaw before login: err = %v, want ErrNotLoggedIn", err)
+		}
+	}
+
+	if _, err := c.Login(ctx, "UserA", "pass"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	var titles []string
+	for title, err := range c.WatchlistRaw(ctx) {
+		if err != nil {
+			t.Fatalf("WatchlistRaw: %v", err)
+		}
+		titles = append(titles, title)
+	}
+	if len(titles) != 3 || titles[0] != "A" || titles[2] != "Talk:B" {
+		t.Fatalf("titles = %v", titles)
+	}
+	for _, r := range wiki.RequestsFor("query") {
+		if r.Param("list") == "watchlistraw" && r.Param("assertuser") != "UserA" {
+			t.Fatalf("assertuser = %q, want UserA", r.Param("assertuser"))
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("watch", func(r *mwtest.Request) any {
+		var out []any
+		for _, title := range strings.Split(r.Param("titles"), "|") {
+			res := map[string]any{"title": title}
+			if r.Param("unwatch") != "" {
+				res["unwatched"] = true
+			} else {
+				res["watched"] = true
+			}
+			if title == "Nope" {
+				res["missing"] = true
+			}
+			out = append(out, res)
+		}
+		return map[string]any{"batchcomplete": true, "watch": out}
+	})
+
+	c := New(wiki.URL())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	if _, err := c.Watch(ctx, []string{"A"}, false); !errors.Is(err, ErrNotLoggedIn) {
+		t.Fatalf("err = %v, want ErrNotLoggedIn", err)
+	}
+	if _, err := c.Login(ctx, "UserA", "pass"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+
+	titles := []string{"Nope"}
+	for i := 0; i < 59; i++ {
+		titles = append(titles, fmt.Sprintf("Page %d", i))
+	}
+	res, err := c.Watch(ctx, titles, false)
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	if len(res) != 60 || res[0] != (WatchResult{Title: "Nope", Watched: true, Missing: true}) || !res[59].Watched {
+		t.Fatalf("results = %+v", res)
+	}
+	if n := len(wiki.RequestsFor("watch")); n != 2 {
+		t.Fatalf("watch requests = %d, want 2 (batches of 50)", n)
+	}
+
+	res, err = c.Watch(ctx, []string{"A"}, true)
+	if err != nil || len(res) != 1 || res[0] != (WatchResult{Title: "A", Unwatched: true}) {
+		t.Fatalf("unwatch = %+v, %v", res, err)
+	}
+	wiki.AssertSent("watch", "unwatch", "1")
+}
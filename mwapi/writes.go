@@ -0,0 +1,160 @@
+package mwapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultWriteActions are the core modules that change wiki state.
+// Extensions can add their own via WithWriteActions.
+var defaultWriteActions = []string{
+	"block",
+	"changecontentmodel",
+	"changetags",
+	"delete",
+	"edit",
+	"emailuser",
+	"filerevert",
+	"import",
+	"managetags",
+	"mergehistory",
+	"move",
+	"options",
+	"patrol",
+	"protect",
+	"review",
+	"revisiondelete",
+	"rollback",
+	"setnotificationtimestamp",
+	"tag",
+	"unblock",
+	"undelete",
+	"upload",
+	"userrights",
+	"watch",
+}
+
+func newWriteActionSet() map[string]struct{} {
+	m := make(map[string]struct{}, len(defaultWriteActions))
+	for _, a := range defaultWriteActions {
+		m[a] = struct{}{}
+	}
+	return m
+}
+
+// WithWriteActions marks additional actions as side-effecting, on top of the
+// built-in set. The set drives the write guards (e.g. WithRequireLoginForWrites).
+func WithWriteActions(actions ...string) Option {
+	return func(c *Client) {
+		for _, a := range actions {
+			if a = strings.ToLower(strings.TrimSpace(a)); a != "" {
+				c.writeActions[a] = struct{}{}
+			}
+		}
+	}
+}
+
+// WithRequireLoginForWrites makes write actions fail with ErrNotLoggedIn before
+// anything is sent when the client has no logged-in session, instead of
+// silently editing as an anonymous IP.
+func WithRequireLoginForWrites(v bool) Option {
+	return func(c *Client) {
+		c.requireLoginForWrites = v
+	}
+}
+
+// WithReadOnly makes every write action (see WithWriteActions) fail with
+// ErrReadOnlyClient before anything is sent, for scrapers and analytics jobs
+// that must never modify the wiki. Reads are unaffected.
+func WithReadOnly(v bool) Option {
+	return func(c *Client) {
+		c.readOnly = v
+	}
+}
+
+// WithForcePostActions makes Get send these actions as POST, for extension
+// modules that reject GET with mustbeposted even though they only read.
+// The list is independent of (and adds to) the write-action set.
+func WithForcePostActions(actions ...string) Option {
+	return func(c *Client) {
+		if c.forcePostActions == nil {
+			c.forcePostActions = map[string]struct{}{}
+		}
+		for _, a := range actions {
+			if a = strings.ToLower(strings.TrimSpace(a)); a != "" {
+				c.forcePostActions[a] = struct{}{}
+			}
+		}
+	}
+}
+
+// WithMinWriteInterval spaces write actions at least d apart, waiting as
+// needed, for wikis that throttle how fast non-bot accounts may edit. Writes
+// are serialized while waiting; reads are never delayed. It predates
+// Scheduler and works without one; with a PoliteScheduler prefer
+// SchedulerOptions.WriteInterval, which also paces retries.
+func WithMinWriteInterval(d time.Duration) Option {
+	return func(c *Client) {
+		if d >= 0 {
+			c.writePacer.interval = d
+		}
+	}
+}
+
+type writePacer struct {
+	mu       sync.Mutex
+	interval time.Duration
+	last     time.Time
+}
+
+// wait blocks until the next write may be sent and records it as sent.
+func (p *writePacer) wait(ctx context.Context) error {
+	if p.interval <= 0 {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if d := p.interval - time.Since(p.last); d > 0 {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return canceled(ctx, nil)
+		}
+	}
+	p.last = time.Now()
+	return nil
+}
+
+func (c *Client) mustPost(action string) bool {
+	_, ok := c.forcePostActions[action]
+	return ok
+}
+
+func (c *Client) isWriteAction(action string) bool {
+	_, ok := c.writeActions[action]
+	return ok
+}
+
+func (c *Client) checkWrite(method, action string) error {
+	if method != http.MethodPost || !c.isWriteAction(action) {
+		return nil
+	}
+	if c.readOnly {
+		return fmt.Errorf("%w: action=%s", ErrReadOnlyClient, action)
+	}
+	if c.requireLoginForWrites {
+		c.mu.Lock()
+		loggedIn := c.loggedInUser != "" || c.oauthToken != ""
+		c.mu.Unlock()
+		if !loggedIn {
+			return ErrNotLoggedIn
+		}
+	}
+	return nil
+}
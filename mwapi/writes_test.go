@@ -0,0 +1,211 @@
+package mwapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/moegirlwiki/wiki-saikou-go/mwapi/mwtest"
+)
+
+func TestRequireLoginForWrites_BlocksAnonymousEdit(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("edit", func(r *mwtest.Request) any {
+		return map[string]any{"edit": map[string]any{"result": "Success"}}
+	})
+	wiki.Handle("wbeditentity", func(r *mwtest.Request) any {
+		return map[string]any{"success": 1}
+	})
+
+	c := New(wiki.URL(), WithRequireLoginForWrites(true), WithWriteActions("wbeditentity"))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	_, err := c.PostWithToken(ctx, TokenCSRF, map[string]any{"action": "edit", "title": "Sandbox", "text": "x"}, nil)
+	if !errors.Is(err, ErrNotLoggedIn) {
+		t.Fatalf("err = %v, want ErrNotLoggedIn", err)
+	}
+	_, err = c.Post(ctx, map[string]any{"action": "wbeditentity", "id": "Q1"})
+	if !errors.Is(err, ErrNotLoggedIn) {
+		t.Fatalf("err = %v, want ErrNotLoggedIn for custom write action", err)
+	}
+	if n := len(wiki.Requests()); n != 0 {
+		t.Fatalf("%d requests were sent, want 0", n)
+	}
+
+	// Reads are unaffected.
+	if _, err := c.Get(ctx, map[string]any{"meta": "userinfo"}); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	if _, err := c.Login(ctx, "UserA", "pass"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if _, err := c.PostWithToken(ctx, TokenCSRF, map[string]any{"action": "edit", "title": "Sandbox", "text": "x"}, nil); err != nil {
+		t.Fatalf("edit after login: %v", err)
+	}
+}
+
+func TestReadOnly_RejectsWritesLocally(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("edit", func(r *mwtest.Request) any {
+		return map[string]any{"edit": map[string]any{"result": "Success"}}
+	})
+	wiki.Handle("query", func(r *mwtest.Request) any {
+		return map[string]any{"query": map[string]any{"allpages": []any{}}}
+	})
+
+	c := New(wiki.URL(), WithReadOnly(true))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	_, err := c.PostWithToken(ctx, TokenCSRF, map[string]any{"action": "edit", "title": "Sandbox", "text": "x"}, nil)
+	if !errors.Is(err, ErrReadOnlyClient) {
+		t.Fatalf("err = %v, want ErrReadOnlyClient", err)
+	}
+	if n := len(wiki.Requests()); n != 0 {
+		t.Fatalf("%d requests were sent, want 0", n)
+	}
+
+	if _, err := c.Post(ctx, map[string]any{"action": "query", "list": "allpages"}); err != nil {
+		t.Fatalf("query: %v", err)
+	}
+}
+
+func TestForcePostActions_UpgradesGet(t *testing.T) {
+	t.Parallel()
+
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("cirrus-config-dump", func(r *mwtest.Request) any {
+		if r.Method != http.MethodPost {
+			return mwtest.ErrorResponse("mustbeposted", "The \"cirrus-config-dump\" module requires a POST request.")
+		}
+		return map[string]any{"CirrusSearchServers": []any{}}
+	})
+	wiki.Handle("parse", func(r *mwtest.Request) any {
+		return map[string]any{"parse": map[string]any{}}
+	})
+
+	c := New(wiki.URL(), WithForcePostActions("Cirrus-Config-Dump"))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	if _, err := c.Get(ctx, map[string]any{"action": "cirrus-config-dump"}, ThrowOnApiError(true)); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if m := wiki.LastRequest("cirrus-config-dump").Method; m != http.MethodPost {
+		t.Fatalf("method = %s, want POST", m)
+	}
+
+	if _, err := c.Get(ctx, map[string]any{"action": "parse", "page": "Main Page"}); err != nil {
+		t.Fatalf("Get(parse): %v", err)
+	}
+	if m := wiki.LastRequest("parse").Method; m != http.MethodGet {
+		t.Fatalf("parse method = %s, want GET", m)
+	}
+}
+
+func TestBotEdits(t *testing.T) {
+	t.Parallel()
+
+	newWiki := func(rights []string) *mwtest.FakeWiki {
+		wiki := mwtest.NewFakeWiki(t)
+		wiki.Handle("query", func(r *mwtest.Request) any {
+			return map[string]any{"query": map[string]any{"userinfo": map[string]any{
+				"id": 1, "name": r.User, "rights": rights,
+			}}}
+		})
+		wiki.Handle("edit", func(r *mwtest.Request) any {
+			return map[string]any{"edit": map[string]any{"result": "Success"}}
+		})
+		return wiki
+	}
+	edit := func(t *testing.T, wiki *mwtest.FakeWiki, opts ...Option) string {
+		t.Helper()
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		s := NewSession(New(wiki.URL(), opts...))
+		if _, err := s.Login(ctx, "BotUser", "pass"); err != nil {
+			t.Fatalf("Login: %v", err)
+		}
+		for i := 0; i < 2; i++ {
+			if _, err := s.Edit(ctx, EditParams{Title: "Sandbox", Text: "x"}); err != nil {
+				t.Fatalf("Edit: %v", err)
+			}
+		}
+		edits := wiki.RequestsFor("edit")
+		return edits[len(edits)-1].Param("bot")
+	}
+
+	wiki := newWiki([]string{"edit", "bot"})
+	if got := edit(t, wiki, WithBotEdits(true)); got != "1" {
+		t.Fatalf("bot = %q with WithBotEdits, want 1", got)
+	}
+	probes := 0
+	for _, r := range wiki.RequestsFor("query") {
+		if r.Param("meta") == "userinfo" {
+			probes++
+		}
+	}
+	if probes != 1 {
+		t.Fatalf("userinfo probes = %d, want 1 (cached per login)", probes)
+	}
+	if got := edit(t, newWiki([]string{"edit", "bot"})); got != "" {
+		t.Fatalf("bot = %q without WithBotEdits, want unset", got)
+	}
+	if got := edit(t, newWiki([]string{"edit"}), WithBotEdits(true)); got != "" {
+		t.Fatalf("bot = %q without the bot right, want unset", got)
+	}
+}
+
+func TestMinWriteInterval_SpacesWrites(t *testing.T) {
+	t.Parallel()
+
+	const interval = 150 * time.Millisecond
+	var mu sync.Mutex
+	var editTimes []time.Time
+	wiki := mwtest.NewFakeWiki(t)
+	wiki.Handle("edit", func(r *mwtest.Request) any {
+		mu.Lock()
+		editTimes = append(editTimes, time.Now())
+		mu.Unlock()
+		return map[string]any{"edit": map[string]any{"result": "Success"}}
+	})
+	wiki.Handle("parse", func(r *mwtest.Request) any {
+		return map[string]any{"parse": map[string]any{}}
+	})
+	c := New(wiki.URL(), WithMinWriteInterval(interval))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	t.Cleanup(cancel)
+
+	edit := map[string]any{"action": "edit", "title": "X", "text": "y"}
+	if _, err := c.PostWithToken(ctx, TokenCSRF, edit, nil); err != nil {
+		t.Fatalf("first edit: %v", err)
+	}
+	start := time.Now()
+	if _, err := c.Get(ctx, map[string]any{"action": "parse", "text": "y"}); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if d := time.Since(start); d >= interval {
+		t.Fatalf("read took %v, want no write pacing", d)
+	}
+	if _, err := c.PostWithToken(ctx, TokenCSRF, edit, nil); err != nil {
+		t.Fatalf("second edit: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(editTimes) != 2 {
+		t.Fatalf("edits = %d, want 2", len(editTimes))
+	}
+	if gap := editTimes[1].Sub(editTimes[0]); gap < interval {
+		t.Fatalf("edits %v apart, want at least %v", gap, interval)
+	}
+}